@@ -0,0 +1,175 @@
+//go:build windows || linux
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/acl"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/icacls"
+)
+
+// files changes the permissions of the paths on the host. Tests can replace its Backend with an acltest.Backend.
+var files acl.Files
+
+func runChmod(flags *flag.FlagSet, args []string, _ io.Writer) error {
+	recursive := flags.Bool("R", false, "change the files and directories below the paths as well")
+	if err := parse(flags, args, 2); err != nil {
+		return err
+	}
+	mode, err := parseMode(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	return walk(flags.Args()[1:], *recursive, func(path string) error {
+		return files.Chmod(path, mode)
+	})
+}
+
+// parseMode parses octal permission bits (e.g. 0755)
+func parseMode(s string) (fs.FileMode, error) {
+	v, err := strconv.ParseUint(strings.TrimPrefix(s, "0o"), 8, 32)
+	if err != nil || v&^uint64(fs.ModePerm) != 0 {
+		return 0, fmt.Errorf("invalid mode %q: expected octal permission bits such as 0755", s)
+	}
+	return fs.FileMode(v), nil
+}
+
+func runChown(flags *flag.FlagSet, args []string, _ io.Writer) error {
+	recursive := flags.Bool("R", false, "change the files and directories below the paths as well")
+	if err := parse(flags, args, 2); err != nil {
+		return err
+	}
+	owner, group, _ := strings.Cut(flags.Arg(0), ":")
+	if owner == "" && group == "" {
+		return fmt.Errorf("%w: owner and group cannot both be empty", errUsage)
+	}
+	var ownerSID, groupSID descriptor.SID
+	var err error
+	if owner != "" {
+		if ownerSID, err = acl.LookupUser(owner); err != nil {
+			return err
+		}
+	}
+	if group != "" {
+		if groupSID, err = acl.LookupGroup(group); err != nil {
+			return err
+		}
+	}
+	// unlike acl.Chown, the DACL is left unchanged
+	return walk(flags.Args()[1:], *recursive, func(path string) error {
+		return files.Apply(path, ownerSID, groupSID)
+	})
+}
+
+// output is the JSON output of get for a path
+type output struct {
+	Path string `json:"path"`
+	*descriptor.SecurityDescriptor
+	SDDL string `json:"sddl"`
+}
+
+func runGet(flags *flag.FlagSet, args []string, stdout io.Writer) error {
+	recursive := flags.Bool("R", false, "print the files and directories below the paths as well")
+	format := flags.String("o", "text", "output format: text, sddl, icacls or json")
+	if err := parse(flags, args, 1); err != nil {
+		return err
+	}
+	if *format != "text" && *format != "sddl" && *format != "icacls" && *format != "json" {
+		return fmt.Errorf("%w: unknown output format %q", errUsage, *format)
+	}
+	// a single security descriptor is printed on its own in SDDL, so that it can be passed to set
+	single := !*recursive && flags.NArg() == 1
+	encoder := json.NewEncoder(stdout)
+	return walk(flags.Args(), *recursive, func(path string) error {
+		sd, err := files.Get(path, descriptor.DefaultInformation)
+		if err != nil {
+			return err
+		}
+		switch *format {
+		case "sddl":
+			if single {
+				_, err = fmt.Fprintln(stdout, sd.SDDL())
+			} else {
+				_, err = fmt.Fprintf(stdout, "%s\t%s\n", path, sd.SDDL())
+			}
+		case "icacls":
+			_, err = io.WriteString(stdout, icacls.Format(path, sd, accountName))
+		case "json":
+			err = encoder.Encode(output{Path: path, SecurityDescriptor: sd, SDDL: sd.SDDL()})
+		default:
+			_, err = io.WriteString(stdout, formatText(path, sd))
+		}
+		return err
+	})
+}
+
+// formatText renders the security descriptor of the path in a human-readable form
+func formatText(path string, sd *descriptor.SecurityDescriptor) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s\n", path)
+	fmt.Fprintf(&sb, "  owner: %s\n", sd.Owner)
+	fmt.Fprintf(&sb, "  group: %s\n", sd.Group)
+	switch {
+	case sd.DACL == nil:
+		sb.WriteString("  dacl: none (full access for everyone)\n")
+	case sd.IsDACLProtected():
+		sb.WriteString("  dacl (protected):\n")
+	default:
+		sb.WriteString("  dacl:\n")
+	}
+	for _, ace := range sd.DACL {
+		fmt.Fprintf(&sb, "    %s %s: %s", ace.Type, ace.SID, access.FileRightsString(access.FileGenericMapping.Normalize(ace.Mask)))
+		if ace.Flags != 0 {
+			fmt.Fprintf(&sb, " (%s)", ace.Flags)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func runSet(flags *flag.FlagSet, args []string, _ io.Writer) error {
+	recursive := flags.Bool("R", false, "change the files and directories below the paths as well")
+	sddl := flags.String("sddl", "", "security descriptor to write, in SDDL (e.g. O:BAG:SYD:PAI(A;OICI;FA;;;SY))")
+	if err := parse(flags, args, 1); err != nil {
+		return err
+	}
+	if *sddl == "" {
+		return fmt.Errorf("%w: --sddl is required", errUsage)
+	}
+	sd, err := descriptor.ParseSDDL(*sddl)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	info := sd.Information()
+	return walk(flags.Args(), *recursive, func(path string) error {
+		return files.Set(path, sd, info)
+	})
+}
+
+func runReset(flags *flag.FlagSet, args []string, _ io.Writer) error {
+	recursive := flags.Bool("R", false, "reset the files and directories below the paths as well")
+	if err := parse(flags, args, 1); err != nil {
+		return err
+	}
+	return walk(flags.Args(), *recursive, files.Reset)
+}
+
+func runCopy(flags *flag.FlagSet, args []string, _ io.Writer) error {
+	convertInherited := flags.Bool("convert-inherited", false, "copy inherited ACEs as explicit ACEs and protect the DACL of DST")
+	if err := parse(flags, args, 2); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return errUsage
+	}
+	return files.Copy(flags.Arg(0), flags.Arg(1), acl.CopyOptions{ConvertInherited: *convertInherited})
+}
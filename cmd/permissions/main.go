@@ -0,0 +1,150 @@
+//go:build windows || linux
+
+// Command permissions reads and writes the owner, group and permissions of files and directories in the same way on
+// Windows and Linux.
+//
+// Usage:
+//
+//	permissions chmod [-R] MODE PATH...
+//	permissions chown [-R] OWNER[:GROUP] PATH...
+//	permissions get [-R] [-o text|sddl|icacls|json] PATH...
+//	permissions set [-R] --sddl SDDL PATH...
+//	permissions reset [-R] PATH...
+//	permissions copy [--convert-inherited] SRC DST
+//
+// On Windows, owners and groups are account names or SIDs, and the security descriptors read and written by get and
+// set are the ones of the files. On Linux, owners and groups are user and group names or IDs, and security descriptors
+// use the S-1-22-1-<uid> and S-1-22-2-<gid> SIDs of Unix accounts, with a DACL derived from the permission bits.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// command is a subcommand of the tool
+type command struct {
+	usage string
+	run   func(flags *flag.FlagSet, args []string, stdout io.Writer) error
+}
+
+var commands = map[string]command{
+	"chmod": {usage: "chmod [-R] MODE PATH...", run: runChmod},
+	"chown": {usage: "chown [-R] OWNER[:GROUP] PATH...", run: runChown},
+	"get":   {usage: "get [-R] [-o text|sddl|icacls|json] PATH...", run: runGet},
+	"set":   {usage: "set [-R] --sddl SDDL PATH...", run: runSet},
+	"reset": {usage: "reset [-R] PATH...", run: runReset},
+	"copy":  {usage: "copy [--convert-inherited] SRC DST", run: runCopy},
+}
+
+// commandOrder is the order in which the commands are listed in the usage
+var commandOrder = []string{"chmod", "chown", "get", "set", "reset", "copy"}
+
+// errUsage is returned by commands that were called with invalid arguments
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the exit code: 0 on success, 1 if the command failed and 2 if the
+// command line is invalid
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+			usage(stdout)
+			return 0
+		}
+		fmt.Fprintf(stderr, "permissions: unknown command %q\n", args[0])
+		usage(stderr)
+		return 2
+	}
+
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: permissions %s\n", cmd.usage)
+		flags.PrintDefaults()
+	}
+	err := cmd.run(flags, args[1:], stdout)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		flags.Usage()
+		return 2
+	default:
+		fmt.Fprintf(stderr, "permissions %s: %v\n", args[0], err)
+		return 1
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage:")
+	for _, name := range commandOrder {
+		fmt.Fprintf(w, "  permissions %s\n", commands[name].usage)
+	}
+}
+
+// parse parses the flags of the command and checks that at least minArgs arguments remain
+func parse(flags *flag.FlagSet, args []string, minArgs int) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		// the flag package has already reported the error
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if flags.NArg() < minArgs {
+		return errUsage
+	}
+	return nil
+}
+
+// walk calls fn for each path, and for every file / directory below them if recursive is set. Symbolic links below
+// the paths are not followed, and are skipped. All paths are processed, and the errors are joined.
+func walk(paths []string, recursive bool, fn func(path string) error) error {
+	var errs []error
+	for _, root := range paths {
+		if !recursive {
+			if err := withPath(root, fn(root)); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.Type()&fs.ModeSymlink != 0 && path != root {
+				return nil
+			}
+			return withPath(path, fn(path))
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// withPath adds the path to the error, unless it already holds it
+func withPath(path string, err error) error {
+	var pathErr *fs.PathError
+	if err == nil || errors.As(err, &pathErr) {
+		return err
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
@@ -0,0 +1,125 @@
+//go:build linux
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// permissions runs the tool and returns its exit code and output
+func permissions(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestChmodAndGet(t *testing.T) {
+	dir := t.TempDir()
+	f := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(f, nil, 0600))
+
+	code, _, stderr := permissions("chmod", "-R", "0750", dir)
+	require.Equal(t, 0, code, stderr)
+	for _, path := range []string{dir, f} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0750), info.Mode().Perm(), path)
+	}
+
+	owner := descriptor.UnixUserSID(uint32(os.Getuid()))
+	group := descriptor.UnixGroupSID(uint32(os.Getgid()))
+	// chmod removes the named entries of the access ACL, as it replaces the DACL on Windows
+	code, _, stderr = permissions("set", "--sddl", fmt.Sprintf("D:(A;;FA;;;%s)(A;;FR;;;S-1-22-1-12345)", owner), f)
+	require.Equal(t, 0, code, stderr)
+	code, _, stderr = permissions("chmod", "0750", f)
+	require.Equal(t, 0, code, stderr)
+
+	code, stdout, _ := permissions("get", "-o", "sddl", f)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, fmt.Sprintf("O:%sG:%sD:P(A;;0x1301bf;;;%s)(A;;0x1200a9;;;%s)\n", owner, group, owner, group), stdout)
+
+	code, stdout, _ = permissions("get", f)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, f+"\n"+
+		"  owner: "+string(owner)+"\n"+
+		"  group: "+string(group)+"\n"+
+		"  dacl (protected):\n"+
+		"    Allow "+string(owner)+": Modify, Synchronize\n"+
+		"    Allow "+string(group)+": ReadAndExecute, Synchronize\n", stdout)
+
+	code, stdout, _ = permissions("get", "-o", "icacls", f)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, fmt.Sprintf("%s %s:(M)\n%s%s:(RX)\n", f, owner, strings.Repeat(" ", len(f)+1), group), stdout)
+
+	code, stdout, _ = permissions("get", "-R", "-o", "json", dir)
+	assert.Equal(t, 0, code)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 2)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &decoded))
+	assert.Equal(t, f, decoded["path"])
+	assert.Equal(t, string(owner), decoded["owner"])
+	assert.Len(t, decoded["dacl"], 2)
+}
+
+func TestSetAndReset(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	require.NoError(t, os.WriteFile(src, nil, 0600))
+	require.NoError(t, os.WriteFile(dst, nil, 0600))
+
+	owner := descriptor.UnixUserSID(uint32(os.Getuid()))
+	code, _, stderr := permissions("set", "--sddl", fmt.Sprintf("D:(A;;FA;;;%s)(A;;FR;;;WD)", owner), src)
+	require.Equal(t, 0, code, stderr)
+	info, err := os.Stat(src)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0704), info.Mode().Perm())
+
+	code, _, stderr = permissions("copy", src, dst)
+	require.Equal(t, 0, code, stderr)
+	info, err = os.Stat(dst)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0704), info.Mode().Perm())
+
+	code, _, stderr = permissions("chown", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()), dst)
+	assert.Equal(t, 0, code, stderr)
+	code, _, stderr = permissions("reset", "-R", dir)
+	assert.Equal(t, 0, code, stderr)
+
+	code, _, stderr = permissions("set", "--sddl", "D:(D;;FA;;;WD)", src)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, src)
+}
+
+func TestUsage(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"unknown"},
+		{"chmod", "0755"},
+		{"chmod", "u+x", "file"},
+		{"chown", ":", "file"},
+		{"get", "-o", "xml", "file"},
+		{"set", "file"},
+		{"set", "--sddl", "D:(A;;ZZ;;;WD)", "file"},
+		{"copy", "a", "b", "c"},
+	} {
+		code, _, stderr := permissions(args...)
+		assert.Equal(t, 2, code, args)
+		assert.Contains(t, stderr, "usage:", args)
+	}
+
+	code, stdout, _ := permissions("help")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "permissions chmod [-R] MODE PATH...")
+}
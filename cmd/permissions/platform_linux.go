@@ -0,0 +1,11 @@
+//go:build linux
+
+package main
+
+import (
+	"github.com/rancher/permissions/pkg/icacls"
+)
+
+// accountName resolves the SIDs printed by get -o icacls. Unix accounts have no Windows names, so only well-known
+// SIDs are named.
+var accountName icacls.NameFunc = icacls.WellKnownName
@@ -0,0 +1,10 @@
+//go:build windows
+
+package main
+
+import (
+	"github.com/rancher/permissions/pkg/icacls"
+)
+
+// accountName resolves the SIDs printed by get -o icacls
+var accountName icacls.NameFunc = icacls.LookupName
@@ -0,0 +1,39 @@
+//go:build windows
+
+package access
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// AuditSuccessSid creates an EXPLICIT_ACCESS instance auditing successful uses of the permissions by the provided SID.
+func AuditSuccessSid(accessPermissions windows.ACCESS_MASK, sid *windows.SID) windows.EXPLICIT_ACCESS {
+	return audit(accessPermissions, windows.SET_AUDIT_SUCCESS, windows.TRUSTEE_IS_SID, windows.TrusteeValueFromSID(sid))
+}
+
+// AuditFailureSid creates an EXPLICIT_ACCESS instance auditing failed attempts to use the permissions by the provided SID.
+func AuditFailureSid(accessPermissions windows.ACCESS_MASK, sid *windows.SID) windows.EXPLICIT_ACCESS {
+	return audit(accessPermissions, windows.SET_AUDIT_FAILURE, windows.TRUSTEE_IS_SID, windows.TrusteeValueFromSID(sid))
+}
+
+// AuditSuccessName creates an EXPLICIT_ACCESS instance auditing successful uses of the permissions by the provided name.
+func AuditSuccessName(accessPermissions windows.ACCESS_MASK, name string) windows.EXPLICIT_ACCESS {
+	return audit(accessPermissions, windows.SET_AUDIT_SUCCESS, windows.TRUSTEE_IS_NAME, windows.TrusteeValueFromString(name))
+}
+
+// AuditFailureName creates an EXPLICIT_ACCESS instance auditing failed attempts to use the permissions by the provided name.
+func AuditFailureName(accessPermissions windows.ACCESS_MASK, name string) windows.EXPLICIT_ACCESS {
+	return audit(accessPermissions, windows.SET_AUDIT_FAILURE, windows.TRUSTEE_IS_NAME, windows.TrusteeValueFromString(name))
+}
+
+func audit(accessPermissions windows.ACCESS_MASK, mode windows.ACCESS_MODE, form windows.TRUSTEE_FORM, value windows.TrusteeValue) windows.EXPLICIT_ACCESS {
+	return windows.EXPLICIT_ACCESS{
+		AccessPermissions: accessPermissions,
+		AccessMode:        mode,
+		Inheritance:       windows.SUB_CONTAINERS_AND_OBJECTS_INHERIT,
+		Trustee: windows.TRUSTEE{
+			TrusteeForm:  form,
+			TrusteeValue: value,
+		},
+	}
+}
@@ -0,0 +1,97 @@
+package access
+
+import (
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// Privileges that grant access to files regardless of their DACL
+const (
+	// SecurityPrivilege grants AccessSystemSecurity
+	SecurityPrivilege = "SeSecurityPrivilege"
+	// TakeOwnershipPrivilege grants TakeOwnership
+	TakeOwnershipPrivilege = "SeTakeOwnershipPrivilege"
+	// BackupPrivilege grants read access to any file opened with backup semantics
+	BackupPrivilege = "SeBackupPrivilege"
+	// RestorePrivilege grants write access to any file opened with backup semantics
+	RestorePrivilege = "SeRestorePrivilege"
+)
+
+// privilegeRights maps privileges to the rights they grant, as documented for the Windows AccessCheck function
+var privilegeRights = map[string]uint32{
+	SecurityPrivilege:      AccessSystemSecurity,
+	TakeOwnershipPrivilege: TakeOwnership,
+	BackupPrivilege:        ReadPermissions | AccessSystemSecurity | FileGenericMapping.Read | ExecuteFile,
+	RestorePrivilege:       ChangePermissions | TakeOwnership | AccessSystemSecurity | FileGenericMapping.Write | Delete,
+}
+
+// Token describes the security context that requests access to a file, like a Windows access token.
+type Token struct {
+	// User is the SID of the user
+	User descriptor.SID
+	// Groups holds the SIDs of the groups the user belongs to. Windows tokens usually include well-known groups such
+	// as descriptor.Everyone and descriptor.AuthenticatedUsers, which must be listed here to be matched.
+	Groups []descriptor.SID
+	// Privileges holds the names of the enabled privileges (e.g. TakeOwnershipPrivilege)
+	Privileges []string
+}
+
+// hasSID returns true if the SID is the user of the token or one of its groups
+func (t Token) hasSID(sid descriptor.SID) bool {
+	if sid == t.User {
+		return true
+	}
+	for _, group := range t.Groups {
+		if sid == group {
+			return true
+		}
+	}
+	return false
+}
+
+// Result is the outcome of an access check
+type Result struct {
+	// Desired holds the rights that were requested, with generic rights expanded
+	Desired uint32 `json:"desired"`
+	// Granted holds the desired rights that were granted
+	Granted uint32 `json:"granted"`
+	// Denied holds the desired rights that were denied by an access denied ACE
+	Denied uint32 `json:"denied"`
+}
+
+// Allowed returns true if all the desired rights were granted
+func (r Result) Allowed() bool {
+	return r.Granted == r.Desired
+}
+
+// Unsatisfied returns the desired rights that were neither granted nor denied by an ACE. These rights are not granted
+// either.
+func (r Result) Unsatisfied() uint32 {
+	return r.Desired &^ (r.Granted | r.Denied)
+}
+
+// Check evaluates which of the desired rights the DACL of the security descriptor grants to the token, following the
+// rules of the Windows AccessCheck function:
+//
+//   - Generic rights are expanded with FileGenericMapping, both in the desired rights and in the ACEs.
+//   - A missing or NULL DACL grants all rights, and an empty DACL grants none.
+//   - The owner is implicitly granted ReadPermissions and ChangePermissions, unless the DACL has ACEs for
+//     descriptor.OwnerRights, in which case those ACEs determine the rights of the owner.
+//   - ACEs are evaluated in order, and each right is granted or denied by the first ACE that matches the token and
+//     includes it. Inherit-only ACEs are ignored. This is why explicit deny ACEs come first in a canonical ACL.
+//   - Enabled privileges grant their rights before the DACL is evaluated (see privilegeRights). AccessSystemSecurity
+//     can only be granted by a privilege.
+//
+// To find all the rights the token has, pass FileGenericMapping.All as the desired rights. Use Explain to find out
+// which ACEs granted or denied each right.
+func Check(sd *descriptor.SecurityDescriptor, token Token, desired uint32) Result {
+	return Explain(sd, token, desired).Result
+}
+
+func hasOwnerRightsACE(dacl descriptor.ACL) bool {
+	for _, ace := range dacl {
+		if ace.SID == descriptor.OwnerRights && ace.AppliesToObject() {
+			return true
+		}
+	}
+	return false
+}
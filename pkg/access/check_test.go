@@ -0,0 +1,114 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	alice := descriptor.MustParseSID("S-1-5-21-1-2-3-1001")
+	bob := descriptor.MustParseSID("S-1-5-21-1-2-3-1002")
+	aliceToken := Token{User: alice, Groups: []descriptor.SID{descriptor.Everyone, descriptor.BuiltinUsers}}
+	bobToken := Token{User: bob, Groups: []descriptor.SID{descriptor.Everyone, descriptor.BuiltinUsers}}
+
+	dacl := func(aces ...descriptor.ACE) *descriptor.SecurityDescriptor {
+		return &descriptor.SecurityDescriptor{Owner: alice, Control: descriptor.DACLPresent, DACL: append(descriptor.ACL{}, aces...)}
+	}
+
+	testCases := []struct {
+		name    string
+		sd      *descriptor.SecurityDescriptor
+		token   Token
+		desired uint32
+		granted uint32
+		denied  uint32
+	}{
+		{
+			name:    "Granted through a group",
+			sd:      dacl(descriptor.ACE{Type: descriptor.AccessAllowed, Mask: GenericRead | GenericExecute, SID: descriptor.BuiltinUsers}),
+			token:   bobToken,
+			desired: GenericRead,
+			granted: FileGenericMapping.Read,
+		},
+		{
+			name:    "Partially granted",
+			sd:      dacl(descriptor.ACE{Type: descriptor.AccessAllowed, Mask: ReadAndExecute, SID: descriptor.BuiltinUsers}),
+			token:   bobToken,
+			desired: ReadData | WriteData,
+			granted: ReadData,
+		},
+		{
+			name: "Deny takes precedence in canonical order",
+			sd: dacl(
+				descriptor.ACE{Type: descriptor.AccessDenied, Mask: WriteData, SID: bob},
+				descriptor.ACE{Type: descriptor.AccessAllowed, Mask: Modify, SID: descriptor.Everyone},
+			),
+			token:   bobToken,
+			desired: ReadData | WriteData,
+			granted: ReadData,
+			denied:  WriteData,
+		},
+		{
+			name: "First matching ACE wins",
+			sd: dacl(
+				descriptor.ACE{Type: descriptor.AccessAllowed, Mask: Modify, SID: descriptor.Everyone},
+				descriptor.ACE{Type: descriptor.AccessDenied, Mask: WriteData, SID: bob},
+			),
+			token:   bobToken,
+			desired: ReadData | WriteData,
+			granted: ReadData | WriteData,
+		},
+		{
+			name:    "Inherit-only ACEs are ignored",
+			sd:      dacl(descriptor.ACE{Type: descriptor.AccessAllowed, Flags: descriptor.ContainerInherit | descriptor.InheritOnly, Mask: GenericAll, SID: bob}),
+			token:   bobToken,
+			desired: ReadData,
+		},
+		{
+			name:    "Owner can read and change permissions",
+			sd:      dacl(),
+			token:   aliceToken,
+			desired: FileGenericMapping.All,
+			granted: ReadPermissions | ChangePermissions,
+		},
+		{
+			name: "Owner rights replace the implicit owner rights",
+			sd: dacl(
+				descriptor.ACE{Type: descriptor.AccessAllowed, Mask: ReadPermissions | ReadData, SID: descriptor.OwnerRights},
+			),
+			token:   aliceToken,
+			desired: FileGenericMapping.All,
+			granted: ReadPermissions | ReadData,
+		},
+		{
+			name:    "Owner rights only apply to the owner",
+			sd:      dacl(descriptor.ACE{Type: descriptor.AccessAllowed, Mask: FullControl, SID: descriptor.OwnerRights}),
+			token:   bobToken,
+			desired: ReadData,
+		},
+		{
+			name:    "NULL DACL",
+			sd:      &descriptor.SecurityDescriptor{Control: descriptor.DACLPresent},
+			token:   bobToken,
+			desired: GenericAll | AccessSystemSecurity,
+			granted: FullControl,
+		},
+		{
+			name:    "Privileges",
+			sd:      dacl(),
+			token:   Token{User: bob, Privileges: []string{TakeOwnershipPrivilege, SecurityPrivilege}},
+			desired: TakeOwnership | AccessSystemSecurity | ReadData,
+			granted: TakeOwnership | AccessSystemSecurity,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := Check(tc.sd, tc.token, tc.desired)
+			assert.Equal(t, FileRightsString(tc.granted), FileRightsString(result.Granted))
+			assert.Equal(t, FileRightsString(tc.denied), FileRightsString(result.Denied))
+			assert.Equal(t, tc.granted == result.Desired, result.Allowed())
+		})
+	}
+}
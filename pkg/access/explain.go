@@ -0,0 +1,165 @@
+package access
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// StepKind identifies what granted or denied rights in a step of an access check
+type StepKind string
+
+const (
+	// PrivilegeStep grants the rights of an enabled privilege
+	PrivilegeStep StepKind = "Privilege"
+	// NullDACLStep grants all rights because the security descriptor has no DACL or a NULL DACL
+	NullDACLStep StepKind = "NullDACL"
+	// OwnerStep grants the implicit rights of the owner
+	OwnerStep StepKind = "Owner"
+	// ACEStep grants or denies rights with an ACE of the DACL that matches the token
+	ACEStep StepKind = "ACE"
+)
+
+// Step is a step of an access check that could grant or deny rights to the token
+type Step struct {
+	Kind StepKind `json:"kind"`
+	// Privilege is the name of the privilege of a PrivilegeStep
+	Privilege string `json:"privilege,omitempty"`
+	// Index is the position of the ACE of an ACEStep in the DACL, or -1 for other steps
+	Index int `json:"index"`
+	// ACE is the ACE of an ACEStep
+	ACE *descriptor.ACE `json:"ace,omitempty"`
+	// Granted holds the desired rights that were granted in this step. Rights that were granted or denied by a
+	// previous step are not included.
+	Granted uint32 `json:"granted"`
+	// Denied holds the desired rights that were denied in this step
+	Denied uint32 `json:"denied"`
+}
+
+func (s Step) String() string {
+	var sb strings.Builder
+	switch s.Kind {
+	case PrivilegeStep:
+		fmt.Fprintf(&sb, "privilege %s", s.Privilege)
+	case NullDACLStep:
+		sb.WriteString("no DACL")
+	case OwnerStep:
+		sb.WriteString("implicit owner rights")
+	case ACEStep:
+		fmt.Fprintf(&sb, "ACE %d %s %s (%s)", s.Index, s.ACE.Type, s.ACE.SID, FileRightsString(FileGenericMapping.Normalize(s.ACE.Mask)))
+	}
+	switch {
+	case s.Granted != 0:
+		fmt.Fprintf(&sb, ": granted %s", FileRightsString(s.Granted))
+	case s.Denied != 0:
+		fmt.Fprintf(&sb, ": denied %s", FileRightsString(s.Denied))
+	default:
+		sb.WriteString(": no effect")
+	}
+	return sb.String()
+}
+
+// Explanation is the outcome of an access check, with the steps that led to it
+type Explanation struct {
+	Result
+	// Steps lists, in evaluation order, every privilege, implicit right and ACE that matched the token
+	Steps []Step `json:"steps"`
+}
+
+// Explain evaluates the access of the token to the security descriptor like Check, and records each step of the
+// evaluation: the privileges that granted rights, the implicit rights of the owner, and every ACE that matched the
+// token, including ACEs that had no effect because their rights had already been granted or denied. As in Windows,
+// the evaluation of the DACL stops as soon as all the desired rights have been granted or denied, so the ACEs that
+// come after that point are not listed.
+func Explain(sd *descriptor.SecurityDescriptor, token Token, desired uint32) *Explanation {
+	e := &Explanation{Result: Result{Desired: FileGenericMapping.Expand(desired) & (FileGenericMapping.All | AccessSystemSecurity)}}
+
+	for _, privilege := range token.Privileges {
+		if rights := privilegeRights[privilege]; rights&e.Unsatisfied() != 0 {
+			e.grant(Step{Kind: PrivilegeStep, Privilege: privilege, Index: -1}, rights)
+		}
+	}
+	if sd.Control&descriptor.DACLPresent == 0 || sd.DACL == nil {
+		e.grant(Step{Kind: NullDACLStep, Index: -1}, FileGenericMapping.All)
+		return e
+	}
+
+	isOwner := sd.Owner != "" && token.hasSID(sd.Owner)
+	if isOwner && !hasOwnerRightsACE(sd.DACL) {
+		e.grant(Step{Kind: OwnerStep, Index: -1}, ReadPermissions|ChangePermissions)
+	}
+
+	for i, ace := range sd.DACL {
+		if e.Unsatisfied() == 0 {
+			break
+		}
+		if !ace.AppliesToObject() {
+			continue
+		}
+		if ace.Type != descriptor.AccessAllowed && ace.Type != descriptor.AccessDenied {
+			continue
+		}
+		if !token.hasSID(ace.SID) && !(isOwner && ace.SID == descriptor.OwnerRights) {
+			continue
+		}
+		ace := ace
+		step := Step{Kind: ACEStep, Index: i, ACE: &ace}
+		rights := FileGenericMapping.Normalize(ace.Mask)
+		if ace.Type == descriptor.AccessAllowed {
+			e.grant(step, rights)
+		} else {
+			e.deny(step, rights)
+		}
+	}
+	return e
+}
+
+func (e *Explanation) grant(step Step, rights uint32) {
+	step.Granted = rights & e.Unsatisfied()
+	e.Granted |= step.Granted
+	e.Steps = append(e.Steps, step)
+}
+
+func (e *Explanation) deny(step Step, rights uint32) {
+	step.Denied = rights & e.Unsatisfied()
+	e.Denied |= step.Denied
+	e.Steps = append(e.Steps, step)
+}
+
+// String renders the explanation as a human-readable trace, with one line per step
+func (e *Explanation) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "desired: %s\n", FileRightsString(e.Desired))
+	for _, step := range e.Steps {
+		fmt.Fprintf(&sb, "  %s\n", step)
+	}
+	fmt.Fprintf(&sb, "granted: %s\n", FileRightsString(e.Granted))
+	if e.Denied != 0 {
+		fmt.Fprintf(&sb, "denied: %s\n", FileRightsString(e.Denied))
+	}
+	if unsatisfied := e.Unsatisfied(); unsatisfied != 0 {
+		fmt.Fprintf(&sb, "not granted by any ACE: %s\n", FileRightsString(unsatisfied))
+	}
+	if e.Allowed() {
+		sb.WriteString("access allowed\n")
+	} else {
+		sb.WriteString("access denied\n")
+	}
+	return sb.String()
+}
+
+// MarshalJSON renders the explanation as JSON, including the unsatisfied rights and whether access is allowed
+func (e *Explanation) MarshalJSON() ([]byte, error) {
+	type explanation Explanation
+	return json.Marshal(struct {
+		*explanation
+		Unsatisfied uint32 `json:"unsatisfied"`
+		Allowed     bool   `json:"allowed"`
+	}{
+		explanation: (*explanation)(e),
+		Unsatisfied: e.Unsatisfied(),
+		Allowed:     e.Allowed(),
+	})
+}
@@ -0,0 +1,74 @@
+package access
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	bob := descriptor.MustParseSID("S-1-5-21-1-2-3-1002")
+	token := Token{User: bob, Groups: []descriptor.SID{descriptor.Everyone, descriptor.BuiltinUsers}}
+	sd := &descriptor.SecurityDescriptor{
+		Owner:   descriptor.BuiltinAdministrators,
+		Control: descriptor.DACLPresent,
+		DACL: descriptor.ACL{
+			{Type: descriptor.AccessDenied, Mask: Delete, SID: bob},
+			{Type: descriptor.AccessAllowed, Mask: FullControl, SID: descriptor.BuiltinAdministrators},
+			{Type: descriptor.AccessAllowed, Flags: descriptor.ObjectInherit | descriptor.InheritOnly, Mask: GenericAll, SID: bob},
+			{Type: descriptor.AccessAllowed, Mask: GenericRead, SID: descriptor.BuiltinUsers},
+			{Type: descriptor.AccessAllowed, Mask: ReadData, SID: descriptor.Everyone},
+		},
+	}
+
+	e := Explain(sd, token, GenericRead|Delete|WriteData)
+	assert.Equal(t, Check(sd, token, GenericRead|Delete|WriteData), e.Result)
+	assert.False(t, e.Allowed())
+	assert.Equal(t, FileGenericMapping.Read, e.Granted)
+	assert.Equal(t, Delete, e.Denied)
+	assert.Equal(t, WriteData, e.Unsatisfied())
+
+	if assert.Len(t, e.Steps, 3) {
+		assert.Equal(t, 0, e.Steps[0].Index)
+		assert.Equal(t, Delete, e.Steps[0].Denied)
+		assert.Equal(t, 3, e.Steps[1].Index)
+		assert.Equal(t, FileGenericMapping.Read, e.Steps[1].Granted)
+		assert.Equal(t, 4, e.Steps[2].Index)
+		assert.Zero(t, e.Steps[2].Granted)
+	}
+
+	assert.Equal(t, "desired: Read, WriteData, Delete, Synchronize\n"+
+		"  ACE 0 Deny S-1-5-21-1-2-3-1002 (Delete): denied Delete\n"+
+		"  ACE 3 Allow S-1-5-32-545 (Read, Synchronize): granted Read, Synchronize\n"+
+		"  ACE 4 Allow S-1-1-0 (ReadData): no effect\n"+
+		"granted: Read, Synchronize\n"+
+		"denied: Delete\n"+
+		"not granted by any ACE: WriteData\n"+
+		"access denied\n", e.String())
+
+	out, err := json.Marshal(e)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	assert.Equal(t, false, decoded["allowed"])
+	assert.EqualValues(t, WriteData, decoded["unsatisfied"])
+	assert.EqualValues(t, Delete, decoded["denied"])
+	assert.Len(t, decoded["steps"], 3)
+	assert.Contains(t, string(out), `"ace":{"type":"Deny","mask":65536,"sid":"S-1-5-21-1-2-3-1002"}`)
+}
+
+func TestExplainOwnerAndPrivileges(t *testing.T) {
+	owner := descriptor.MustParseSID("S-1-5-21-1-2-3-1001")
+	sd := &descriptor.SecurityDescriptor{Owner: owner, Control: descriptor.DACLPresent, DACL: descriptor.ACL{}}
+	token := Token{User: owner, Privileges: []string{TakeOwnershipPrivilege, SecurityPrivilege}}
+
+	e := Explain(sd, token, ReadPermissions|TakeOwnership)
+	assert.True(t, e.Allowed())
+	if assert.Len(t, e.Steps, 2) {
+		assert.Equal(t, Step{Kind: PrivilegeStep, Privilege: TakeOwnershipPrivilege, Index: -1, Granted: TakeOwnership}, e.Steps[0])
+		assert.Equal(t, Step{Kind: OwnerStep, Index: -1, Granted: ReadPermissions}, e.Steps[1])
+	}
+}
@@ -0,0 +1,60 @@
+package access
+
+// Generic access rights as defined in winnt.h. These have the same values as windows.GENERIC_*, but they are defined
+// here so that access masks can be compared on any platform.
+const (
+	GenericRead    uint32 = 0x80000000
+	GenericWrite   uint32 = 0x40000000
+	GenericExecute uint32 = 0x20000000
+	GenericAll     uint32 = 0x10000000
+
+	genericMask = GenericRead | GenericWrite | GenericExecute | GenericAll
+)
+
+// GenericMapping describes how generic access rights map to the standard and specific rights of an object type.
+// It mirrors the GENERIC_MAPPING structure used by the Windows MapGenericMask function.
+type GenericMapping struct {
+	Read    uint32
+	Write   uint32
+	Execute uint32
+	All     uint32
+}
+
+// FileGenericMapping is the generic mapping used by Windows for files and directories.
+var FileGenericMapping = GenericMapping{
+	Read:    0x00120089, // FILE_GENERIC_READ
+	Write:   0x00120116, // FILE_GENERIC_WRITE
+	Execute: 0x001200A0, // FILE_GENERIC_EXECUTE
+	All:     0x001F01FF, // FILE_ALL_ACCESS
+}
+
+// Expand replaces the generic bits of the mask with the rights they map to. All other bits are returned unchanged.
+func (m GenericMapping) Expand(mask uint32) uint32 {
+	if mask&GenericRead != 0 {
+		mask |= m.Read
+	}
+	if mask&GenericWrite != 0 {
+		mask |= m.Write
+	}
+	if mask&GenericExecute != 0 {
+		mask |= m.Execute
+	}
+	if mask&GenericAll != 0 {
+		mask |= m.All
+	}
+	return mask &^ genericMask
+}
+
+// Normalize expands the generic bits of the mask and drops every bit that has no meaning for the object type
+// (such as MAXIMUM_ALLOWED).
+//
+// Windows stores generic rights unexpanded in inheritable ACEs but expanded in the ACEs that apply to the object
+// itself, so masks should always be normalized before they are compared.
+func (m GenericMapping) Normalize(mask uint32) uint32 {
+	return m.Expand(mask) & m.All
+}
+
+// Equal returns true if both masks grant the same rights once normalized.
+func (m GenericMapping) Equal(a, b uint32) bool {
+	return m.Normalize(a) == m.Normalize(b)
+}
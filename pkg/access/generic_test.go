@@ -0,0 +1,60 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mask     uint32
+		expected uint32
+	}{
+		{
+			name:     "Generic read",
+			mask:     GenericRead,
+			expected: FileGenericMapping.Read,
+		},
+		{
+			name:     "Generic read and execute",
+			mask:     GenericRead | GenericExecute,
+			expected: 0x001200A9,
+		},
+		{
+			name:     "Generic all",
+			mask:     GenericAll,
+			expected: FileGenericMapping.All,
+		},
+		{
+			name:     "Specific rights are kept",
+			mask:     0x00010000 | 0x00000001,
+			expected: 0x00010001,
+		},
+		{
+			name:     "Irrelevant bits are dropped",
+			mask:     GenericWrite | 0x02000000,
+			expected: FileGenericMapping.Write,
+		},
+		{
+			name:     "Zero mask",
+			mask:     0,
+			expected: 0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, FileGenericMapping.Normalize(tc.mask))
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	// 0755 as produced by filemode.Convert for the owner: GENERIC_READ | GENERIC_WRITE | GENERIC_EXECUTE | DELETE
+	requested := GenericRead | GenericWrite | GenericExecute | 0x00010000
+	// the same rights as reported by Windows once the ACE has been applied to the object ("Modify, Synchronize")
+	actual := uint32(0x001301BF)
+	assert.True(t, FileGenericMapping.Equal(requested, actual))
+	assert.False(t, FileGenericMapping.Equal(GenericRead, actual))
+}
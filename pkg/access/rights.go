@@ -0,0 +1,114 @@
+package access
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// File and directory access rights. The names match the members of the .NET FileSystemRights enumeration, which is
+// what Get-Acl reports.
+const (
+	ReadData                     uint32 = 0x00000001
+	WriteData                    uint32 = 0x00000002
+	AppendData                   uint32 = 0x00000004
+	ReadExtendedAttributes       uint32 = 0x00000008
+	WriteExtendedAttributes      uint32 = 0x00000010
+	ExecuteFile                  uint32 = 0x00000020
+	DeleteSubdirectoriesAndFiles uint32 = 0x00000040
+	ReadAttributes               uint32 = 0x00000080
+	WriteAttributes              uint32 = 0x00000100
+	Delete                       uint32 = 0x00010000
+	ReadPermissions              uint32 = 0x00020000
+	ChangePermissions            uint32 = 0x00040000
+	TakeOwnership                uint32 = 0x00080000
+	Synchronize                  uint32 = 0x00100000
+	// AccessSystemSecurity is required to read or write the SACL. It is never granted by a DACL, only by the
+	// SeSecurityPrivilege privilege.
+	AccessSystemSecurity uint32 = 0x01000000
+
+	Read           = ReadData | ReadExtendedAttributes | ReadAttributes | ReadPermissions
+	Write          = WriteData | AppendData | WriteExtendedAttributes | WriteAttributes
+	ReadAndExecute = Read | ExecuteFile
+	Modify         = ReadAndExecute | Write | Delete
+	FullControl    = Modify | DeleteSubdirectoriesAndFiles | ChangePermissions | TakeOwnership | Synchronize
+)
+
+// fileRightNames lists the names of the file rights, with combined rights first so that they are preferred when
+// formatting a mask
+var fileRightNames = []struct {
+	name string
+	mask uint32
+}{
+	{"FullControl", FullControl},
+	{"Modify", Modify},
+	{"ReadAndExecute", ReadAndExecute},
+	{"Read", Read},
+	{"Write", Write},
+	{"ReadData", ReadData},
+	{"WriteData", WriteData},
+	{"AppendData", AppendData},
+	{"ReadExtendedAttributes", ReadExtendedAttributes},
+	{"WriteExtendedAttributes", WriteExtendedAttributes},
+	{"ExecuteFile", ExecuteFile},
+	{"DeleteSubdirectoriesAndFiles", DeleteSubdirectoriesAndFiles},
+	{"ReadAttributes", ReadAttributes},
+	{"WriteAttributes", WriteAttributes},
+	{"Delete", Delete},
+	{"ReadPermissions", ReadPermissions},
+	{"ChangePermissions", ChangePermissions},
+	{"TakeOwnership", TakeOwnership},
+	{"Synchronize", Synchronize},
+	{"AccessSystemSecurity", AccessSystemSecurity},
+	{"GenericRead", GenericRead},
+	{"GenericWrite", GenericWrite},
+	{"GenericExecute", GenericExecute},
+	{"GenericAll", GenericAll},
+}
+
+// FileRightsString returns the names of the file rights in the mask, separated by commas (e.g. "Modify, Synchronize").
+// Bits that do not correspond to a file right are formatted as a hexadecimal number.
+func FileRightsString(mask uint32) string {
+	if mask == 0 {
+		return "None"
+	}
+	var names []string
+	for _, right := range fileRightNames {
+		if mask&right.mask == right.mask {
+			names = append(names, right.name)
+			mask &^= right.mask
+		}
+	}
+	if mask != 0 {
+		names = append(names, fmt.Sprintf("%#x", mask))
+	}
+	return strings.Join(names, ", ")
+}
+
+// ParseFileRights parses the names of file rights separated by commas, as returned by FileRightsString. Hexadecimal
+// and decimal numbers are also accepted.
+func ParseFileRights(s string) (uint32, error) {
+	var mask uint32
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || strings.EqualFold(name, "None") {
+			continue
+		}
+		if v, err := strconv.ParseUint(name, 0, 32); err == nil {
+			mask |= uint32(v)
+			continue
+		}
+		found := false
+		for _, right := range fileRightNames {
+			if strings.EqualFold(right.name, name) {
+				mask |= right.mask
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown file right %q", name)
+		}
+	}
+	return mask, nil
+}
@@ -0,0 +1,24 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileRightsString(t *testing.T) {
+	assert.Equal(t, "Modify, Synchronize", FileRightsString(0x001301BF))
+	assert.Equal(t, "FullControl", FileRightsString(FileGenericMapping.All))
+	assert.Equal(t, "ReadAndExecute, Synchronize", FileRightsString(FileGenericMapping.Normalize(GenericRead|GenericExecute)))
+	assert.Equal(t, "GenericRead, GenericWrite", FileRightsString(GenericRead|GenericWrite))
+	assert.Equal(t, "Delete, 0x2000000", FileRightsString(Delete|0x02000000))
+	assert.Equal(t, "None", FileRightsString(0))
+
+	for _, mask := range []uint32{0x001301BF, FullControl, Read | Delete, GenericAll, 0x02000000} {
+		parsed, err := ParseFileRights(FileRightsString(mask))
+		assert.NoError(t, err)
+		assert.Equal(t, mask, parsed)
+	}
+	_, err := ParseFileRights("Read, Fly")
+	assert.Error(t, err)
+}
@@ -0,0 +1,411 @@
+// Package acltest provides an in-memory acl.Backend, so that code that reads and writes permissions through
+// pkg/acl can be unit tested on any platform, without touching the file system.
+package acltest
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rancher/permissions/pkg/acl"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/filemode"
+)
+
+// Op identifies the Backend operations that errors can be injected into
+type Op string
+
+// Backend operations
+const (
+	OpGet     Op = "get"
+	OpSet     Op = "set"
+	OpMkdir   Op = "mkdir"
+	OpCreate  Op = "create"
+	OpLstat   Op = "lstat"
+	OpReadDir Op = "readdir"
+)
+
+// Backend is an in-memory acl.Backend. It holds a tree of files and directories, each with its security descriptor,
+// and emulates how Windows applies inheritance: new files and directories inherit the inheritable ACEs of their
+// parent, as computed by acl.InheritedACEs, and the inherited ACEs of a DACL or SACL that is not protected are
+// recomputed whenever it is written, including in the files and directories below it.
+//
+// Chmod, Copy and Reset are made of a Get and a Set, as they behave on Windows, so the errors injected into OpGet and
+// OpSet also apply to them.
+//
+// Lstat and ReadDir describe the files and directories of the backend, whose modes have no permission bits. There are
+// no symbolic links.
+//
+// Paths are cleaned with filepath.Clean, and the parent of a new file or directory must exist. Roots are added with
+// Add. Errors can be injected with Fail. A Backend is safe for concurrent use.
+type Backend struct {
+	// Owner and Group are the owner and group of the files and directories created with Mkdir and Create, which
+	// also replace Creator Owner and Creator Group in their inherited ACEs
+	Owner descriptor.SID
+	Group descriptor.SID
+
+	mu     sync.Mutex
+	files  map[string]*file
+	faults map[fault]error
+}
+
+type file struct {
+	isDir bool
+	sd    *descriptor.SecurityDescriptor
+}
+
+type fault struct {
+	op   Op
+	path string
+}
+
+var _ acl.Backend = &Backend{}
+
+// New returns an empty backend whose files and directories are created by BUILTIN\Administrators, with Local System
+// as their group
+func New() *Backend {
+	return &Backend{
+		Owner:  descriptor.BuiltinAdministrators,
+		Group:  descriptor.LocalSystem,
+		files:  make(map[string]*file),
+		faults: make(map[fault]error),
+	}
+}
+
+// Add adds a file (or directory, if isDir is set) with the security descriptor as is, replacing any existing entry.
+// Unlike Mkdir and Create, it does not require the parent to exist and does not apply inheritance, so it is used to
+// set up the roots of the tree.
+func (b *Backend) Add(path string, isDir bool, sd *descriptor.SecurityDescriptor) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.files[filepath.Clean(path)] = &file{isDir: isDir, sd: clone(sd)}
+}
+
+// Paths returns the paths of the files and directories of the backend, in lexical order
+func (b *Backend) Paths() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	paths := make([]string, 0, len(b.files))
+	for path := range b.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Fail makes the operation fail with err on the path, until Fail is called again with a nil error. An empty path
+// makes the operation fail on every path. The error is wrapped in an *fs.PathError, so ErrAccessDenied and
+// ErrSharingViolation can be checked with errors.Is.
+func (b *Backend) Fail(op Op, path string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if path != "" {
+		path = filepath.Clean(path)
+	}
+	key := fault{op: op, path: path}
+	if err == nil {
+		delete(b.faults, key)
+		return
+	}
+	b.faults[key] = err
+}
+
+// fault returns the error injected into the operation on the path, if any
+func (b *Backend) fault(op Op, path string) error {
+	err, ok := b.faults[fault{op: op, path: path}]
+	if !ok {
+		err, ok = b.faults[fault{op: op}]
+	}
+	if !ok {
+		return nil
+	}
+	return &fs.PathError{Op: string(op), Path: path, Err: err}
+}
+
+// Get implements acl.Backend
+func (b *Backend) Get(path string, info descriptor.Information) (*descriptor.SecurityDescriptor, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	path = filepath.Clean(path)
+	if err := b.fault(OpGet, path); err != nil {
+		return nil, err
+	}
+	f, ok := b.files[path]
+	if !ok {
+		return nil, &fs.PathError{Op: string(OpGet), Path: path, Err: fs.ErrNotExist}
+	}
+	return f.sd.Select(info), nil
+}
+
+// Set implements acl.Backend
+func (b *Backend) Set(path string, sd *descriptor.SecurityDescriptor, info descriptor.Information) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	path = filepath.Clean(path)
+	if err := b.fault(OpSet, path); err != nil {
+		return err
+	}
+	f, ok := b.files[path]
+	if !ok {
+		return &fs.PathError{Op: string(OpSet), Path: path, Err: fs.ErrNotExist}
+	}
+	if info&descriptor.OwnerInformation != 0 {
+		if !sd.Owner.Valid() {
+			return fmt.Errorf("invalid owner %q", sd.Owner)
+		}
+		f.sd.Owner = sd.Owner
+	}
+	if info&descriptor.GroupInformation != 0 {
+		if !sd.Group.Valid() {
+			return fmt.Errorf("invalid group %q", sd.Group)
+		}
+		f.sd.Group = sd.Group
+	}
+	const daclControl = descriptor.DACLPresent | descriptor.DACLProtected | descriptor.DACLAutoInherited |
+		descriptor.DACLAutoInheritReq | descriptor.DACLDefaulted
+	const saclControl = descriptor.SACLPresent | descriptor.SACLProtected | descriptor.SACLAutoInherited |
+		descriptor.SACLAutoInheritReq | descriptor.SACLDefaulted
+	if info&descriptor.DACLInformation != 0 {
+		if sd.Control&descriptor.DACLPresent == 0 {
+			return fmt.Errorf("the DACL is selected, but descriptor.DACLPresent is not set")
+		}
+		f.sd.DACL = nil
+		if sd.DACL != nil {
+			f.sd.DACL = append(descriptor.ACL{}, sd.DACL...)
+		}
+		f.sd.Control = f.sd.Control&^daclControl | sd.Control&daclControl | descriptor.DACLPresent
+	}
+	// audit ACEs and mandatory labels are both stored in the SACL, but they are written independently
+	if info&descriptor.SACLInformation != 0 {
+		f.sd.SACL = replaceACEs(f.sd.SACL, sd.SACL, false)
+		f.sd.Control = f.sd.Control&^saclControl | sd.Control&saclControl | descriptor.SACLPresent
+	}
+	if info&descriptor.LabelInformation != 0 {
+		f.sd.SACL = replaceACEs(f.sd.SACL, sd.SACL, true)
+		f.sd.Control |= descriptor.SACLPresent
+	}
+	if info&(descriptor.DACLInformation|descriptor.SACLInformation|descriptor.LabelInformation) != 0 {
+		b.propagate(path)
+	}
+	return nil
+}
+
+// Mkdir implements acl.Backend
+func (b *Backend) Mkdir(path string, aces ...descriptor.ACE) error {
+	return b.create(OpMkdir, path, true, aces)
+}
+
+// Create implements acl.Backend
+func (b *Backend) Create(path string, aces ...descriptor.ACE) error {
+	return b.create(OpCreate, path, false, aces)
+}
+
+// Chmod implements acl.Backend. The DACL is replaced with the ACEs of filemode.ToDACL for the owner and group of the
+// path, which are inherited by files and directories, and it is protected.
+func (b *Backend) Chmod(path string, fileMode os.FileMode) error {
+	current, err := b.Get(path, descriptor.OwnerInformation|descriptor.GroupInformation)
+	if err != nil {
+		return err
+	}
+	aces := filemode.ToDACL(fileMode, current.Owner, current.Group)
+	for i := range aces {
+		aces[i].Flags = descriptor.ObjectInherit | descriptor.ContainerInherit
+	}
+	sd := &descriptor.SecurityDescriptor{
+		Control: descriptor.DACLPresent | descriptor.DACLProtected,
+		DACL:    descriptor.ACL(aces).Canonicalize(),
+	}
+	return b.Set(path, sd, descriptor.DACLInformation)
+}
+
+// Copy implements acl.Backend. The parts of the security descriptor selected by opts are read from src and written to
+// dst.
+func (b *Backend) Copy(src, dst string, opts acl.CopyOptions) error {
+	if opts.Information == 0 {
+		opts.Information = descriptor.DefaultInformation
+	}
+	sd, err := b.Get(src, opts.Information)
+	if err != nil {
+		return err
+	}
+	if opts.ConvertInherited && opts.Information&descriptor.DACLInformation != 0 {
+		for i := range sd.DACL {
+			sd.DACL[i].Flags &^= descriptor.Inherited
+		}
+		sd.Control |= descriptor.DACLProtected
+	}
+	return b.Set(dst, sd, opts.Information)
+}
+
+// Reset implements acl.Backend. An empty DACL that is not protected is written, so that only the ACEs inherited from
+// the parent apply.
+func (b *Backend) Reset(path string) error {
+	return b.Set(path, &descriptor.SecurityDescriptor{Control: descriptor.DACLPresent, DACL: descriptor.ACL{}},
+		descriptor.DACLInformation)
+}
+
+// Lstat implements acl.Backend
+func (b *Backend) Lstat(path string) (fs.FileInfo, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	path = filepath.Clean(path)
+	if err := b.fault(OpLstat, path); err != nil {
+		return nil, err
+	}
+	f, ok := b.files[path]
+	if !ok {
+		return nil, &fs.PathError{Op: string(OpLstat), Path: path, Err: fs.ErrNotExist}
+	}
+	return fileInfo{name: filepath.Base(path), isDir: f.isDir}, nil
+}
+
+// ReadDir implements acl.Backend
+func (b *Backend) ReadDir(path string) ([]fs.DirEntry, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	path = filepath.Clean(path)
+	if err := b.fault(OpReadDir, path); err != nil {
+		return nil, err
+	}
+	f, ok := b.files[path]
+	if !ok {
+		return nil, &fs.PathError{Op: string(OpReadDir), Path: path, Err: fs.ErrNotExist}
+	}
+	if !f.isDir {
+		return nil, &fs.PathError{Op: string(OpReadDir), Path: path, Err: fmt.Errorf("not a directory")}
+	}
+	var entries []fs.DirEntry
+	for p, child := range b.files {
+		if p != path && filepath.Dir(p) == path {
+			entries = append(entries, fs.FileInfoToDirEntry(fileInfo{name: filepath.Base(p), isDir: child.isDir}))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func (b *Backend) create(op Op, path string, isDir bool, aces descriptor.ACL) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	path = filepath.Clean(path)
+	if err := b.fault(op, path); err != nil {
+		return err
+	}
+	f, exists := b.files[path]
+	if !exists {
+		parent, ok := b.files[filepath.Dir(path)]
+		if !ok || filepath.Dir(path) == path {
+			return &fs.PathError{Op: string(op), Path: path, Err: fs.ErrNotExist}
+		}
+		if !parent.isDir {
+			return &fs.PathError{Op: string(op), Path: path, Err: fmt.Errorf("%s is not a directory", filepath.Dir(path))}
+		}
+		f = &file{isDir: isDir, sd: &descriptor.SecurityDescriptor{
+			Owner:   b.Owner,
+			Group:   b.Group,
+			Control: descriptor.DACLPresent | descriptor.DACLAutoInherited,
+			DACL:    descriptor.ACL{},
+		}}
+		if parent.sd.Control&descriptor.SACLPresent != 0 {
+			f.sd.Control |= descriptor.SACLPresent | descriptor.SACLAutoInherited
+			f.sd.SACL = descriptor.ACL{}
+		}
+		b.files[path] = f
+	} else if f.isDir != isDir {
+		return &fs.PathError{Op: string(op), Path: path, Err: fs.ErrExist}
+	}
+	if len(aces) > 0 || !exists {
+		if len(aces) > 0 {
+			f.sd.DACL = append(descriptor.ACL{}, aces...)
+			f.sd.Control |= descriptor.DACLProtected
+		}
+		b.propagate(path)
+	}
+	return nil
+}
+
+// propagate recomputes the inherited ACEs of the path and of the files and directories below it
+func (b *Backend) propagate(path string) {
+	var paths []string
+	for p := range b.files {
+		if p == path || strings.HasPrefix(p, path+string(filepath.Separator)) {
+			paths = append(paths, p)
+		}
+	}
+	// parents are updated before their children
+	sort.Slice(paths, func(i, j int) bool {
+		return strings.Count(paths[i], string(filepath.Separator)) < strings.Count(paths[j], string(filepath.Separator))
+	})
+	for _, p := range paths {
+		parent, ok := b.files[filepath.Dir(p)]
+		if !ok || filepath.Dir(p) == p {
+			continue
+		}
+		f := b.files[p]
+		if f.sd.Control&descriptor.DACLProtected == 0 && f.sd.DACL != nil {
+			f.sd.DACL = inherit(f.sd.DACL, parent.sd.DACL, f.isDir, f.sd.Owner, f.sd.Group)
+		}
+		if f.sd.Control&descriptor.SACLProtected == 0 && f.sd.SACL != nil {
+			f.sd.SACL = inherit(f.sd.SACL, parent.sd.SACL, f.isDir, f.sd.Owner, f.sd.Group)
+		}
+	}
+}
+
+// inherit returns the explicit ACEs of the ACL, followed by the ACEs inherited from the ACL of the parent
+func inherit(current, parent descriptor.ACL, isDir bool, owner, group descriptor.SID) descriptor.ACL {
+	result := append(descriptor.ACL{}, current.Explicit()...)
+	return append(result, acl.InheritedACEs(parent, isDir, owner, group)...)
+}
+
+// fileInfo describes a file or directory of the backend
+type fileInfo struct {
+	name  string
+	isDir bool
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return 0 }
+func (i fileInfo) ModTime() time.Time { return time.Time{} }
+func (i fileInfo) IsDir() bool        { return i.isDir }
+func (i fileInfo) Sys() any           { return nil }
+
+func (i fileInfo) Mode() fs.FileMode {
+	if i.isDir {
+		return fs.ModeDir
+	}
+	return 0
+}
+
+// replaceACEs replaces the mandatory label ACEs of the SACL (or its other ACEs, if labels is false) with those of
+// the new SACL
+func replaceACEs(sacl, with descriptor.ACL, labels bool) descriptor.ACL {
+	result := descriptor.ACL{}
+	for _, ace := range sacl {
+		if (ace.Type == descriptor.SystemMandatoryLabel) != labels {
+			result = append(result, ace)
+		}
+	}
+	for _, ace := range with {
+		if (ace.Type == descriptor.SystemMandatoryLabel) == labels {
+			result = append(result, ace)
+		}
+	}
+	return result
+}
+
+// clone returns a deep copy of the security descriptor
+func clone(sd *descriptor.SecurityDescriptor) *descriptor.SecurityDescriptor {
+	c := *sd
+	if sd.DACL != nil {
+		c.DACL = append(descriptor.ACL{}, sd.DACL...)
+	}
+	if sd.SACL != nil {
+		c.SACL = append(descriptor.ACL{}, sd.SACL...)
+	}
+	return &c
+}
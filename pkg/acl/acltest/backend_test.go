@@ -0,0 +1,115 @@
+package acltest
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackend(t *testing.T) {
+	b := New()
+	root := filepath.Join("data")
+	rootSD, err := descriptor.ParseSDDL("O:SYG:SYD:PAI(A;OICI;FA;;;SY)(A;OICIIO;GA;;;CO)(A;OICI;0x1200a9;;;BU)")
+	require.NoError(t, err)
+	b.Add(root, true, rootSD)
+
+	dir := filepath.Join(root, "dir")
+	require.NoError(t, b.Mkdir(dir))
+	sd, err := b.Get(dir, descriptor.DefaultInformation)
+	require.NoError(t, err)
+	assert.Equal(t, "O:BAG:SYD:AI(A;OICIID;FA;;;SY)(A;ID;FA;;;BA)(A;OICIIOID;GA;;;CO)(A;OICIID;0x1200a9;;;BU)", sd.SDDL())
+
+	f := filepath.Join(dir, "file")
+	bob := descriptor.MustParseSID("S-1-5-21-1-2-3-1001")
+	modify := descriptor.ACE{Type: descriptor.AccessAllowed, Mask: access.Modify, SID: bob}
+	require.NoError(t, b.Create(f, modify))
+	sd, err = b.Get(f, descriptor.DACLInformation)
+	require.NoError(t, err)
+	assert.Equal(t, "D:PAI(A;;RCSDCCDCLCSWRPWPLOCR;;;S-1-5-21-1-2-3-1001)", sd.SDDL(), "the ACEs should be protected")
+
+	// writing a DACL that is not protected restores inheritance
+	require.NoError(t, b.Set(f, &descriptor.SecurityDescriptor{Control: descriptor.DACLPresent, DACL: descriptor.ACL{modify}},
+		descriptor.DACLInformation))
+	sd, err = b.Get(f, descriptor.DACLInformation)
+	require.NoError(t, err)
+	assert.Equal(t, "D:(A;;RCSDCCDCLCSWRPWPLOCR;;;S-1-5-21-1-2-3-1001)(A;ID;FA;;;SY)(A;ID;FA;;;BA)(A;ID;0x1200a9;;;BU)", sd.SDDL())
+
+	// changing an ACL propagates to the children that are not protected
+	require.NoError(t, b.Set(dir, &descriptor.SecurityDescriptor{
+		Control: descriptor.DACLPresent,
+		DACL:    descriptor.ACL{{Type: descriptor.AccessDenied, Flags: descriptor.ObjectInherit, Mask: access.Delete, SID: bob}},
+	}, descriptor.DACLInformation))
+	sd, err = b.Get(f, descriptor.DACLInformation)
+	require.NoError(t, err)
+	assert.Equal(t, descriptor.ACE{Type: descriptor.AccessDenied, Flags: descriptor.Inherited, Mask: access.Delete, SID: bob}, sd.DACL[1])
+
+	require.NoError(t, b.Set(f, &descriptor.SecurityDescriptor{
+		Control: descriptor.DACLPresent | descriptor.DACLProtected,
+		DACL:    descriptor.ACL{{Type: descriptor.AccessAllowed, Mask: access.Read, SID: bob}},
+	}, descriptor.DACLInformation))
+	require.NoError(t, b.Set(root, &descriptor.SecurityDescriptor{Control: descriptor.DACLPresent, DACL: descriptor.ACL{}},
+		descriptor.DACLInformation))
+	sd, err = b.Get(dir, descriptor.DACLInformation)
+	require.NoError(t, err)
+	assert.Equal(t, "D:(D;OI;SD;;;S-1-5-21-1-2-3-1001)", sd.SDDL())
+	sd, err = b.Get(f, descriptor.DACLInformation)
+	require.NoError(t, err)
+	assert.Equal(t, "D:P(A;;RCCCSWLO;;;S-1-5-21-1-2-3-1001)", sd.SDDL(), "protected ACLs should not change")
+
+	require.NoError(t, b.Set(f, &descriptor.SecurityDescriptor{Owner: bob}, descriptor.OwnerInformation))
+	sd, err = b.Get(f, descriptor.OwnerInformation)
+	require.NoError(t, err)
+	assert.Equal(t, &descriptor.SecurityDescriptor{Owner: bob}, sd)
+	assert.Equal(t, []string{root, dir, f}, b.Paths())
+
+	info, err := b.Lstat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, "dir", info.Name())
+	assert.True(t, info.IsDir())
+	entries, err := b.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "file", entries[0].Name())
+	assert.False(t, entries[0].IsDir())
+	_, err = b.ReadDir(f)
+	assert.Error(t, err)
+
+	assert.ErrorIs(t, b.Mkdir(filepath.Join(root, "missing", "dir")), fs.ErrNotExist)
+	assert.ErrorIs(t, b.Mkdir(f), fs.ErrExist)
+	assert.Error(t, b.Create(filepath.Join(f, "file")))
+	_, err = b.Get(filepath.Join(root, "missing"), descriptor.DefaultInformation)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.NoError(t, b.Mkdir(dir), "Mkdir should not fail if the directory exists")
+}
+
+func TestFail(t *testing.T) {
+	b := New()
+	b.Add("root", true, &descriptor.SecurityDescriptor{Control: descriptor.DACLPresent, DACL: descriptor.ACL{}})
+
+	b.Fail(OpGet, "root", ErrAccessDenied)
+	_, err := b.Get("root", descriptor.DefaultInformation)
+	assert.ErrorIs(t, err, fs.ErrPermission)
+	var pathErr *fs.PathError
+	if assert.True(t, errors.As(err, &pathErr)) {
+		assert.Equal(t, "root", pathErr.Path)
+	}
+	b.Fail(OpGet, "root", nil)
+	_, err = b.Get("root", descriptor.DefaultInformation)
+	assert.NoError(t, err)
+
+	b.Fail(OpCreate, "", ErrSharingViolation)
+	assert.ErrorIs(t, b.Create(filepath.Join("root", "file")), ErrSharingViolation)
+	assert.NoError(t, b.Mkdir(filepath.Join("root", "dir")))
+	assert.Equal(t, []string{"root", filepath.Join("root", "dir")}, b.Paths())
+
+	assert.Error(t, b.Set(filepath.Join("root", "dir"), &descriptor.SecurityDescriptor{}, descriptor.DACLInformation),
+		"a DACL that is not present should not be written")
+	b.Fail(OpSet, filepath.Join("root", "dir"), ErrAccessDenied)
+	assert.ErrorIs(t, b.Set(filepath.Join("root", "dir"), &descriptor.SecurityDescriptor{}, descriptor.DACLInformation), ErrAccessDenied)
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package acltest
+
+import "golang.org/x/sys/windows"
+
+// Errors that can be injected with Backend.Fail. They match the errors returned by the functions of pkg/acl on the
+// host, so ErrAccessDenied satisfies errors.Is(err, fs.ErrPermission).
+var (
+	ErrAccessDenied     error = windows.ERROR_ACCESS_DENIED
+	ErrSharingViolation error = windows.ERROR_SHARING_VIOLATION
+)
@@ -19,6 +19,9 @@ var defaultChownPermissions fs.FileMode = 0755
 // Group: read, execute
 // Everyone: read, execute
 //
+// The SeRestorePrivilege and SeTakeOwnershipPrivilege are enabled for the duration of the call if the caller holds
+// them, which is required to set the owner to a SID other than the caller.
+//
 // To set custom permissions, use Apply or ApplyCustom instead directly
 func Chown(path string, owner *windows.SID, group *windows.SID) error {
 	return Apply(path, owner, group, filemode.Convert(defaultChownPermissions).ToExplicitAccess()...)
@@ -32,7 +35,14 @@ func Chmod(path string, fileMode os.FileMode) error {
 
 // Mkdir creates a directory with the provided permissions if it does not exist already
 // If it already exists, it just applies the provided permissions
+//
+// It only exists on Windows. Code that builds on both OSes uses Files.Mkdir, which takes descriptor ACEs.
 func Mkdir(path string, access ...windows.EXPLICIT_ACCESS) error {
+	return MkdirCustom(path, Options{}, access...)
+}
+
+// MkdirCustom behaves like Mkdir, but lets the caller customize how the security descriptor is written through opts
+func MkdirCustom(path string, opts Options, access ...windows.EXPLICIT_ACCESS) error {
 	if path == "" {
 		return fmt.Errorf("path cannot be empty")
 	}
@@ -44,7 +54,7 @@ func Mkdir(path string, access ...windows.EXPLICIT_ACCESS) error {
 	exists := !os.IsNotExist(err)
 
 	if exists {
-		return apply(path, nil, nil, access...)
+		return apply(path, nil, nil, opts, access...)
 	}
 
 	// use windows.CreateDirectory instead
@@ -53,8 +63,16 @@ func Mkdir(path string, access ...windows.EXPLICIT_ACCESS) error {
 		return err
 	}
 	args := securityArgs{
-		path:   path,
-		access: access,
+		path:    path,
+		access:  access,
+		options: opts,
+	}
+	if !args.appliesOnCreate() {
+		// the DACL depends on the ACEs inherited from the parent, which only exist once the directory is created
+		if err = windows.CreateDirectory(pathPtr, nil); err != nil {
+			return err
+		}
+		return apply(path, nil, nil, opts, access...)
 	}
 	sa, err := args.ToSecurityAttributes()
 	if err != nil {
@@ -72,18 +90,27 @@ func Apply(path string, owner *windows.SID, group *windows.SID, access ...window
 	if path == "" {
 		return fmt.Errorf("path cannot be empty")
 	}
-	return apply(path, owner, group, access...)
+	return apply(path, owner, group, Options{}, access...)
+}
+
+// ApplyCustom behaves like Apply, but lets the caller customize how the security descriptor is written through opts
+func ApplyCustom(path string, owner *windows.SID, group *windows.SID, opts Options, access ...windows.EXPLICIT_ACCESS) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	return apply(path, owner, group, opts, access...)
 }
 
 // apply performs a Chmod (if owner and group are provided) and sets a custom ACL based on the provided EXPLICIT_ACCESS rules
 // To create EXPLICIT_ACCESS rules, see the helper functions in pkg/access
-func apply(path string, owner *windows.SID, group *windows.SID, access ...windows.EXPLICIT_ACCESS) error {
+func apply(path string, owner *windows.SID, group *windows.SID, opts Options, access ...windows.EXPLICIT_ACCESS) error {
 	// assemble arguments
 	args := securityArgs{
-		path:   path,
-		owner:  owner,
-		group:  group,
-		access: access,
+		path:    path,
+		owner:   owner,
+		group:   group,
+		access:  access,
+		options: opts,
 	}
 
 	securityInfo := args.ToSecurityInfo()
@@ -95,13 +122,9 @@ func apply(path string, owner *windows.SID, group *windows.SID, access ...window
 	if err != nil {
 		return err
 	}
-	return windows.SetNamedSecurityInfo(
-		path,
-		windows.SE_FILE_OBJECT,
-		securityInfo,
-		owner,
-		group,
-		dacl,
-		nil,
-	)
+	sacl, err := args.ToSACL()
+	if err != nil {
+		return err
+	}
+	return setNamedSecurityInfo(path, securityInfo, owner, group, dacl, sacl)
 }
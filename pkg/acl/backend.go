@@ -0,0 +1,35 @@
+package acl
+
+import (
+	"io/fs"
+	"os"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// Backend reads and writes the security descriptors of files and directories. System implements it with the
+// functions of this package, and acltest.Backend with an in-memory file tree, so that code that depends on a Backend
+// can be tested without a Windows host.
+type Backend interface {
+	// Get returns the parts of the security descriptor of the path selected by info
+	Get(path string, info descriptor.Information) (*descriptor.SecurityDescriptor, error)
+	// Set writes the parts of the security descriptor selected by info to the path, as Set does. Unless the DACL
+	// (or SACL) is protected, its inherited ACEs are discarded and recomputed from the parent of the path.
+	Set(path string, sd *descriptor.SecurityDescriptor, info descriptor.Information) error
+	// Mkdir creates a directory that inherits the ACEs of its parent, unless it exists already. If ACEs are
+	// provided, they replace its DACL, which is protected so that it does not inherit ACEs from the parent.
+	Mkdir(path string, aces ...descriptor.ACE) error
+	// Create creates an empty file that inherits the ACEs of its parent, unless it exists already. If ACEs are
+	// provided, they replace its DACL, which is protected so that it does not inherit ACEs from the parent.
+	Create(path string, aces ...descriptor.ACE) error
+	// Chmod replaces the DACL of the path with ACEs that match the unix permissions, as Chmod does
+	Chmod(path string, fileMode os.FileMode) error
+	// Copy makes the security descriptor of dst look like the one of src, as Copy does
+	Copy(src, dst string, opts CopyOptions) error
+	// Reset removes the explicit ACEs of the path and re-enables inheritance, as Reset does
+	Reset(path string) error
+	// Lstat returns the FileInfo of the path, without following symbolic links, as os.Lstat does
+	Lstat(path string) (fs.FileInfo, error)
+	// ReadDir returns the entries of the directory sorted by name, as os.ReadDir does
+	ReadDir(path string) ([]fs.DirEntry, error)
+}
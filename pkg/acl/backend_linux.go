@@ -0,0 +1,21 @@
+//go:build linux
+
+package acl
+
+import (
+	"fmt"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// Get returns the owner, group and DACL of the path selected by info. SACLs do not exist on Linux.
+func (systemBackend) Get(path string, info descriptor.Information) (*descriptor.SecurityDescriptor, error) {
+	if info&(descriptor.SACLInformation|descriptor.LabelInformation) != 0 {
+		return nil, fmt.Errorf("SACLs and mandatory labels are not supported on Linux")
+	}
+	sd, err := Get(path)
+	if err != nil {
+		return nil, err
+	}
+	return sd.Select(info), nil
+}
@@ -0,0 +1,33 @@
+//go:build linux
+
+package acl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/filemode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemBackend(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dir")
+	require.NoError(t, System.Mkdir(dir))
+	f := filepath.Join(dir, "file")
+	owner := descriptor.UnixUserSID(uint32(os.Getuid()))
+	require.NoError(t, System.Create(f, descriptor.ACE{Type: descriptor.AccessAllowed, Mask: filemode.Rights(06), SID: owner}))
+
+	info, err := os.Stat(f)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	sd, err := System.Get(f, descriptor.OwnerInformation)
+	require.NoError(t, err)
+	assert.Equal(t, &descriptor.SecurityDescriptor{Owner: owner}, sd)
+
+	_, err = System.Get(f, descriptor.SACLInformation)
+	assert.Error(t, err)
+	require.NoError(t, System.Create(f), "Create should not fail if the file exists")
+}
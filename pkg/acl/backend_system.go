@@ -0,0 +1,58 @@
+//go:build windows || linux
+
+package acl
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// System is the Backend of the host: Windows ACLs on Windows, and POSIX ACLs mapped to DACLs on Linux
+var System Backend = systemBackend{}
+
+type systemBackend struct{}
+
+func (systemBackend) Set(path string, sd *descriptor.SecurityDescriptor, info descriptor.Information) error {
+	return Set(path, sd, info)
+}
+
+func (systemBackend) Chmod(path string, fileMode os.FileMode) error {
+	return Chmod(path, fileMode)
+}
+
+func (systemBackend) Copy(src, dst string, opts CopyOptions) error {
+	return Copy(src, dst, opts)
+}
+
+func (systemBackend) Reset(path string) error {
+	return Reset(path)
+}
+
+func (systemBackend) Lstat(path string) (fs.FileInfo, error) {
+	return os.Lstat(path)
+}
+
+func (systemBackend) ReadDir(path string) ([]fs.DirEntry, error) {
+	return os.ReadDir(path)
+}
+
+func (b systemBackend) Create(path string, aces ...descriptor.ACE) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if len(aces) == 0 {
+		return nil
+	}
+	sd := &descriptor.SecurityDescriptor{Control: descriptor.DACLPresent | descriptor.DACLProtected, DACL: aces}
+	return b.Set(path, sd, descriptor.DACLInformation)
+}
@@ -0,0 +1,47 @@
+//go:build windows
+
+package acl
+
+import (
+	"fmt"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"golang.org/x/sys/windows"
+)
+
+func (systemBackend) Get(path string, info descriptor.Information) (*descriptor.SecurityDescriptor, error) {
+	return GetCustom(path, info)
+}
+
+// Mkdir creates the directory with MkdirCustom, so that a new directory gets its DACL when it is created
+func (systemBackend) Mkdir(path string, aces ...descriptor.ACE) error {
+	entries, err := explicitAccess(aces)
+	if err != nil {
+		return err
+	}
+	return MkdirCustom(path, Options{}, entries...)
+}
+
+// explicitAccess converts allow and deny ACEs to EXPLICIT_ACCESS entries with the same inheritance flags
+func explicitAccess(aces []descriptor.ACE) ([]windows.EXPLICIT_ACCESS, error) {
+	entries := make([]windows.EXPLICIT_ACCESS, 0, len(aces))
+	for i, ace := range aces {
+		sid, err := windows.StringToSid(ace.SID.String())
+		if err != nil {
+			return nil, fmt.Errorf("ACE %d: invalid SID %s: %w", i, ace.SID, err)
+		}
+		var entry windows.EXPLICIT_ACCESS
+		switch ace.Type {
+		case descriptor.AccessAllowed:
+			entry = access.GrantSid(windows.ACCESS_MASK(ace.Mask), sid)
+		case descriptor.AccessDenied:
+			entry = access.DenySid(windows.ACCESS_MASK(ace.Mask), sid)
+		default:
+			return nil, fmt.Errorf("ACE %d: unsupported ACE type %s: expected Allow or Deny", i, ace.Type)
+		}
+		entry.Inheritance = uint32(ace.Flags & descriptor.InheritanceFlags)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
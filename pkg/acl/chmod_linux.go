@@ -0,0 +1,21 @@
+//go:build linux
+
+package acl
+
+import (
+	"os"
+
+	"github.com/rancher/permissions/pkg/posixacl"
+)
+
+// Chmod changes the file's access ACL to match the provided unix permissions
+//
+// On Linux, the access ACL is replaced with the permission bits, which removes its named user and group entries as
+// Chmod removes the other ACEs on Windows. The default ACL of a directory is left unchanged, as chmod does.
+func Chmod(path string, fileMode os.FileMode) error {
+	_, defaults, err := GetPOSIX(path)
+	if err != nil {
+		return err
+	}
+	return SetPOSIX(path, posixacl.FromMode(fileMode), defaults)
+}
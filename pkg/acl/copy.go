@@ -0,0 +1,24 @@
+//go:build windows || linux
+
+package acl
+
+import (
+	"fmt"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// Copy makes the security descriptor of dst look like the one of src.
+//
+// On Linux, the owner, group, permission bits and POSIX ACLs (system.posix_acl_access and system.posix_acl_default)
+// are copied. A POSIX ACL is not inherited once it has been set on a file / directory, so ConvertInherited has no
+// effect, and neither SACLs nor mandatory labels exist, so they are not copied.
+func Copy(src, dst string, opts CopyOptions) error {
+	if src == "" || dst == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	if opts.Information == 0 {
+		opts.Information = descriptor.DefaultInformation
+	}
+	return copySecurity(src, dst, opts)
+}
@@ -0,0 +1,62 @@
+//go:build linux
+
+package acl
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+func copySecurity(src, dst string, opts CopyOptions) error {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	dstInfo, err := os.Stat(dst)
+	if err != nil {
+		return err
+	}
+
+	if opts.Information&(descriptor.OwnerInformation|descriptor.GroupInformation) != 0 {
+		stat := srcInfo.Sys().(*syscall.Stat_t)
+		uid, gid := -1, -1
+		if opts.Information&descriptor.OwnerInformation != 0 {
+			uid = int(stat.Uid)
+		}
+		if opts.Information&descriptor.GroupInformation != 0 {
+			gid = int(stat.Gid)
+		}
+		if err := os.Chown(dst, uid, gid); err != nil {
+			return err
+		}
+	}
+
+	if opts.Information&descriptor.DACLInformation == 0 {
+		return nil
+	}
+	// the permission bits are set first, since setting an access ACL also updates them
+	if err := os.Chmod(dst, dstInfo.Mode()&^os.ModePerm|srcInfo.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := copyxattr(src, dst, accessACLXattr); err != nil {
+		return err
+	}
+	if !dstInfo.IsDir() {
+		return nil
+	}
+	return copyxattr(src, dst, defaultACLXattr)
+}
+
+// copyxattr copies the extended attribute from src to dst, removing it from dst if src does not have it
+func copyxattr(src, dst, name string) error {
+	value, err := getxattr(src, name)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		return removexattr(dst, name)
+	}
+	return setxattr(dst, name, value)
+}
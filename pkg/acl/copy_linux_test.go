@@ -0,0 +1,58 @@
+//go:build linux
+
+package acl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+func TestCopy(t *testing.T) {
+	dir := t.TempDir()
+	skipWithoutACLSupport(t, dir)
+
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	assert.NoError(t, os.Mkdir(src, 0750))
+	assert.NoError(t, os.Mkdir(dst, 0700))
+	access := rawACL(
+		[3]uint32{0x01, 7, ^uint32(0)},
+		[3]uint32{0x02, 5, 1000},
+		[3]uint32{0x04, 5, ^uint32(0)},
+		[3]uint32{0x10, 5, ^uint32(0)},
+		[3]uint32{0x20, 0, ^uint32(0)},
+	)
+	assert.NoError(t, unix.Setxattr(src, accessACLXattr, access, 0))
+	assert.NoError(t, unix.Setxattr(src, defaultACLXattr, access, 0))
+	assert.NoError(t, unix.Setxattr(dst, defaultACLXattr, rawACL([3]uint32{0x01, 7, ^uint32(0)}, [3]uint32{0x04, 0, ^uint32(0)}, [3]uint32{0x20, 0, ^uint32(0)}), 0))
+
+	assert.NoError(t, Copy(src, dst, CopyOptions{}))
+
+	for _, name := range []string{accessACLXattr, defaultACLXattr} {
+		value, err := getxattr(dst, name)
+		assert.NoError(t, err)
+		assert.Equal(t, access, value, name)
+	}
+	srcInfo, err := os.Stat(src)
+	assert.NoError(t, err)
+	dstInfo, err := os.Stat(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, srcInfo.Mode(), dstInfo.Mode())
+
+	// copying from a path without an extended ACL removes the extended ACL of the destination
+	plain := filepath.Join(dir, "plain")
+	assert.NoError(t, os.Mkdir(plain, 0755))
+	assert.NoError(t, Copy(plain, dst, CopyOptions{}))
+	for _, name := range []string{accessACLXattr, defaultACLXattr} {
+		value, err := getxattr(dst, name)
+		assert.NoError(t, err)
+		assert.Nil(t, value, name)
+	}
+	dstInfo, err = os.Stat(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), dstInfo.Mode().Perm())
+}
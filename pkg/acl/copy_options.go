@@ -0,0 +1,18 @@
+package acl
+
+import (
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// CopyOptions controls what Copy copies from the source to the destination
+type CopyOptions struct {
+	// Information selects the parts of the security descriptor that are copied. Defaults to
+	// descriptor.DefaultInformation (owner, group and DACL).
+	Information descriptor.Information
+
+	// ConvertInherited copies the ACEs that the source inherited from its parent as explicit ACEs and protects the DACL
+	// of the destination, so that it ends up with exactly the same permissions as the source. Otherwise, only the
+	// explicit ACEs are copied and, unless the DACL of the source is protected, the destination inherits ACEs from its
+	// own parent.
+	ConvertInherited bool
+}
@@ -0,0 +1,21 @@
+//go:build windows
+
+package acl
+
+import (
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+func copySecurity(src, dst string, opts CopyOptions) error {
+	sd, err := GetCustom(src, opts.Information)
+	if err != nil {
+		return err
+	}
+	if opts.ConvertInherited && opts.Information&descriptor.DACLInformation != 0 {
+		for i := range sd.DACL {
+			sd.DACL[i].Flags &^= descriptor.Inherited
+		}
+		sd.Control |= descriptor.DACLProtected
+	}
+	return Set(dst, sd, opts.Information)
+}
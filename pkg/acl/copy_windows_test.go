@@ -0,0 +1,77 @@
+//go:build windows
+
+package acl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/sid"
+	"golang.org/x/sys/windows"
+)
+
+func TestCopy(t *testing.T) {
+	dir, err := os.MkdirTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	for _, f := range []string{src, dst} {
+		if err := os.WriteFile(f, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = ApplyCustom(src, nil, nil, Options{Mode: Replace, Inheritance: Unprotected},
+		access.GrantSid(windows.GENERIC_ALL, sid.LocalSystem()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name    string
+		Options CopyOptions
+
+		ExpectProtected bool
+	}{
+		{
+			Name: "Copy explicit ACEs",
+		},
+		{
+			Name:            "Copy inherited ACEs as explicit ACEs",
+			Options:         CopyOptions{ConvertInherited: true},
+			ExpectProtected: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if err := Copy(src, dst, tc.Options); err != nil {
+				t.Fatal(err)
+			}
+			srcSD, err := Get(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dstSD, err := Get(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if dstSD.IsDACLProtected() != tc.ExpectProtected {
+				t.Errorf("expected protected DACL: %t", tc.ExpectProtected)
+			}
+			if len(dstSD.DACL) != len(srcSD.DACL) {
+				t.Fatalf("expected DACL %v, found %v", srcSD.DACL, dstSD.DACL)
+			}
+			for i := range srcSD.DACL {
+				if srcSD.DACL[i].SID != dstSD.DACL[i].SID || srcSD.DACL[i].Mask != dstSD.DACL[i].Mask {
+					t.Errorf("expected DACL %v, found %v", srcSD.DACL, dstSD.DACL)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package acl
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// SecurityDescriptorDiff describes the differences between two security descriptors, as returned by Diff
+type SecurityDescriptorDiff struct {
+	Owner *SIDChange `json:"owner,omitempty"`
+	Group *SIDChange `json:"group,omitempty"`
+
+	DACLProtected *ProtectionChange `json:"daclProtected,omitempty"`
+	SACLProtected *ProtectionChange `json:"saclProtected,omitempty"`
+
+	DACL []ACEChange `json:"dacl,omitempty"`
+	SACL []ACEChange `json:"sacl,omitempty"`
+}
+
+// SIDChange describes a change of owner or group
+type SIDChange struct {
+	From descriptor.SID `json:"from"`
+	To   descriptor.SID `json:"to"`
+}
+
+// ProtectionChange describes a change of the protection of an ACL against inheritance
+type ProtectionChange struct {
+	From bool `json:"from"`
+	To   bool `json:"to"`
+}
+
+// ACEChange describes the rights that were added and removed for a trustee, for ACEs of the same type that apply to
+// the same objects.
+//
+// A change of inheritance flags shows up as rights removed from the ACEs with the old flags and added to the ACEs
+// with the new flags.
+type ACEChange struct {
+	SID  descriptor.SID     `json:"sid"`
+	Type descriptor.ACEType `json:"type"`
+	// Inherited is true if the rights are inherited from the parent object
+	Inherited bool `json:"inherited"`
+	// Inheritance holds the flags describing the children that inherit the rights, or none if the rights apply to the
+	// object itself
+	Inheritance descriptor.ACEFlags `json:"inheritance,omitempty"`
+	// Audit holds the audit flags for audit ACEs
+	Audit descriptor.ACEFlags `json:"audit,omitempty"`
+
+	Added   uint32 `json:"added,omitempty"`
+	Removed uint32 `json:"removed,omitempty"`
+}
+
+// Diff compares the security descriptors a and b and returns what changed from a to b.
+//
+// ACLs are compared by the rights they grant, deny or audit for each trustee rather than ACE by ACE: access masks are
+// normalized with access.FileGenericMapping, and the rights of an ACE that applies both to the object and to its
+// children are compared separately for each. This means that an ACE that Windows has split into an ACE with expanded
+// rights for the object and an inherit-only ACE with generic rights is equal to the original ACE.
+//
+// Parts of the security descriptor that are missing in one of them (e.g. an empty owner or a SACL that was not read)
+// are not compared.
+func Diff(a, b *descriptor.SecurityDescriptor) *SecurityDescriptorDiff {
+	diff := &SecurityDescriptorDiff{}
+	if a.Owner != "" && b.Owner != "" && a.Owner != b.Owner {
+		diff.Owner = &SIDChange{From: a.Owner, To: b.Owner}
+	}
+	if a.Group != "" && b.Group != "" && a.Group != b.Group {
+		diff.Group = &SIDChange{From: a.Group, To: b.Group}
+	}
+	if a.Control&b.Control&descriptor.DACLPresent != 0 {
+		if a.IsDACLProtected() != b.IsDACLProtected() {
+			diff.DACLProtected = &ProtectionChange{From: a.IsDACLProtected(), To: b.IsDACLProtected()}
+		}
+		diff.DACL = diffACL(a.DACL, b.DACL)
+	}
+	if a.Control&b.Control&descriptor.SACLPresent != 0 {
+		aProtected, bProtected := a.Control&descriptor.SACLProtected != 0, b.Control&descriptor.SACLProtected != 0
+		if aProtected != bProtected {
+			diff.SACLProtected = &ProtectionChange{From: aProtected, To: bProtected}
+		}
+		diff.SACL = diffACL(a.SACL, b.SACL)
+	}
+	return diff
+}
+
+// IsEmpty returns true if both security descriptors are equivalent
+func (d *SecurityDescriptorDiff) IsEmpty() bool {
+	return d.Owner == nil && d.Group == nil && d.DACLProtected == nil && d.SACLProtected == nil &&
+		len(d.DACL) == 0 && len(d.SACL) == 0
+}
+
+// String renders the differences as text, with one line per change
+func (d *SecurityDescriptorDiff) String() string {
+	var sb strings.Builder
+	if d.Owner != nil {
+		fmt.Fprintf(&sb, "owner: %s -> %s\n", d.Owner.From, d.Owner.To)
+	}
+	if d.Group != nil {
+		fmt.Fprintf(&sb, "group: %s -> %s\n", d.Group.From, d.Group.To)
+	}
+	if d.DACLProtected != nil {
+		fmt.Fprintf(&sb, "DACL protected: %t -> %t\n", d.DACLProtected.From, d.DACLProtected.To)
+	}
+	if d.SACLProtected != nil {
+		fmt.Fprintf(&sb, "SACL protected: %t -> %t\n", d.SACLProtected.From, d.SACLProtected.To)
+	}
+	for _, acl := range []struct {
+		name    string
+		changes []ACEChange
+	}{
+		{"DACL", d.DACL},
+		{"SACL", d.SACL},
+	} {
+		for _, change := range acl.changes {
+			if change.Added != 0 {
+				fmt.Fprintf(&sb, "%s: + %s\n", acl.name, change.describe(change.Added))
+			}
+			if change.Removed != 0 {
+				fmt.Fprintf(&sb, "%s: - %s\n", acl.name, change.describe(change.Removed))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// JSON renders the differences as JSON
+func (d *SecurityDescriptorDiff) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+func (c ACEChange) describe(mask uint32) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s", c.Type, c.SID)
+	if c.Audit != 0 {
+		fmt.Fprintf(&sb, " (%s)", c.Audit)
+	}
+	if c.Inheritance != 0 {
+		fmt.Fprintf(&sb, " [inherited by %s]", c.Inheritance)
+	} else {
+		sb.WriteString(" [this object]")
+	}
+	if c.Inherited {
+		sb.WriteString(" (inherited)")
+	}
+	if c.Type == descriptor.SystemMandatoryLabel {
+		fmt.Fprintf(&sb, ": %s", descriptor.LabelPolicy(mask))
+	} else {
+		fmt.Fprintf(&sb, ": %s", access.FileRightsString(mask))
+	}
+	return sb.String()
+}
+
+// aceScope identifies the rights of a trustee that are compared together
+type aceScope struct {
+	sid         descriptor.SID
+	aceType     descriptor.ACEType
+	inherited   bool
+	inheritance descriptor.ACEFlags
+	audit       descriptor.ACEFlags
+}
+
+// scopedRights returns the rights of the ACL for each trustee, ACE type and set of objects they apply to
+func scopedRights(acl descriptor.ACL) map[aceScope]uint32 {
+	rights := make(map[aceScope]uint32)
+	for _, ace := range acl {
+		mask := ace.Mask
+		if ace.Type != descriptor.SystemMandatoryLabel {
+			mask = access.FileGenericMapping.Normalize(mask)
+		}
+		scope := aceScope{
+			sid:       ace.SID,
+			aceType:   ace.Type,
+			inherited: ace.IsInherited(),
+			audit:     ace.Flags & descriptor.AuditFlags,
+		}
+		if ace.AppliesToObject() {
+			rights[scope] |= mask
+		}
+		if ace.IsInheritable() {
+			scope.inheritance = ace.Flags & (descriptor.InheritanceFlags &^ descriptor.InheritOnly)
+			rights[scope] |= mask
+		}
+	}
+	return rights
+}
+
+func diffACL(a, b descriptor.ACL) []ACEChange {
+	aRights, bRights := scopedRights(a), scopedRights(b)
+	scopes := make(map[aceScope]bool)
+	for scope := range aRights {
+		scopes[scope] = true
+	}
+	for scope := range bRights {
+		scopes[scope] = true
+	}
+
+	var changes []ACEChange
+	for scope := range scopes {
+		added := bRights[scope] &^ aRights[scope]
+		removed := aRights[scope] &^ bRights[scope]
+		if added == 0 && removed == 0 {
+			continue
+		}
+		changes = append(changes, ACEChange{
+			SID:         scope.sid,
+			Type:        scope.aceType,
+			Inherited:   scope.inherited,
+			Inheritance: scope.inheritance,
+			Audit:       scope.audit,
+			Added:       added,
+			Removed:     removed,
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		ci, cj := changes[i], changes[j]
+		if ci.SID != cj.SID {
+			return ci.SID < cj.SID
+		}
+		if ci.Type != cj.Type {
+			return ci.Type < cj.Type
+		}
+		if ci.Inherited != cj.Inherited {
+			return !ci.Inherited
+		}
+		if ci.Inheritance != cj.Inheritance {
+			return ci.Inheritance < cj.Inheritance
+		}
+		return ci.Audit < cj.Audit
+	})
+	return changes
+}
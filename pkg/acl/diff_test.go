@@ -0,0 +1,154 @@
+package acl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	users := descriptor.BuiltinUsers
+	admins := descriptor.BuiltinAdministrators
+	inherit := descriptor.ObjectInherit | descriptor.ContainerInherit
+
+	base := descriptor.SecurityDescriptor{
+		Owner:   admins,
+		Group:   descriptor.LocalSystem,
+		Control: descriptor.DACLPresent,
+		DACL: descriptor.ACL{
+			{Type: descriptor.AccessAllowed, Flags: inherit, Mask: access.GenericAll, SID: admins},
+			{Type: descriptor.AccessAllowed, Flags: inherit, Mask: access.ReadAndExecute | access.Synchronize, SID: users},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		b        func(sd *descriptor.SecurityDescriptor)
+		expected SecurityDescriptorDiff
+	}{
+		{
+			name: "Identical",
+			b:    func(sd *descriptor.SecurityDescriptor) {},
+		},
+		{
+			name: "Split generic ACE is equivalent",
+			b: func(sd *descriptor.SecurityDescriptor) {
+				sd.DACL = descriptor.ACL{
+					{Type: descriptor.AccessAllowed, Mask: access.FullControl, SID: admins},
+					{Type: descriptor.AccessAllowed, Flags: inherit | descriptor.InheritOnly, Mask: access.GenericAll, SID: admins},
+					sd.DACL[1],
+				}
+			},
+		},
+		{
+			name: "Owner and group",
+			b: func(sd *descriptor.SecurityDescriptor) {
+				sd.Owner = users
+				sd.Group = admins
+			},
+			expected: SecurityDescriptorDiff{
+				Owner: &SIDChange{From: admins, To: users},
+				Group: &SIDChange{From: descriptor.LocalSystem, To: admins},
+			},
+		},
+		{
+			name: "Rights added",
+			b: func(sd *descriptor.SecurityDescriptor) {
+				sd.DACL[1].Mask |= access.Write
+			},
+			expected: SecurityDescriptorDiff{
+				DACL: []ACEChange{
+					{SID: users, Type: descriptor.AccessAllowed, Added: access.Write},
+					{SID: users, Type: descriptor.AccessAllowed, Inheritance: inherit, Added: access.Write},
+				},
+			},
+		},
+		{
+			name: "Deny added",
+			b: func(sd *descriptor.SecurityDescriptor) {
+				sd.DACL = append(descriptor.ACL{{Type: descriptor.AccessDenied, Mask: access.Delete, SID: users}}, sd.DACL...)
+			},
+			expected: SecurityDescriptorDiff{
+				DACL: []ACEChange{
+					{SID: users, Type: descriptor.AccessDenied, Added: access.Delete},
+				},
+			},
+		},
+		{
+			name: "Inheritance flags changed",
+			b: func(sd *descriptor.SecurityDescriptor) {
+				sd.DACL[1].Flags = descriptor.ContainerInherit
+			},
+			expected: SecurityDescriptorDiff{
+				DACL: []ACEChange{
+					{SID: users, Type: descriptor.AccessAllowed, Inheritance: descriptor.ContainerInherit, Added: access.ReadAndExecute | access.Synchronize},
+					{SID: users, Type: descriptor.AccessAllowed, Inheritance: descriptor.ObjectInherit | descriptor.ContainerInherit, Removed: access.ReadAndExecute | access.Synchronize},
+				},
+			},
+		},
+		{
+			name: "Protected and trustee removed",
+			b: func(sd *descriptor.SecurityDescriptor) {
+				sd.Control |= descriptor.DACLProtected
+				sd.DACL = sd.DACL[:1]
+			},
+			expected: SecurityDescriptorDiff{
+				DACLProtected: &ProtectionChange{From: false, To: true},
+				DACL: []ACEChange{
+					{SID: users, Type: descriptor.AccessAllowed, Removed: access.ReadAndExecute | access.Synchronize},
+					{SID: users, Type: descriptor.AccessAllowed, Inheritance: inherit, Removed: access.ReadAndExecute | access.Synchronize},
+				},
+			},
+		},
+		{
+			name: "SACL not read",
+			b: func(sd *descriptor.SecurityDescriptor) {
+				sd.Control |= descriptor.SACLPresent
+				sd.SACL = descriptor.ACL{{Type: descriptor.SystemAudit, Flags: descriptor.FailedAccess, Mask: access.Write, SID: descriptor.Everyone}}
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := base
+			b.DACL = append(descriptor.ACL(nil), base.DACL...)
+			tc.b(&b)
+			diff := Diff(&base, &b)
+			assert.Equal(t, tc.expected, *diff)
+			assert.Equal(t, len(tc.expected.DACL) == 0 && tc.expected.Owner == nil && tc.expected.DACLProtected == nil, diff.IsEmpty())
+		})
+	}
+}
+
+func TestDiffOutput(t *testing.T) {
+	a := &descriptor.SecurityDescriptor{
+		Owner:   descriptor.BuiltinAdministrators,
+		Control: descriptor.DACLPresent,
+		DACL: descriptor.ACL{
+			{Type: descriptor.AccessAllowed, Mask: access.Modify | access.Synchronize, SID: descriptor.BuiltinUsers},
+		},
+	}
+	b := &descriptor.SecurityDescriptor{
+		Owner:   descriptor.LocalSystem,
+		Control: descriptor.DACLPresent | descriptor.DACLProtected,
+		DACL: descriptor.ACL{
+			{Type: descriptor.AccessAllowed, Mask: access.ReadAndExecute | access.Synchronize, SID: descriptor.BuiltinUsers},
+		},
+	}
+	diff := Diff(a, b)
+
+	assert.Equal(t, "owner: S-1-5-32-544 -> S-1-5-18\n"+
+		"DACL protected: false -> true\n"+
+		"DACL: - Allow S-1-5-32-545 [this object]: Write, Delete\n", diff.String())
+
+	out, err := diff.JSON()
+	require.NoError(t, err)
+	var decoded SecurityDescriptorDiff
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	assert.Equal(t, *diff, decoded)
+	assert.Contains(t, string(out), `"type": "Allow"`)
+}
@@ -0,0 +1,105 @@
+//go:build windows
+
+package acl
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"golang.org/x/sys/windows"
+)
+
+// Validate checks the EXPLICIT_ACCESS rules for entries in non-canonical order, duplicate trustees, zero masks and
+// conflicting inheritance flags. It returns a *descriptor.ValidationError listing every issue found, or nil if the
+// rules are valid.
+func Validate(access ...windows.EXPLICIT_ACCESS) error {
+	acl, err := toACL(access)
+	if err != nil {
+		return err
+	}
+	return acl.Validate()
+}
+
+// toACL converts EXPLICIT_ACCESS rules into the ACEs they describe, resolving trustee names into SIDs.
+// REVOKE_ACCESS rules do not describe an ACE, so they are skipped.
+func toACL(access []windows.EXPLICIT_ACCESS) (descriptor.ACL, error) {
+	var acl descriptor.ACL
+	for _, ea := range access {
+		ace, ok, err := toACE(ea)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			acl = append(acl, ace)
+		}
+	}
+	return acl, nil
+}
+
+// toACE converts an EXPLICIT_ACCESS rule into the ACE it describes. The returned bool is false if the rule does not
+// describe an ACE (REVOKE_ACCESS), in which case only the SID of the returned ACE is set.
+func toACE(ea windows.EXPLICIT_ACCESS) (descriptor.ACE, bool, error) {
+	sid, err := trusteeSID(ea.Trustee)
+	if err != nil {
+		return descriptor.ACE{}, false, err
+	}
+	ace := descriptor.ACE{
+		Flags: descriptor.ACEFlags(ea.Inheritance & windows.VALID_INHERIT_FLAGS),
+		Mask:  uint32(ea.AccessPermissions),
+		SID:   descriptor.SID(sid.String()),
+	}
+	switch ea.AccessMode {
+	case windows.GRANT_ACCESS, windows.SET_ACCESS:
+		ace.Type = descriptor.AccessAllowed
+	case windows.DENY_ACCESS:
+		ace.Type = descriptor.AccessDenied
+	case windows.SET_AUDIT_SUCCESS:
+		ace.Type = descriptor.SystemAudit
+		ace.Flags |= descriptor.SuccessfulAccess
+	case windows.SET_AUDIT_FAILURE:
+		ace.Type = descriptor.SystemAudit
+		ace.Flags |= descriptor.FailedAccess
+	case windows.REVOKE_ACCESS:
+		return descriptor.ACE{SID: ace.SID}, false, nil
+	default:
+		return descriptor.ACE{}, false, fmt.Errorf("unsupported access mode %d", ea.AccessMode)
+	}
+	return ace, true, nil
+}
+
+// trusteeSID returns the SID identified by the trustee
+func trusteeSID(trustee windows.TRUSTEE) (*windows.SID, error) {
+	switch trustee.TrusteeForm {
+	case windows.TRUSTEE_IS_SID:
+		return *(**windows.SID)(unsafe.Pointer(&trustee.TrusteeValue)), nil
+	case windows.TRUSTEE_IS_NAME:
+		name := windows.UTF16PtrToString(*(**uint16)(unsafe.Pointer(&trustee.TrusteeValue)))
+		sid, _, _, err := windows.LookupSID("", name)
+		if err != nil {
+			return nil, fmt.Errorf("unable to look up trustee %s: %w", name, err)
+		}
+		return sid, nil
+	default:
+		return nil, fmt.Errorf("unsupported trustee form %d", trustee.TrusteeForm)
+	}
+}
+
+// fromWindowsACL decodes a Windows ACL into its ACEs
+func fromWindowsACL(acl *windows.ACL) (descriptor.ACL, error) {
+	if acl == nil {
+		return nil, nil
+	}
+	// the ACL header is laid out as AclRevision (byte), Sbz1 (byte), AclSize (uint16), ...
+	size := *(*uint16)(unsafe.Add(unsafe.Pointer(acl), 2))
+	return descriptor.ParseACL(unsafe.Slice((*byte)(unsafe.Pointer(acl)), size))
+}
+
+// toWindowsACL encodes ACEs into a Windows ACL
+func toWindowsACL(acl descriptor.ACL) (*windows.ACL, error) {
+	b, err := acl.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return (*windows.ACL)(unsafe.Pointer(&b[0])), nil
+}
@@ -0,0 +1,77 @@
+//go:build windows
+
+package acl
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/sid"
+	"golang.org/x/sys/windows"
+)
+
+func TestValidate(t *testing.T) {
+	if err := Validate(
+		access.DenySid(windows.GENERIC_WRITE, sid.Everyone()),
+		access.GrantSid(windows.GENERIC_ALL, sid.LocalSystem()),
+		access.GrantName(windows.GENERIC_ALL, "BUILTIN\\Administrators"),
+	); err != nil {
+		t.Error(err)
+	}
+
+	err := Validate(
+		access.GrantSid(windows.GENERIC_ALL, sid.LocalSystem()),
+		access.DenySid(windows.GENERIC_WRITE, sid.Everyone()),
+		access.GrantSid(0, sid.BuiltinAdministrators()),
+		access.GrantSid(windows.GENERIC_READ, sid.LocalSystem()),
+	)
+	var validationErr *descriptor.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected validation error, found %v", err)
+	}
+	expected := []descriptor.IssueKind{descriptor.NonCanonicalOrder, descriptor.ZeroMask, descriptor.DuplicateTrustee}
+	if len(validationErr.Issues) != len(expected) {
+		t.Fatalf("expected issues %v, found %v", expected, validationErr.Issues)
+	}
+	for i, issue := range validationErr.Issues {
+		if issue.Kind != expected[i] {
+			t.Errorf("expected issue %s, found %s", expected[i], issue)
+		}
+	}
+}
+
+func TestApplyCanonicalize(t *testing.T) {
+	tempFile, err := os.CreateTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := tempFile.Name()
+	tempFile.Close()
+	defer os.Remove(f)
+
+	err = ApplyCustom(f, nil, nil, Options{Canonicalize: true},
+		access.GrantSid(windows.GENERIC_ALL, sid.LocalSystem()),
+		access.DenySid(windows.GENERIC_WRITE, sid.Everyone()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd, err := windows.GetNamedSecurityInfo(f, windows.SE_FILE_OBJECT, windows.DACL_SECURITY_INFORMATION)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dacl, _, err := sd.DACL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	aces, err := fromWindowsACL(dacl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !aces.IsCanonical() {
+		t.Errorf("expected canonical DACL, found %v", aces)
+	}
+}
@@ -0,0 +1,105 @@
+//go:build windows || linux
+
+package acl
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// Files changes the permissions of files and directories through a Backend, with the same API on Windows and Linux.
+// The zero value uses System.
+type Files struct {
+	// Backend reads and writes the security descriptors. Defaults to System.
+	Backend Backend
+}
+
+func (f Files) backend() Backend {
+	if f.Backend == nil {
+		return System
+	}
+	return f.Backend
+}
+
+// Get returns the parts of the security descriptor of the file / directory selected by info
+func (f Files) Get(path string, info descriptor.Information) (*descriptor.SecurityDescriptor, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	return f.backend().Get(path, info)
+}
+
+// Set writes the parts of the security descriptor selected by info to the file / directory, as Set does
+func (f Files) Set(path string, sd *descriptor.SecurityDescriptor, info descriptor.Information) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	return f.backend().Set(path, sd, info)
+}
+
+// Apply sets the owner and group of the file / directory, unless they are empty. If ACEs are provided, they replace
+// its DACL in canonical order, and the DACL is protected so that it does not inherit ACEs from the parent any more.
+//
+// On Linux, the ACEs are written as POSIX ACLs (see Set): the ACEs that apply to the path make up its access ACL, and
+// the inheritable ACEs of a directory make up its default ACL.
+func (f Files) Apply(path string, owner, group descriptor.SID, aces ...descriptor.ACE) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	sd := &descriptor.SecurityDescriptor{Owner: owner, Group: group}
+	var info descriptor.Information
+	if owner != "" {
+		info |= descriptor.OwnerInformation
+	}
+	if group != "" {
+		info |= descriptor.GroupInformation
+	}
+	if len(aces) > 0 {
+		info |= descriptor.DACLInformation
+		sd.Control = descriptor.DACLPresent | descriptor.DACLProtected
+		sd.DACL = descriptor.ACL(aces).Canonicalize()
+	}
+	if info == 0 {
+		return nil
+	}
+	return f.backend().Set(path, sd, info)
+}
+
+// Mkdir creates a directory that inherits the ACEs of its parent, unless it exists already. If ACEs are provided,
+// they replace its DACL, which is protected, and its inheritable ACEs are inherited by the files and directories
+// created in it on both OSes: they make up its default ACL on Linux (see Set).
+//
+// This is the Mkdir to use in code that builds on Windows and Linux: the Mkdir function only exists on Windows, where it
+// takes EXPLICIT_ACCESS entries.
+func (f Files) Mkdir(path string, aces ...descriptor.ACE) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	return f.backend().Mkdir(path, aces...)
+}
+
+// Chmod changes the file's DACL to match the provided unix permissions, as Chmod does
+func (f Files) Chmod(path string, fileMode os.FileMode) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	return f.backend().Chmod(path, fileMode)
+}
+
+// Copy makes the security descriptor of dst look like the one of src, as Copy does
+func (f Files) Copy(src, dst string, opts CopyOptions) error {
+	if src == "" || dst == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	return f.backend().Copy(src, dst, opts)
+}
+
+// Reset removes all explicit permissions of the file / directory and re-enables inheritance, as Reset does
+func (f Files) Reset(path string) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	return f.backend().Reset(path)
+}
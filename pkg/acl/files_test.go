@@ -0,0 +1,71 @@
+//go:build windows || linux
+
+package acl_test
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/acl"
+	"github.com/rancher/permissions/pkg/acl/acltest"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilesApply(t *testing.T) {
+	b := acltest.New()
+	root, err := descriptor.ParseSDDL("O:SYG:SYD:PAI(A;OICI;FA;;;SY)")
+	require.NoError(t, err)
+	b.Add("root", true, root)
+	require.NoError(t, b.Create("root/file"))
+	files := acl.Files{Backend: b}
+
+	bob := descriptor.MustParseSID("S-1-5-21-1-2-3-1001")
+	require.NoError(t, files.Apply("root/file", bob, "",
+		descriptor.ACE{Type: descriptor.AccessAllowed, Mask: access.Read, SID: bob},
+		descriptor.ACE{Type: descriptor.AccessDenied, Mask: access.Delete, SID: descriptor.Everyone},
+	))
+	sd, err := b.Get("root/file", descriptor.DefaultInformation)
+	require.NoError(t, err)
+	assert.Equal(t, "O:S-1-5-21-1-2-3-1001G:SYD:P(D;;SD;;;WD)(A;;RCCCSWLO;;;S-1-5-21-1-2-3-1001)", sd.SDDL(),
+		"the DACL should be canonical and protected")
+
+	require.NoError(t, files.Apply("root/file", "", descriptor.BuiltinUsers))
+	sd, err = b.Get("root/file", descriptor.DefaultInformation)
+	require.NoError(t, err)
+	assert.Equal(t, "O:S-1-5-21-1-2-3-1001G:BUD:P(D;;SD;;;WD)(A;;RCCCSWLO;;;S-1-5-21-1-2-3-1001)", sd.SDDL(),
+		"the DACL should be kept without ACEs")
+
+	assert.Error(t, files.Apply("", bob, ""))
+	assert.Error(t, files.Apply("root/missing", bob, ""))
+}
+
+func TestFilesChmodCopyAndReset(t *testing.T) {
+	b := acltest.New()
+	root, err := descriptor.ParseSDDL("O:SYG:SYD:PAI(A;OICI;FA;;;SY)")
+	require.NoError(t, err)
+	b.Add("root", true, root)
+	require.NoError(t, b.Create("root/src"))
+	require.NoError(t, b.Create("root/dst"))
+	files := acl.Files{Backend: b}
+
+	require.NoError(t, files.Chmod("root/src", 0o750))
+	sd, err := b.Get("root/src", descriptor.DACLInformation)
+	require.NoError(t, err)
+	assert.Equal(t, "D:P(A;OICI;0x1301bf;;;BA)(A;OICI;0x1200a9;;;SY)", sd.SDDL())
+
+	require.NoError(t, files.Copy("root/src", "root/dst", acl.CopyOptions{}))
+	copied, err := b.Get("root/dst", descriptor.DACLInformation)
+	require.NoError(t, err)
+	assert.Equal(t, sd, copied)
+
+	require.NoError(t, files.Reset("root/dst"))
+	sd, err = b.Get("root/dst", descriptor.DACLInformation)
+	require.NoError(t, err)
+	assert.Equal(t, "D:(A;ID;FA;;;SY)", sd.SDDL())
+
+	b.Fail(acltest.OpSet, "root/dst", acltest.ErrAccessDenied)
+	assert.ErrorIs(t, files.Reset("root/dst"), fs.ErrPermission)
+}
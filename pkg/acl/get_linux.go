@@ -0,0 +1,41 @@
+//go:build linux
+
+package acl
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// Get returns the owner, group and DACL of the file / directory.
+//
+// On Linux, the owner and group are returned as the S-1-22-1-<uid> and S-1-22-2-<gid> SIDs that Samba uses for Unix
+// accounts, and the DACL is derived from the POSIX access ACL with posixacl.ACL.DACL, which is the same as
+// filemode.ToDACL for the permission bits when no extended ACL is set. The default ACL of a directory is returned as
+// the inherit-only ACEs of posixacl.ACL.InheritableACEs, after the ACEs of the access ACL. POSIX ACLs are not inherited
+// once they have been set, so the DACL is always protected.
+func Get(path string) (*descriptor.SecurityDescriptor, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	access, defaults, err := GetPOSIX(path)
+	if err != nil {
+		return nil, err
+	}
+	stat := info.Sys().(*syscall.Stat_t)
+	owner := descriptor.UnixUserSID(stat.Uid)
+	group := descriptor.UnixGroupSID(stat.Gid)
+	return &descriptor.SecurityDescriptor{
+		Owner:   owner,
+		Group:   group,
+		Control: descriptor.DACLPresent | descriptor.DACLProtected,
+		DACL:    append(access.DACL(owner, group), defaults.InheritableACEs()...),
+	}, nil
+}
@@ -0,0 +1,32 @@
+//go:build linux
+
+package acl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/filemode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(f, nil, 0600))
+	require.NoError(t, os.Chmod(f, 0754))
+
+	sd, err := Get(f)
+	require.NoError(t, err)
+	owner := descriptor.UnixUserSID(uint32(os.Getuid()))
+	group := descriptor.UnixGroupSID(uint32(os.Getgid()))
+	assert.Equal(t, owner, sd.Owner)
+	assert.Equal(t, group, sd.Group)
+	assert.True(t, sd.IsDACLProtected())
+	assert.Equal(t, filemode.ToDACL(0754, owner, group), sd.DACL)
+
+	_, err = Get(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,88 @@
+//go:build windows
+
+package acl
+
+import (
+	"fmt"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"golang.org/x/sys/windows"
+)
+
+// Get returns the owner, group and DACL of the file / directory
+func Get(path string) (*descriptor.SecurityDescriptor, error) {
+	return GetCustom(path, descriptor.DefaultInformation)
+}
+
+// GetCustom returns the parts of the security descriptor of the file / directory selected by info.
+//
+// Reading the SACL requires the SeSecurityPrivilege, which is enabled for the duration of the call. If the caller does
+// not hold it, a *PrivilegeError is returned.
+func GetCustom(path string, info descriptor.Information) (*descriptor.SecurityDescriptor, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	var sd *windows.SECURITY_DESCRIPTOR
+	get := func() (err error) {
+		sd, err = windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.SECURITY_INFORMATION(info))
+		return err
+	}
+	var err error
+	if info&descriptor.SACLInformation != 0 {
+		err = withPrivileges([]string{securityPrivilege}, nil, get)
+	} else {
+		err = get()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fromWindowsSecurityDescriptor(sd, info)
+}
+
+// fromWindowsSecurityDescriptor converts the parts of a Windows security descriptor selected by info
+func fromWindowsSecurityDescriptor(sd *windows.SECURITY_DESCRIPTOR, info descriptor.Information) (*descriptor.SecurityDescriptor, error) {
+	control, _, err := sd.Control()
+	if err != nil {
+		return nil, err
+	}
+	result := &descriptor.SecurityDescriptor{
+		Control: descriptor.Control(control) &^ descriptor.SelfRelative,
+	}
+	if info&descriptor.OwnerInformation != 0 {
+		owner, _, err := sd.Owner()
+		if err != nil {
+			return nil, err
+		}
+		if owner != nil {
+			result.Owner = descriptor.SID(owner.String())
+		}
+	}
+	if info&descriptor.GroupInformation != 0 {
+		group, _, err := sd.Group()
+		if err != nil {
+			return nil, err
+		}
+		if group != nil {
+			result.Group = descriptor.SID(group.String())
+		}
+	}
+	if result.Control&descriptor.DACLPresent != 0 {
+		dacl, _, err := sd.DACL()
+		if err != nil {
+			return nil, err
+		}
+		if result.DACL, err = fromWindowsACL(dacl); err != nil {
+			return nil, err
+		}
+	}
+	if result.Control&descriptor.SACLPresent != 0 {
+		sacl, _, err := sd.SACL()
+		if err != nil {
+			return nil, err
+		}
+		if result.SACL, err = fromWindowsACL(sacl); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
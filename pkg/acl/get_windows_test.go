@@ -0,0 +1,79 @@
+//go:build windows
+
+package acl
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/sid"
+	"golang.org/x/sys/windows"
+)
+
+func TestGet(t *testing.T) {
+	tempFile, err := os.CreateTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := tempFile.Name()
+	tempFile.Close()
+	defer os.Remove(f)
+
+	err = Apply(f, sid.CurrentUser(), nil, access.GrantSid(windows.GENERIC_ALL, sid.LocalSystem()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd, err := Get(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sd.Owner != descriptor.SID(sid.CurrentUser().String()) {
+		t.Errorf("expected owner %s, found %s", sid.CurrentUser(), sd.Owner)
+	}
+	if !sd.IsDACLProtected() {
+		t.Error("expected protected DACL")
+	}
+	if len(sd.DACL) == 0 || sd.DACL[0].SID != descriptor.SID(sid.LocalSystem().String()) {
+		t.Errorf("expected DACL granting access to LocalSystem, found %v", sd.DACL)
+	}
+	if sd.SACL != nil {
+		t.Errorf("expected no SACL to be read, found %v", sd.SACL)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	tempFile, err := os.CreateTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := tempFile.Name()
+	tempFile.Close()
+	defer os.Remove(f)
+
+	err = ApplyCustom(f, nil, nil, Options{
+		Audit: []windows.EXPLICIT_ACCESS{
+			access.AuditFailureSid(windows.GENERIC_WRITE, sid.Everyone()),
+		},
+	})
+	var privilegeErr *PrivilegeError
+	if errors.As(err, &privilegeErr) {
+		t.Skipf("%s is not held by the test process", privilegeErr.Privilege)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd, err := GetCustom(f, descriptor.SACLInformation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sd.SACL) != 1 {
+		t.Fatalf("expected a single audit ACE, found %v", sd.SACL)
+	}
+	ace := sd.SACL[0]
+	if ace.Type != descriptor.SystemAudit || ace.Flags&descriptor.FailedAccess == 0 || ace.SID != descriptor.Everyone {
+		t.Errorf("unexpected audit ACE %s", ace)
+	}
+}
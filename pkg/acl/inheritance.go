@@ -0,0 +1,21 @@
+//go:build windows
+
+package acl
+
+// Inheritance controls whether the DACL of a path inherits ACEs from its parent. The values match the
+// /inheritance:e|d|r switches of icacls.
+type Inheritance string
+
+const (
+	// DefaultInheritance lets the merge mode decide: Replace protects the DACL, while Merge and RemoveTrustee leave
+	// the inheritance of the DACL unchanged
+	DefaultInheritance Inheritance = ""
+	// Protected disables inheritance and removes every inherited ACE (icacls /inheritance:r)
+	Protected Inheritance = "protected"
+	// Unprotected enables inheritance, so the inheritable ACEs of the parent apply in addition to the explicit ACEs
+	// (icacls /inheritance:e)
+	Unprotected Inheritance = "unprotected"
+	// ConvertInherited disables inheritance and converts the ACEs currently inherited from the parent into explicit
+	// ACEs (icacls /inheritance:d)
+	ConvertInherited Inheritance = "convert"
+)
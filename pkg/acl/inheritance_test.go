@@ -0,0 +1,85 @@
+//go:build windows
+
+package acl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/sid"
+	"golang.org/x/sys/windows"
+)
+
+func TestApplyInheritance(t *testing.T) {
+	dir, err := os.MkdirTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	f := filepath.Join(dir, "file")
+	if err := os.WriteFile(f, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name        string
+		Options     Options
+		Permissions []windows.EXPLICIT_ACCESS
+
+		ExpectInherited bool
+		ExpectExplicit  bool
+	}{
+		{
+			Name:    "Protected removes inherited ACEs",
+			Options: Options{Inheritance: Protected},
+			Permissions: []windows.EXPLICIT_ACCESS{
+				access.GrantSid(windows.GENERIC_ALL, sid.LocalSystem()),
+			},
+			ExpectExplicit: true,
+		},
+		{
+			Name:            "Unprotected re-enables inheritance and keeps explicit ACEs",
+			Options:         Options{Inheritance: Unprotected},
+			ExpectInherited: true,
+			ExpectExplicit:  true,
+		},
+		{
+			Name:           "Convert inherited ACEs into explicit ACEs",
+			Options:        Options{Inheritance: ConvertInherited},
+			ExpectExplicit: true,
+		},
+		{
+			Name: "Replace keeping inheritance",
+			Options: Options{
+				Mode:        Replace,
+				Inheritance: Unprotected,
+			},
+			Permissions: []windows.EXPLICIT_ACCESS{
+				access.GrantSid(windows.GENERIC_ALL, sid.LocalSystem()),
+			},
+			ExpectInherited: true,
+			ExpectExplicit:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := ApplyCustom(f, nil, nil, tc.Options, tc.Permissions...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			aces, err := currentDACL(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hasInherited := len(aces.Inherited()) != 0; hasInherited != tc.ExpectInherited {
+				t.Errorf("expected inherited ACEs: %t, found %v", tc.ExpectInherited, aces)
+			}
+			if hasExplicit := len(aces.Explicit()) != 0; hasExplicit != tc.ExpectExplicit {
+				t.Errorf("expected explicit ACEs: %t, found %v", tc.ExpectExplicit, aces)
+			}
+		})
+	}
+}
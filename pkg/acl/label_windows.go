@@ -0,0 +1,56 @@
+//go:build windows
+
+package acl
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"golang.org/x/sys/windows"
+)
+
+// SetIntegrity sets the mandatory integrity level of the file / directory and the policy that applies to subjects
+// with a lower integrity level. For example, a low integrity sandboxed process can be allowed to write to a scratch
+// directory only, by labelling that directory with descriptor.LowIntegrity and descriptor.NoWriteUp.
+//
+// On directories, the label is inherited by files and directories created below it.
+//
+// Raising the integrity level above the one of the caller requires the SeRelabelPrivilege, which is enabled if the
+// caller holds it.
+func SetIntegrity(path string, level descriptor.SID, policy descriptor.LabelPolicy) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	if !level.IsIntegrityLevel() {
+		return fmt.Errorf("%s is not an integrity level", level)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	var flags descriptor.ACEFlags
+	if info.IsDir() {
+		flags = descriptor.ObjectInherit | descriptor.ContainerInherit
+	}
+	sacl, err := toWindowsACL(descriptor.ACL{descriptor.LabelACE(level, policy, flags)})
+	if err != nil {
+		return err
+	}
+	return setNamedSecurityInfo(path, windows.LABEL_SECURITY_INFORMATION, nil, nil, nil, sacl)
+}
+
+// GetIntegrity returns the mandatory integrity level and policy of the file / directory. Objects without a mandatory
+// label are treated by Windows as medium integrity objects with the descriptor.NoWriteUp policy, which is what is
+// returned for them.
+func GetIntegrity(path string) (descriptor.SID, descriptor.LabelPolicy, error) {
+	sd, err := GetCustom(path, descriptor.LabelInformation)
+	if err != nil {
+		return "", 0, err
+	}
+	label, ok := sd.SACL.Label()
+	if !ok {
+		return descriptor.MediumIntegrity, descriptor.NoWriteUp, nil
+	}
+	return label.SID, descriptor.LabelPolicy(label.Mask), nil
+}
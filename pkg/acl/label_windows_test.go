@@ -0,0 +1,41 @@
+//go:build windows
+
+package acl
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+func TestSetIntegrity(t *testing.T) {
+	dir, err := os.MkdirTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	level, policy, err := GetIntegrity(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if level != descriptor.MediumIntegrity || policy != descriptor.NoWriteUp {
+		t.Errorf("expected default medium integrity with no-write-up policy, found %s %s", level, policy)
+	}
+
+	if err := SetIntegrity(dir, descriptor.LowIntegrity, descriptor.NoWriteUp|descriptor.NoExecuteUp); err != nil {
+		t.Fatal(err)
+	}
+	level, policy, err = GetIntegrity(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if level != descriptor.LowIntegrity || policy != descriptor.NoWriteUp|descriptor.NoExecuteUp {
+		t.Errorf("expected low integrity with NWNX policy, found %s %s", level, policy)
+	}
+
+	if err := SetIntegrity(dir, descriptor.LocalSystem, descriptor.NoWriteUp); err == nil {
+		t.Error("expected error when setting a SID that is not an integrity level")
+	}
+}
@@ -0,0 +1,103 @@
+//go:build linux
+
+package acl
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/icacls"
+)
+
+// LookupUser returns the SID of a user, given as a user name, a numeric user ID or an S-1-22-1-<uid> SID
+func LookupUser(name string) (descriptor.SID, error) {
+	if sid, ok, err := parseSID(name); ok {
+		if _, isUser := sid.UnixUser(); err == nil && !isUser {
+			err = fmt.Errorf("%s is not a Unix user SID (S-1-22-1-<uid>)", sid)
+		}
+		return sid, err
+	}
+	uid, err := lookupID(name, func(name string) (string, error) {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", err
+		}
+		return u.Uid, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return descriptor.UnixUserSID(uid), nil
+}
+
+// LookupGroup returns the SID of a group, given as a group name, a numeric group ID or an S-1-22-2-<gid> SID
+func LookupGroup(name string) (descriptor.SID, error) {
+	if sid, ok, err := parseSID(name); ok {
+		if _, isGroup := sid.UnixGroup(); err == nil && !isGroup {
+			err = fmt.Errorf("%s is not a Unix group SID (S-1-22-2-<gid>)", sid)
+		}
+		return sid, err
+	}
+	gid, err := lookupID(name, func(name string) (string, error) {
+		g, err := user.LookupGroup(name)
+		if err != nil {
+			return "", err
+		}
+		return g.Gid, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return descriptor.UnixGroupSID(gid), nil
+}
+
+// LookupAccount returns the SID of an account, given as a SID, a well-known account name such as Everyone (see
+// icacls.WellKnownSID), a user name or a group name, which is only looked up if no user has that name. Numeric IDs
+// could be a user or a group, so they are not accepted: use the S-1-22-1-<uid> and S-1-22-2-<gid> SIDs instead.
+func LookupAccount(name string) (descriptor.SID, error) {
+	if sid, ok, err := parseSID(name); ok {
+		return sid, err
+	}
+	if name == "" {
+		return "", fmt.Errorf("account name cannot be empty")
+	}
+	if sid, ok := icacls.WellKnownSID(name); ok {
+		return sid, nil
+	}
+	if u, err := user.Lookup(name); err == nil {
+		return LookupUser(u.Uid)
+	}
+	g, err := user.LookupGroup(name)
+	if err != nil {
+		return "", fmt.Errorf("unknown account %q", name)
+	}
+	return LookupGroup(g.Gid)
+}
+
+// parseSID parses the name if it is a SID string. It returns false if it is not one.
+func parseSID(name string) (descriptor.SID, bool, error) {
+	if !strings.HasPrefix(strings.ToUpper(name), "S-1-") {
+		return "", false, nil
+	}
+	sid, err := descriptor.ParseSID(name)
+	return sid, true, err
+}
+
+// lookupID converts a numeric ID or a name to an ID with the lookup function
+func lookupID(s string, lookup func(name string) (string, error)) (uint32, error) {
+	if s == "" {
+		return 0, fmt.Errorf("name cannot be empty")
+	}
+	if id, err := strconv.ParseUint(s, 10, 32); err == nil {
+		return uint32(id), nil
+	}
+	id, err := lookup(s)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseUint(id, 10, 32)
+	return uint32(v), err
+}
@@ -0,0 +1,40 @@
+//go:build linux
+
+package acl
+
+import (
+	"testing"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"0", "root", "S-1-22-1-0"} {
+		sid, err := LookupUser(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, descriptor.UnixUserSID(0), sid, name)
+	}
+	for _, name := range []string{"0", "root", "S-1-22-2-0"} {
+		sid, err := LookupGroup(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, descriptor.UnixGroupSID(0), sid, name)
+	}
+	_, err := LookupUser("S-1-22-2-0")
+	assert.Error(t, err, "a group SID is not a user")
+	_, err = LookupGroup("")
+	assert.Error(t, err)
+
+	for name, expected := range map[string]descriptor.SID{
+		"root":       descriptor.UnixUserSID(0),
+		"Everyone":   descriptor.Everyone,
+		"S-1-22-2-5": descriptor.UnixGroupSID(5),
+	} {
+		sid, err := LookupAccount(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, sid, name)
+	}
+	_, err = LookupAccount("0")
+	assert.Error(t, err, "numeric IDs are ambiguous")
+}
@@ -0,0 +1,39 @@
+//go:build windows
+
+package acl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"golang.org/x/sys/windows"
+)
+
+// LookupUser returns the SID of a user, given as a SID or an account name. It is the same as LookupAccount, and
+// exists so that users can be looked up the same way on Windows and Linux.
+func LookupUser(name string) (descriptor.SID, error) {
+	return LookupAccount(name)
+}
+
+// LookupGroup returns the SID of a group, given as a SID or an account name. It is the same as LookupAccount, and
+// exists so that groups can be looked up the same way on Windows and Linux.
+func LookupGroup(name string) (descriptor.SID, error) {
+	return LookupAccount(name)
+}
+
+// LookupAccount returns the SID of an account, given as a SID or an account name (e.g. BUILTIN\Administrators), which
+// is resolved with LookupAccountName
+func LookupAccount(name string) (descriptor.SID, error) {
+	if name == "" {
+		return "", fmt.Errorf("account name cannot be empty")
+	}
+	if strings.HasPrefix(strings.ToUpper(name), "S-1-") {
+		return descriptor.ParseSID(name)
+	}
+	sid, _, _, err := windows.LookupSID("", name)
+	if err != nil {
+		return "", fmt.Errorf("unknown account %q: %w", name, err)
+	}
+	return descriptor.SID(sid.String()), nil
+}
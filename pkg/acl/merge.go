@@ -0,0 +1,75 @@
+//go:build windows
+
+package acl
+
+import (
+	"github.com/rancher/permissions/pkg/descriptor"
+	"golang.org/x/sys/windows"
+)
+
+// MergeMode controls how the provided EXPLICIT_ACCESS rules are combined with the existing DACL of a path
+type MergeMode string
+
+const (
+	// Replace replaces the whole DACL with the provided rules. The DACL is protected, so ACEs inherited from the
+	// parent are removed and no longer apply. This is the default mode.
+	Replace MergeMode = "replace"
+	// Merge adds the provided rules to the existing DACL. Any explicit ACE of a trustee referenced by the rules is
+	// replaced, while the ACEs of every other trustee are kept. Whether the DACL inherits from the parent is left
+	// unchanged.
+	Merge MergeMode = "merge"
+	// RemoveTrustee removes every explicit ACE of the trustees referenced by the rules from the existing DACL. The
+	// access mode and permissions of the rules are ignored. Whether the DACL inherits from the parent is left
+	// unchanged.
+	RemoveTrustee MergeMode = "remove-trustee"
+)
+
+// replacesDACL returns true if the mode discards the existing DACL of the path
+func (m MergeMode) replacesDACL() bool {
+	return m == "" || m == Replace
+}
+
+// mergeDACL combines the EXPLICIT_ACCESS rules with the current DACL of the path according to the merge mode.
+// The resulting ACEs are returned in canonical order.
+func mergeDACL(path string, mode MergeMode, access []windows.EXPLICIT_ACCESS) (descriptor.ACL, error) {
+	existing, err := currentDACL(path)
+	if err != nil {
+		return nil, err
+	}
+
+	trustees := make(map[descriptor.SID]bool)
+	var aces descriptor.ACL
+	for _, ea := range access {
+		ace, ok, err := toACE(ea)
+		if err != nil {
+			return nil, err
+		}
+		trustees[ace.SID] = true
+		if ok && mode == Merge {
+			aces = append(aces, ace)
+		}
+	}
+
+	merged := make(descriptor.ACL, 0, len(existing)+len(aces))
+	for _, ace := range existing {
+		if !ace.IsInherited() && trustees[ace.SID] {
+			continue
+		}
+		merged = append(merged, ace)
+	}
+	merged = append(merged, aces...)
+	return merged.Canonicalize(), nil
+}
+
+// currentDACL returns the ACEs of the DACL currently set on the path
+func currentDACL(path string) (descriptor.ACL, error) {
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.DACL_SECURITY_INFORMATION)
+	if err != nil {
+		return nil, err
+	}
+	dacl, _, err := sd.DACL()
+	if err != nil {
+		return nil, err
+	}
+	return fromWindowsACL(dacl)
+}
@@ -0,0 +1,105 @@
+//go:build windows
+
+package acl
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/sid"
+	"golang.org/x/sys/windows"
+)
+
+func TestApplyMergeMode(t *testing.T) {
+	tempFile, err := os.CreateTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := tempFile.Name()
+	tempFile.Close()
+	defer os.Remove(f)
+
+	system := descriptor.SID(sid.LocalSystem().String())
+	admins := descriptor.SID(sid.BuiltinAdministrators().String())
+	everyone := descriptor.SID(sid.Everyone().String())
+
+	testCases := []struct {
+		Name        string
+		Mode        MergeMode
+		Permissions []windows.EXPLICIT_ACCESS
+
+		ExpectedTrustees []descriptor.SID
+	}{
+		{
+			Name: "Replace sets the DACL",
+			Mode: Replace,
+			Permissions: []windows.EXPLICIT_ACCESS{
+				access.GrantSid(windows.GENERIC_ALL, sid.LocalSystem()),
+				access.GrantSid(windows.GENERIC_READ, sid.Everyone()),
+			},
+			ExpectedTrustees: []descriptor.SID{system, everyone},
+		},
+		{
+			Name: "Merge keeps other trustees",
+			Mode: Merge,
+			Permissions: []windows.EXPLICIT_ACCESS{
+				access.GrantSid(windows.GENERIC_ALL, sid.BuiltinAdministrators()),
+			},
+			ExpectedTrustees: []descriptor.SID{system, everyone, admins},
+		},
+		{
+			Name: "Merge places deny entries first",
+			Mode: Merge,
+			Permissions: []windows.EXPLICIT_ACCESS{
+				access.DenySid(windows.GENERIC_WRITE, sid.Everyone()),
+			},
+			ExpectedTrustees: []descriptor.SID{everyone, system, admins},
+		},
+		{
+			Name: "Remove trustee",
+			Mode: RemoveTrustee,
+			Permissions: []windows.EXPLICIT_ACCESS{
+				access.GrantSid(0, sid.Everyone()),
+			},
+			ExpectedTrustees: []descriptor.SID{system, admins},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := ApplyCustom(f, nil, nil, Options{Mode: tc.Mode}, tc.Permissions...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sd, err := windows.GetNamedSecurityInfo(f, windows.SE_FILE_OBJECT, windows.DACL_SECURITY_INFORMATION)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dacl, _, err := sd.DACL()
+			if err != nil {
+				t.Fatal(err)
+			}
+			aces, err := fromWindowsACL(dacl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var trustees []descriptor.SID
+			for _, ace := range aces.Explicit() {
+				if ace.AppliesToObject() {
+					trustees = append(trustees, ace.SID)
+				}
+			}
+			if len(trustees) != len(tc.ExpectedTrustees) {
+				t.Fatalf("expected trustees %v, found %v", tc.ExpectedTrustees, trustees)
+			}
+			for i := range trustees {
+				if trustees[i] != tc.ExpectedTrustees[i] {
+					t.Errorf("expected trustees %v, found %v", tc.ExpectedTrustees, trustees)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+//go:build linux
+
+package acl
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// Mkdir creates a directory with the provided permissions if it does not exist already
+// If it already exists, it just applies the provided permissions
+//
+// The permissions are ACEs, as Set accepts them: the ACEs that apply to the directory make up its access ACL,
+// and the inheritable ACEs (e.g. the ObjectInherit and ContainerInherit ACEs that access.GrantSid creates on Windows)
+// make up its default ACL, so that the files and directories created in it inherit them. Without ACEs, the directory
+// is created with the default ACL of its parent, or with the permission bits allowed by the umask.
+//
+// It is called through Files.Mkdir (or System.Mkdir), which has the same signature on both OSes, unlike the Windows
+// Mkdir function that takes EXPLICIT_ACCESS entries.
+func (systemBackend) Mkdir(path string, aces ...descriptor.ACE) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	_, err := os.Stat(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if os.IsNotExist(err) {
+		perm := os.FileMode(0777)
+		if len(aces) != 0 {
+			// the directory is only accessible to its owner until the ACLs are written
+			perm = 0700
+		}
+		if err := os.Mkdir(path, perm); err != nil {
+			return err
+		}
+	}
+	if len(aces) == 0 {
+		return nil
+	}
+	sd := &descriptor.SecurityDescriptor{Control: descriptor.DACLPresent, DACL: aces}
+	return Set(path, sd, descriptor.DACLInformation)
+}
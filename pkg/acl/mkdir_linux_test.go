@@ -0,0 +1,74 @@
+//go:build linux
+
+package acl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/filemode"
+	"github.com/rancher/permissions/pkg/posixacl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMkdir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dir")
+	owner := descriptor.UnixUserSID(uint32(os.Getuid()))
+	group := descriptor.UnixGroupSID(uint32(os.Getgid()))
+	inherit := descriptor.ObjectInherit | descriptor.ContainerInherit
+	aces := []descriptor.ACE{
+		{Type: descriptor.AccessAllowed, Flags: inherit, Mask: filemode.Rights(07), SID: owner},
+		{Type: descriptor.AccessAllowed, Flags: inherit, Mask: filemode.Rights(05), SID: group},
+		{Type: descriptor.AccessAllowed, Flags: inherit | descriptor.InheritOnly, Mask: filemode.Rights(04), SID: descriptor.UnixUserSID(1001)},
+	}
+	require.NoError(t, System.Mkdir(dir, aces...))
+
+	access, defaults, err := GetPOSIX(dir)
+	require.NoError(t, err)
+	assert.Equal(t, posixacl.FromMode(0750), access)
+	assert.Equal(t, posixacl.ACL{
+		{Tag: posixacl.UserObj, Perm: posixacl.All},
+		{Tag: posixacl.User, ID: 1001, Perm: posixacl.Read},
+		{Tag: posixacl.GroupObj, Perm: posixacl.Read | posixacl.Execute},
+		{Tag: posixacl.Mask, Perm: posixacl.Read | posixacl.Execute},
+		{Tag: posixacl.Other, Perm: 0},
+	}, defaults)
+
+	sd, err := Get(dir)
+	require.NoError(t, err)
+	assert.Contains(t, sd.DACL, descriptor.ACE{
+		Type:  descriptor.AccessAllowed,
+		Flags: inherit | descriptor.InheritOnly,
+		Mask:  filemode.Rights(07),
+		SID:   descriptor.CreatorOwner,
+	})
+
+	// files and directories created in the directory inherit the default ACL
+	sub := filepath.Join(dir, "sub")
+	require.NoError(t, System.Mkdir(sub))
+	_, subDefaults, err := GetPOSIX(sub)
+	require.NoError(t, err)
+	assert.Equal(t, defaults, subDefaults)
+	f := filepath.Join(sub, "file")
+	require.NoError(t, os.WriteFile(f, nil, 0666))
+	fileAccess, _, err := GetPOSIX(f)
+	require.NoError(t, err)
+	assert.Contains(t, fileAccess, posixacl.Entry{Tag: posixacl.User, ID: 1001, Perm: posixacl.Read})
+
+	// the permissions of an existing directory are replaced
+	require.NoError(t, System.Mkdir(dir, filemode.ToDACL(0700, owner, group)...))
+	access, defaults, err = GetPOSIX(dir)
+	require.NoError(t, err)
+	assert.Equal(t, posixacl.FromMode(0700), access)
+	assert.Nil(t, defaults)
+
+	// the portable Mkdir behaves the same
+	other := filepath.Join(filepath.Dir(dir), "other")
+	require.NoError(t, Files{}.Mkdir(other, aces...))
+	_, otherDefaults, err := GetPOSIX(other)
+	require.NoError(t, err)
+	assert.Equal(t, subDefaults, otherDefaults)
+}
@@ -0,0 +1,40 @@
+//go:build linux
+
+package acl
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/rancher/permissions/pkg/nfs4acl"
+)
+
+// GetNFS4 returns the NFSv4 ACL of a file / directory on a file system that supports them (NFSv4 mounts, ZFS,
+// CephFS), as stored in the system.nfs4_acl extended attribute. It returns nil if the file system does not expose one.
+func GetNFS4(path string) (nfs4acl.ACL, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	xattr, err := getxattr(path, nfs4acl.Xattr)
+	if err != nil || xattr == nil {
+		return nil, err
+	}
+	acl, err := nfs4acl.ParseXattr(xattr)
+	if err != nil {
+		return nil, &fs.PathError{Op: "getxattr", Path: path, Err: err}
+	}
+	return acl, nil
+}
+
+// SetNFS4 replaces the NFSv4 ACL of a file / directory by writing the system.nfs4_acl extended attribute. Use
+// nfs4acl.FromACL to write the ACEs of a Windows DACL.
+func SetNFS4(path string, acl nfs4acl.ACL) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	xattr, err := acl.MarshalXattr()
+	if err != nil {
+		return &fs.PathError{Op: "setxattr", Path: path, Err: err}
+	}
+	return setxattr(path, nfs4acl.Xattr, xattr)
+}
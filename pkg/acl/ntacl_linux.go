@@ -0,0 +1,49 @@
+//go:build linux
+
+package acl
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/ntacl"
+)
+
+// GetNTACL returns the Windows security descriptor that Samba stores for a file / directory in an extended
+// attribute, or nil if it has none. Name is the attribute, which defaults to security.NTACL; shares configured with
+// acl_xattr:security_acl_name use another one.
+func GetNTACL(path, name string) (*descriptor.SecurityDescriptor, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	if name == "" {
+		name = ntacl.Xattr
+	}
+	xattr, err := getxattr(path, name)
+	if err != nil || xattr == nil {
+		return nil, err
+	}
+	blob, err := ntacl.Parse(xattr)
+	if err != nil {
+		return nil, &fs.PathError{Op: "getxattr", Path: path, Err: err}
+	}
+	return blob.SD, nil
+}
+
+// SetNTACL stores the security descriptor of a file / directory in the extended attribute that Samba reads it from,
+// which defaults to security.NTACL. The POSIX ACL of the file is left unchanged, so the descriptor only applies to
+// access through Samba.
+func SetNTACL(path, name string, sd *descriptor.SecurityDescriptor) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	if name == "" {
+		name = ntacl.Xattr
+	}
+	xattr, err := ntacl.New(sd).MarshalBinary()
+	if err != nil {
+		return &fs.PathError{Op: "setxattr", Path: path, Err: err}
+	}
+	return setxattr(path, name, xattr)
+}
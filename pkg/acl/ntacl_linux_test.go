@@ -0,0 +1,48 @@
+//go:build linux
+
+package acl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/ntacl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestNTACL(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(f, nil, 0644))
+
+	// user.NTACL can be written without CAP_SYS_ADMIN, as long as the file system supports user xattrs
+	const name = "user.NTACL"
+	sd, err := GetNTACL(f, name)
+	require.NoError(t, err)
+	assert.Nil(t, sd)
+
+	expected, err := descriptor.ParseSDDL("O:BAG:SYD:PAI(A;;FA;;;BA)(A;;FR;;;S-1-5-21-1-2-3-1001)")
+	require.NoError(t, err)
+	err = SetNTACL(f, name, expected)
+	if errors.Is(err, unix.ENOTSUP) {
+		t.Skip("user xattrs are not supported")
+	}
+	require.NoError(t, err)
+	sd, err = GetNTACL(f, name)
+	require.NoError(t, err)
+	assert.Equal(t, expected, sd)
+
+	xattr, err := getxattr(f, name)
+	require.NoError(t, err)
+	blob, err := ntacl.Parse(xattr)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(1), blob.Version)
+
+	require.NoError(t, setxattr(f, name, []byte{1, 0}))
+	_, err = GetNTACL(f, name)
+	assert.Error(t, err)
+}
@@ -0,0 +1,28 @@
+//go:build windows
+
+package acl
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// Options customizes how ApplyCustom writes the security descriptor of a path
+type Options struct {
+	// Mode controls how the provided EXPLICIT_ACCESS rules are combined with the existing DACL. Defaults to Replace.
+	Mode MergeMode
+
+	// Inheritance controls whether the DACL inherits ACEs from the parent. Defaults to DefaultInheritance.
+	Inheritance Inheritance
+
+	// Canonicalize sorts the ACEs of the DACL into canonical order (explicit deny ACEs, then explicit allow ACEs,
+	// then inherited ACEs) before it is written
+	Canonicalize bool
+
+	// Audit holds the rules of the SACL, which define the accesses that generate audit records. To create audit
+	// rules, see the Audit* helper functions in pkg/access. If nil, the SACL is left unchanged, while an empty slice
+	// removes every audit rule.
+	//
+	// Writing the SACL requires the SeSecurityPrivilege, which is enabled for the duration of the call. If the caller
+	// does not hold it, a *PrivilegeError is returned.
+	Audit []windows.EXPLICIT_ACCESS
+}
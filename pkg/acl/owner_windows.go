@@ -0,0 +1,37 @@
+//go:build windows
+
+package acl
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/rancher/permissions/pkg/sid"
+)
+
+// TakeOwnership makes the current user the owner of the file / directory, like `takeown /f path`. If recursive is
+// true, it also takes ownership of every file / directory below it, like `takeown /f path /r`. Symbolic links are
+// not followed.
+//
+// The SeTakeOwnershipPrivilege and SeRestorePrivilege are enabled for the duration of the operation if the caller
+// holds them. Taking ownership does not change the DACL, so it does not grant any access apart from the right to
+// change the DACL: use Apply or Reset afterwards to do so.
+func TakeOwnership(path string, recursive bool) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	owner := sid.CurrentUser()
+	if !recursive {
+		return apply(path, owner, nil, Options{})
+	}
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		return apply(p, owner, nil, Options{})
+	})
+}
@@ -0,0 +1,68 @@
+//go:build windows
+
+package acl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/sid"
+	"golang.org/x/sys/windows"
+)
+
+func TestTakeOwnership(t *testing.T) {
+	dir, err := os.MkdirTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	f := filepath.Join(dir, "file")
+	if err := os.WriteFile(f, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := TakeOwnership(dir, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{dir, f} {
+		sd, err := Get(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sd.Owner != descriptor.SID(sid.CurrentUser().String()) {
+			t.Errorf("expected %s to be owned by %s, found %s", p, sid.CurrentUser(), sd.Owner)
+		}
+	}
+}
+
+func TestWithPrivileges(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	err := withPrivileges(nil, ownerPrivileges, func() error {
+		// the privileges are enabled on an impersonation token of the current thread
+		token, err := openThreadToken()
+		if err != nil {
+			return err
+		}
+		return token.Close()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// once the operation is done, the thread no longer impersonates
+	_, err = openThreadToken()
+	if !errors.Is(err, windows.ERROR_NO_TOKEN) {
+		t.Errorf("expected thread to stop impersonating, found %v", err)
+	}
+
+	err = withPrivileges([]string{"SeNotAPrivilege"}, nil, func() error { return nil })
+	if err == nil {
+		t.Error("expected error for an unknown privilege")
+	}
+}
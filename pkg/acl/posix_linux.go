@@ -0,0 +1,105 @@
+//go:build linux
+
+package acl
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/rancher/permissions/pkg/posixacl"
+)
+
+// GetPOSIX returns the access ACL and the default ACL of the file / directory. If no extended ACL is set, the access
+// ACL is the minimal ACL of the permission bits, and the default ACL is nil if the directory has none.
+//
+// The ACLs are read from the system.posix_acl_access and system.posix_acl_default extended attributes, so this does
+// not depend on libacl.
+func GetPOSIX(path string) (access, defaults posixacl.ACL, err error) {
+	if path == "" {
+		return nil, nil, fmt.Errorf("path cannot be empty")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if access, err = getPOSIXXattr(path, accessACLXattr); err != nil {
+		return nil, nil, err
+	}
+	if access == nil {
+		access = posixacl.FromMode(info.Mode())
+	}
+	if info.IsDir() {
+		if defaults, err = getPOSIXXattr(path, defaultACLXattr); err != nil {
+			return nil, nil, err
+		}
+	}
+	return access, defaults, nil
+}
+
+// SetPOSIX writes the access ACL and the default ACL of the file / directory. A minimal access ACL is written as
+// permission bits, and any extended ACL is removed. A nil default ACL removes the default ACL of a directory, and
+// files cannot have one.
+//
+// The ACLs are validated with posixacl.ACL.Validate, and written to the system.posix_acl_access and
+// system.posix_acl_default extended attributes, so this does not depend on libacl.
+func SetPOSIX(path string, access, defaults posixacl.ACL) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if defaults != nil && !info.IsDir() {
+		return &fs.PathError{Op: "setfacl", Path: path, Err: fmt.Errorf("only directories can have a default ACL")}
+	}
+	// both ACLs are validated before anything is written
+	if err := access.Validate(); err != nil {
+		return &fs.PathError{Op: "setfacl", Path: path, Err: fmt.Errorf("invalid access ACL: %w", err)}
+	}
+	if defaults != nil {
+		if err := defaults.Validate(); err != nil {
+			return &fs.PathError{Op: "setfacl", Path: path, Err: fmt.Errorf("invalid default ACL: %w", err)}
+		}
+	}
+	if err := setPOSIXXattr(path, accessACLXattr, access); err != nil {
+		return err
+	}
+	if access.IsMinimal() {
+		// the kernel updates the permission bits when an extended ACL is written, but not when it is removed
+		if err := os.Chmod(path, info.Mode()&^os.ModePerm|access.Mode()); err != nil {
+			return err
+		}
+	}
+	if !info.IsDir() {
+		return nil
+	}
+	return setPOSIXXattr(path, defaultACLXattr, defaults)
+}
+
+// getPOSIXXattr reads and decodes the ACL stored in the extended attribute, or returns nil if it is not set
+func getPOSIXXattr(path, name string) (posixacl.ACL, error) {
+	xattr, err := getxattr(path, name)
+	if err != nil || xattr == nil {
+		return nil, err
+	}
+	acl, err := posixacl.ParseXattr(xattr)
+	if err != nil {
+		return nil, &fs.PathError{Op: "getxattr", Path: path, Err: err}
+	}
+	return acl, nil
+}
+
+// setPOSIXXattr encodes and writes the ACL to the extended attribute. Minimal access ACLs and nil default ACLs remove
+// the attribute instead.
+func setPOSIXXattr(path, name string, acl posixacl.ACL) error {
+	if acl == nil || (name == accessACLXattr && acl.IsMinimal()) {
+		return removexattr(path, name)
+	}
+	xattr, err := acl.MarshalXattr()
+	if err != nil {
+		return &fs.PathError{Op: "setxattr", Path: path, Err: err}
+	}
+	return setxattr(path, name, xattr)
+}
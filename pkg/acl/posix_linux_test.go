@@ -0,0 +1,75 @@
+//go:build linux
+
+package acl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/filemode"
+	"github.com/rancher/permissions/pkg/posixacl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPOSIX(t *testing.T) {
+	dir := t.TempDir()
+	f := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(f, nil, 0600))
+
+	access := posixacl.ACL{
+		{Tag: posixacl.UserObj, Perm: posixacl.All},
+		{Tag: posixacl.User, ID: 1001, Perm: posixacl.Read | posixacl.Write},
+		{Tag: posixacl.GroupObj, Perm: posixacl.Read},
+		{Tag: posixacl.Mask, Perm: posixacl.Read},
+		{Tag: posixacl.Other, Perm: 0},
+	}
+	require.NoError(t, SetPOSIX(f, access, nil))
+	actual, defaults, err := GetPOSIX(f)
+	require.NoError(t, err)
+	assert.Equal(t, access, actual)
+	assert.Nil(t, defaults)
+	info, err := os.Stat(f)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0740), info.Mode().Perm(), "the group bits should hold the mask")
+
+	sd, err := Get(f)
+	require.NoError(t, err)
+	assert.Equal(t, descriptor.ACE{Type: descriptor.AccessAllowed, Mask: filemode.Rights(04), SID: descriptor.UnixUserSID(1001)}, sd.DACL[1])
+
+	require.NoError(t, SetPOSIX(f, posixacl.FromMode(0644), nil))
+	xattr, err := getxattr(f, accessACLXattr)
+	require.NoError(t, err)
+	assert.Nil(t, xattr)
+	info, err = os.Stat(f)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+	require.NoError(t, SetPOSIX(dir, posixacl.FromMode(0755), posixacl.FromMode(0750)))
+	_, defaults, err = GetPOSIX(dir)
+	require.NoError(t, err)
+	assert.Equal(t, posixacl.FromMode(0750), defaults)
+
+	assert.Error(t, SetPOSIX(f, posixacl.FromMode(0644), posixacl.FromMode(0644)))
+	assert.Error(t, SetPOSIX(f, posixacl.ACL{{Tag: posixacl.UserObj, Perm: posixacl.All}}, nil))
+}
+
+func TestSetNamedUser(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(f, nil, 0600))
+	sd, err := Get(f)
+	require.NoError(t, err)
+
+	named := descriptor.ACE{Type: descriptor.AccessAllowed, Mask: filemode.Rights(05), SID: descriptor.UnixGroupSID(50)}
+	sd.DACL = append(sd.DACL, named)
+	require.NoError(t, Set(f, sd, descriptor.DACLInformation))
+	access, _, err := GetPOSIX(f)
+	require.NoError(t, err)
+	assert.Contains(t, access, posixacl.Entry{Tag: posixacl.Group, ID: 50, Perm: posixacl.Read | posixacl.Execute})
+
+	actual, err := Get(f)
+	require.NoError(t, err)
+	assert.Contains(t, actual.DACL, named)
+}
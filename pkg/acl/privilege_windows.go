@@ -0,0 +1,176 @@
+//go:build windows
+
+package acl
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// securityPrivilege is required to read or write the SACL of an object
+	securityPrivilege = "SeSecurityPrivilege"
+	// takeOwnershipPrivilege allows setting the owner of an object to the caller (or one of its groups) without
+	// being granted WRITE_OWNER access
+	takeOwnershipPrivilege = "SeTakeOwnershipPrivilege"
+	// restorePrivilege allows setting the owner of an object to any SID
+	restorePrivilege = "SeRestorePrivilege"
+	// relabelPrivilege allows raising the mandatory label of an object above the integrity level of the caller
+	relabelPrivilege = "SeRelabelPrivilege"
+)
+
+// ownerPrivileges are enabled, if held, whenever the owner of an object is changed
+var ownerPrivileges = []string{restorePrivilege, takeOwnershipPrivilege}
+
+// procAdjustTokenPrivileges is called directly, since windows.AdjustTokenPrivileges does not report
+// ERROR_NOT_ALL_ASSIGNED, which is returned when the token does not hold a privilege
+var procAdjustTokenPrivileges = windows.NewLazySystemDLL("advapi32.dll").NewProc("AdjustTokenPrivileges")
+
+// PrivilegeError is returned when an operation requires a privilege that the caller does not hold
+type PrivilegeError struct {
+	Privilege string
+}
+
+func (e *PrivilegeError) Error() string {
+	return fmt.Sprintf("the %s privilege is required for this operation, but it is not held by the caller", e.Privilege)
+}
+
+// Unwrap allows checking a PrivilegeError with errors.Is(err, windows.ERROR_PRIVILEGE_NOT_HELD)
+func (e *PrivilegeError) Unwrap() error {
+	return windows.ERROR_PRIVILEGE_NOT_HELD
+}
+
+// withPrivileges runs fn with the required privileges enabled, as well as the optional privileges that are held by
+// the caller. A *PrivilegeError is returned if a required privilege is not held.
+//
+// The privileges are only enabled on the token of the current OS thread, which is locked for the duration of the
+// call: if the thread is not impersonating, it impersonates the process so that the process token is left untouched.
+// Once fn returns, the privileges are restored to their prior state and impersonation is reverted.
+func withPrivileges(required, optional []string, fn func() error) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	token, err := openThreadToken()
+	if errors.Is(err, windows.ERROR_NO_TOKEN) {
+		if err := windows.ImpersonateSelf(windows.SecurityImpersonation); err != nil {
+			return fmt.Errorf("unable to impersonate self: %w", err)
+		}
+		defer windows.RevertToSelf()
+		token, err = openThreadToken()
+	}
+	if err != nil {
+		return fmt.Errorf("unable to open thread token: %w", err)
+	}
+	defer token.Close()
+
+	var previous []windows.Tokenprivileges
+	defer func() {
+		// restore in reverse order, since a privilege may have been adjusted more than once
+		for i := len(previous) - 1; i >= 0; i-- {
+			_, _ = adjustTokenPrivileges(token, &previous[i], nil)
+		}
+	}()
+	for _, privilege := range required {
+		prev, err := enablePrivilege(token, privilege)
+		if err != nil {
+			return err
+		}
+		previous = append(previous, prev)
+	}
+	for _, privilege := range optional {
+		prev, err := enablePrivilege(token, privilege)
+		var privilegeErr *PrivilegeError
+		if errors.As(err, &privilegeErr) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		previous = append(previous, prev)
+	}
+	return fn()
+}
+
+// openThreadToken opens the impersonation token of the current thread. It returns ERROR_NO_TOKEN if the thread is not
+// impersonating.
+func openThreadToken() (windows.Token, error) {
+	var token windows.Token
+	err := windows.OpenThreadToken(windows.CurrentThread(), windows.TOKEN_ADJUST_PRIVILEGES|windows.TOKEN_QUERY, true, &token)
+	return token, err
+}
+
+// enablePrivilege enables a privilege held by the token and returns its previous state
+func enablePrivilege(token windows.Token, privilege string) (windows.Tokenprivileges, error) {
+	name, err := windows.UTF16PtrFromString(privilege)
+	if err != nil {
+		return windows.Tokenprivileges{}, err
+	}
+	var luid windows.LUID
+	if err := windows.LookupPrivilegeValue(nil, name, &luid); err != nil {
+		return windows.Tokenprivileges{}, fmt.Errorf("unable to look up privilege %s: %w", privilege, err)
+	}
+	privileges := windows.Tokenprivileges{
+		PrivilegeCount: 1,
+		Privileges: [1]windows.LUIDAndAttributes{
+			{Luid: luid, Attributes: windows.SE_PRIVILEGE_ENABLED},
+		},
+	}
+	var previous windows.Tokenprivileges
+	notAllAssigned, err := adjustTokenPrivileges(token, &privileges, &previous)
+	if err != nil {
+		return windows.Tokenprivileges{}, fmt.Errorf("unable to enable privilege %s: %w", privilege, err)
+	}
+	if notAllAssigned {
+		return windows.Tokenprivileges{}, &PrivilegeError{Privilege: privilege}
+	}
+	return previous, nil
+}
+
+// adjustTokenPrivileges calls AdjustTokenPrivileges, reporting whether some privileges are not held by the token.
+// If previous is not nil, it receives the prior state of the privileges that were changed.
+func adjustTokenPrivileges(token windows.Token, privileges *windows.Tokenprivileges, previous *windows.Tokenprivileges) (bool, error) {
+	var size, returnLength uint32
+	if previous != nil {
+		size = uint32(unsafe.Sizeof(*previous))
+	}
+	r1, _, e1 := procAdjustTokenPrivileges.Call(
+		uintptr(token),
+		0,
+		uintptr(unsafe.Pointer(privileges)),
+		uintptr(size),
+		uintptr(unsafe.Pointer(previous)),
+		uintptr(unsafe.Pointer(&returnLength)),
+	)
+	if r1 == 0 {
+		return false, e1
+	}
+	return e1 == windows.ERROR_NOT_ALL_ASSIGNED, nil
+}
+
+// setNamedSecurityInfo calls windows.SetNamedSecurityInfo on the file / directory, enabling the privileges needed to
+// write the selected parts of the security descriptor
+func setNamedSecurityInfo(path string, securityInfo windows.SECURITY_INFORMATION, owner, group *windows.SID, dacl, sacl *windows.ACL) error {
+	set := func() error {
+		return windows.SetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, securityInfo, owner, group, dacl, sacl)
+	}
+	var required, optional []string
+	if securityInfo&windows.SACL_SECURITY_INFORMATION != 0 {
+		required = append(required, securityPrivilege)
+	}
+	if securityInfo&windows.OWNER_SECURITY_INFORMATION != 0 {
+		// without these privileges, the owner can only be set to the caller and only if it was granted WRITE_OWNER
+		optional = append(optional, ownerPrivileges...)
+	}
+	if securityInfo&windows.LABEL_SECURITY_INFORMATION != 0 {
+		// without this privilege, the label can only be set up to the integrity level of the caller
+		optional = append(optional, relabelPrivilege)
+	}
+	if len(required) == 0 && len(optional) == 0 {
+		return set()
+	}
+	return withPrivileges(required, optional, set)
+}
@@ -0,0 +1,77 @@
+package acl
+
+import (
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// InheritedACEs returns the ACEs that a new file (or directory, if isDir is set) inherits from the DACL or SACL of its
+// parent directory, as Windows computes them:
+//
+//   - files inherit the ObjectInherit ACEs, and directories the ContainerInherit ACEs, which keep their inheritance
+//     flags unless NoPropagateInherit is set
+//   - directories inherit the ObjectInherit ACEs that are not ContainerInherit as inherit-only ACEs, so that they
+//     reach the files below them, unless NoPropagateInherit is set
+//   - the owner and group replace Creator Owner and Creator Group, and the generic rights are expanded with
+//     access.FileGenericMapping, in the ACEs that apply to the new object. When such an ACE is also propagated
+//     further, it is split into an ACE for the object and an inherit-only ACE that keeps the original SID and rights.
+//
+// The ACEs are marked as inherited. An empty owner or group is not replaced.
+func InheritedACEs(parent descriptor.ACL, isDir bool, owner, group descriptor.SID) descriptor.ACL {
+	var inherited descriptor.ACL
+	for _, ace := range parent {
+		objectInherit := ace.Flags&descriptor.ObjectInherit != 0
+		containerInherit := ace.Flags&descriptor.ContainerInherit != 0
+		noPropagate := ace.Flags&descriptor.NoPropagateInherit != 0
+		// the audit flags are kept in every inherited ACE
+		base := ace.Flags&descriptor.AuditFlags | descriptor.Inherited
+
+		var applies, propagates bool
+		var propagated descriptor.ACEFlags
+		switch {
+		case !isDir:
+			applies = objectInherit
+		case containerInherit:
+			applies = true
+			propagates = !noPropagate
+			propagated = ace.Flags & (descriptor.ObjectInherit | descriptor.ContainerInherit)
+		case objectInherit:
+			propagates = !noPropagate
+			propagated = descriptor.ObjectInherit | descriptor.InheritOnly
+		}
+		if !applies && !propagates {
+			continue
+		}
+
+		effective := ace
+		effective.Flags = base
+		switch ace.SID {
+		case descriptor.CreatorOwner:
+			if owner != "" {
+				effective.SID = owner
+			}
+		case descriptor.CreatorGroup:
+			if group != "" {
+				effective.SID = group
+			}
+		}
+		if ace.Type != descriptor.SystemMandatoryLabel {
+			effective.Mask = access.FileGenericMapping.Expand(ace.Mask)
+		}
+		split := effective.SID != ace.SID || effective.Mask != ace.Mask
+
+		if applies && propagates && !split {
+			effective.Flags |= propagated
+			inherited = append(inherited, effective)
+			continue
+		}
+		if applies {
+			inherited = append(inherited, effective)
+		}
+		if propagates {
+			ace.Flags = base | propagated | descriptor.InheritOnly
+			inherited = append(inherited, ace)
+		}
+	}
+	return inherited
+}
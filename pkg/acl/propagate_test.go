@@ -0,0 +1,46 @@
+package acl
+
+import (
+	"testing"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInheritedACEs(t *testing.T) {
+	parent, err := descriptor.ParseSDDL("D:(A;OICI;FA;;;SY)(A;OICIIO;GA;;;CO)(A;CI;0x1200a9;;;BU)(A;OI;FR;;;AU)" +
+		"(A;OICINP;FW;;;S-1-5-21-1-2-3-1001)(A;;FA;;;BA)")
+	require.NoError(t, err)
+	owner := descriptor.MustParseSID("S-1-5-21-1-2-3-1002")
+
+	dir := descriptor.ACL{
+		{Type: descriptor.AccessAllowed, Flags: descriptor.ObjectInherit | descriptor.ContainerInherit | descriptor.Inherited, Mask: access.FullControl, SID: descriptor.LocalSystem},
+		// Creator Owner is replaced by the owner, and the generic rights are expanded, in a separate ACE
+		{Type: descriptor.AccessAllowed, Flags: descriptor.Inherited, Mask: access.FullControl, SID: owner},
+		{Type: descriptor.AccessAllowed, Flags: descriptor.ObjectInherit | descriptor.ContainerInherit | descriptor.InheritOnly | descriptor.Inherited, Mask: access.GenericAll, SID: descriptor.CreatorOwner},
+		{Type: descriptor.AccessAllowed, Flags: descriptor.ContainerInherit | descriptor.Inherited, Mask: 0x1200a9, SID: descriptor.BuiltinUsers},
+		{Type: descriptor.AccessAllowed, Flags: descriptor.ObjectInherit | descriptor.InheritOnly | descriptor.Inherited, Mask: 0x120089, SID: descriptor.AuthenticatedUsers},
+		{Type: descriptor.AccessAllowed, Flags: descriptor.Inherited, Mask: 0x120116, SID: "S-1-5-21-1-2-3-1001"},
+	}
+	assert.Equal(t, dir, InheritedACEs(parent.DACL, true, owner, ""))
+
+	file := descriptor.ACL{
+		{Type: descriptor.AccessAllowed, Flags: descriptor.Inherited, Mask: access.FullControl, SID: descriptor.LocalSystem},
+		{Type: descriptor.AccessAllowed, Flags: descriptor.Inherited, Mask: access.FullControl, SID: owner},
+		{Type: descriptor.AccessAllowed, Flags: descriptor.Inherited, Mask: 0x120089, SID: descriptor.AuthenticatedUsers},
+		{Type: descriptor.AccessAllowed, Flags: descriptor.Inherited, Mask: 0x120116, SID: "S-1-5-21-1-2-3-1001"},
+	}
+	assert.Equal(t, file, InheritedACEs(parent.DACL, false, owner, ""))
+
+	// inheriting from a directory that inherited the ACEs gives the same result for files
+	assert.Equal(t, file[:3], InheritedACEs(dir, false, owner, ""))
+
+	sacl, err := descriptor.ParseSDDL("S:(AU;OICIFA;FA;;;WD)(ML;OICI;NW;;;HI)")
+	require.NoError(t, err)
+	assert.Equal(t, descriptor.ACL{
+		{Type: descriptor.SystemAudit, Flags: descriptor.FailedAccess | descriptor.Inherited, Mask: access.FullControl, SID: descriptor.Everyone},
+		descriptor.LabelACE(descriptor.HighIntegrity, descriptor.NoWriteUp, descriptor.Inherited),
+	}, InheritedACEs(sacl.SACL, false, owner, ""))
+}
@@ -0,0 +1,51 @@
+//go:build windows || linux
+
+package acl
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+// Report lists the trustees that have access to the file / directory, with their effective rights, and flags the
+// broad groups that are granted write access. See ReportDescriptor for how effective rights are computed.
+func Report(path string, opts ReportOptions) (*AccessReport, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	sd, err := Get(path)
+	if err != nil {
+		return nil, err
+	}
+	report, err := ReportDescriptor(sd, opts)
+	if err != nil {
+		return nil, err
+	}
+	report.Path = path
+	return report, nil
+}
+
+// ReportRecursive performs a Report on the path and on every file / directory below it. Symbolic links are not
+// followed.
+func ReportRecursive(path string, opts ReportOptions) ([]*AccessReport, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path cannot be empty")
+	}
+	var reports []*AccessReport
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		report, err := Report(p, opts)
+		if err != nil {
+			return err
+		}
+		reports = append(reports, report)
+		return nil
+	})
+	return reports, err
+}
@@ -0,0 +1,46 @@
+//go:build windows
+
+package acl
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/sid"
+	"golang.org/x/sys/windows"
+)
+
+func TestReport(t *testing.T) {
+	tempFile, err := os.CreateTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := tempFile.Name()
+	tempFile.Close()
+	defer os.Remove(f)
+
+	err = Apply(f, sid.LocalSystem(), nil,
+		access.GrantSid(windows.GENERIC_ALL, sid.LocalSystem()),
+		access.GrantSid(windows.GENERIC_READ|windows.GENERIC_WRITE, sid.Everyone()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Report(f, ReportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Path != f {
+		t.Errorf("expected path %s, found %s", f, report.Path)
+	}
+	if len(report.Findings) != 1 || report.Findings[0].SID != descriptor.Everyone {
+		t.Errorf("expected a finding for Everyone, found %v", report.Findings)
+	}
+	for _, trustee := range report.Trustees {
+		if trustee.SID == descriptor.LocalSystem && trustee.Rights != access.FullControl {
+			t.Errorf("expected LocalSystem to have full control, found %s", access.FileRightsString(trustee.Rights))
+		}
+	}
+}
@@ -0,0 +1,37 @@
+//go:build windows || linux
+
+package acl
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+// Reset removes all explicit permissions of the file / directory and re-enables inheritance, so that it matches its
+// parent again. This is the equivalent of running `icacls path /reset`.
+//
+// On Linux, the extended ACL entries are removed and the default ACL of the parent directory (if any) is applied.
+func Reset(path string) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	return reset(path)
+}
+
+// ResetRecursive performs a Reset on the path and on every file / directory below it. This is the equivalent of
+// running `icacls path /reset /T`. Symbolic links are not followed.
+func ResetRecursive(path string) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		return reset(p)
+	})
+}
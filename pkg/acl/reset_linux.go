@@ -0,0 +1,82 @@
+//go:build linux
+
+package acl
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/rancher/permissions/pkg/posixacl"
+)
+
+// reset removes the extended ACL entries of the path and applies the default ACL of the parent directory, if it has
+// one, as if the path had just been created in it
+func reset(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	parentDefault, err := getxattr(filepath.Dir(path), defaultACLXattr)
+	if err != nil {
+		return err
+	}
+
+	if parentDefault != nil {
+		if info.IsDir() {
+			if err := setxattr(path, accessACLXattr, parentDefault); err != nil {
+				return err
+			}
+			return setxattr(path, defaultACLXattr, parentDefault)
+		}
+		// files are created with the 0666 mode, which the kernel applies to the default ACL as it does to the
+		// permission bits (the mask, or the owning group without a mask, holds the group bits), so they never
+		// inherit the execute permission
+		defaults, err := posixacl.ParseXattr(parentDefault)
+		if err != nil {
+			return &fs.PathError{Op: "reset", Path: filepath.Dir(path), Err: err}
+		}
+		_, hasMask := defaults.Entry(posixacl.Mask)
+		for i, entry := range defaults {
+			switch entry.Tag {
+			case posixacl.UserObj, posixacl.Mask, posixacl.Other:
+				defaults[i].Perm &^= posixacl.Execute
+			case posixacl.GroupObj:
+				if !hasMask {
+					defaults[i].Perm &^= posixacl.Execute
+				}
+			}
+		}
+		xattr, err := defaults.MarshalXattr()
+		if err != nil {
+			return err
+		}
+		return setxattr(path, accessACLXattr, xattr)
+	}
+
+	if info.IsDir() {
+		if err := removexattr(path, defaultACLXattr); err != nil {
+			return err
+		}
+	}
+	xattr, err := getxattr(path, accessACLXattr)
+	if err != nil || xattr == nil {
+		return err
+	}
+	access, err := posixacl.ParseXattr(xattr)
+	if err != nil {
+		return &fs.PathError{Op: "reset", Path: path, Err: err}
+	}
+	if err := removexattr(path, accessACLXattr); err != nil {
+		return err
+	}
+	// while an extended ACL is set, the group permission bits hold the ACL mask instead of the permissions of the
+	// owning group, so they need to be restored
+	groupObj, ok := access.Entry(posixacl.GroupObj)
+	if !ok {
+		return &fs.PathError{Op: "reset", Path: path, Err: fmt.Errorf("the access ACL has no owning group entry")}
+	}
+	mode := info.Mode()&^0070 | os.FileMode(groupObj.Perm)<<3
+	return os.Chmod(path, mode)
+}
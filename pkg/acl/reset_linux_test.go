@@ -0,0 +1,106 @@
+//go:build linux
+
+package acl
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+// rawACL encodes (tag, perm, id) entries as a POSIX ACL extended attribute
+func rawACL(entries ...[3]uint32) []byte {
+	b := binary.LittleEndian.AppendUint32(nil, 2)
+	for _, e := range entries {
+		b = binary.LittleEndian.AppendUint16(b, uint16(e[0]))
+		b = binary.LittleEndian.AppendUint16(b, uint16(e[1]))
+		b = binary.LittleEndian.AppendUint32(b, e[2])
+	}
+	return b
+}
+
+func skipWithoutACLSupport(t *testing.T, dir string) {
+	err := unix.Setxattr(dir, accessACLXattr, rawACL([3]uint32{0x01, 7, ^uint32(0)}, [3]uint32{0x04, 5, ^uint32(0)}, [3]uint32{0x20, 5, ^uint32(0)}), 0)
+	if errors.Is(err, unix.ENOTSUP) {
+		t.Skip("file system does not support POSIX ACLs")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReset(t *testing.T) {
+	dir := t.TempDir()
+	skipWithoutACLSupport(t, dir)
+
+	named := rawACL(
+		[3]uint32{0x01, 6, ^uint32(0)},
+		[3]uint32{0x02, 6, 1000},
+		[3]uint32{0x04, 4, ^uint32(0)},
+		[3]uint32{0x10, 6, ^uint32(0)},
+		[3]uint32{0x20, 0, ^uint32(0)},
+	)
+
+	t.Run("Without a parent default ACL", func(t *testing.T) {
+		f := filepath.Join(dir, "file")
+		assert.NoError(t, os.WriteFile(f, nil, 0600))
+		assert.NoError(t, unix.Setxattr(f, accessACLXattr, named, 0))
+
+		assert.NoError(t, Reset(f))
+
+		acl, err := getxattr(f, accessACLXattr)
+		assert.NoError(t, err)
+		assert.Nil(t, acl)
+		info, err := os.Stat(f)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+	})
+
+	t.Run("With a parent default ACL", func(t *testing.T) {
+		parentDefault := rawACL(
+			[3]uint32{0x01, 7, ^uint32(0)},
+			[3]uint32{0x04, 5, ^uint32(0)},
+			[3]uint32{0x08, 5, 1001},
+			[3]uint32{0x10, 5, ^uint32(0)},
+			[3]uint32{0x20, 0, ^uint32(0)},
+		)
+		assert.NoError(t, unix.Setxattr(dir, defaultACLXattr, parentDefault, 0))
+		sub := filepath.Join(dir, "sub")
+		assert.NoError(t, os.Mkdir(sub, 0700))
+		f := filepath.Join(sub, "file")
+		assert.NoError(t, os.WriteFile(f, nil, 0600))
+		assert.NoError(t, unix.Setxattr(sub, accessACLXattr, named, 0))
+		assert.NoError(t, unix.Setxattr(f, accessACLXattr, named, 0))
+
+		assert.NoError(t, ResetRecursive(sub))
+
+		acl, err := getxattr(sub, accessACLXattr)
+		assert.NoError(t, err)
+		assert.Equal(t, parentDefault, acl)
+		acl, err = getxattr(sub, defaultACLXattr)
+		assert.NoError(t, err)
+		assert.Equal(t, parentDefault, acl)
+
+		// like the files created in the directory, the file does not inherit the execute permission, which the mask
+		// removes from the group class
+		acl, err = getxattr(f, accessACLXattr)
+		assert.NoError(t, err)
+		assert.Equal(t, rawACL(
+			[3]uint32{0x01, 6, ^uint32(0)},
+			[3]uint32{0x04, 5, ^uint32(0)},
+			[3]uint32{0x08, 5, 1001},
+			[3]uint32{0x10, 4, ^uint32(0)},
+			[3]uint32{0x20, 0, ^uint32(0)},
+		), acl)
+		created := filepath.Join(sub, "created")
+		assert.NoError(t, os.WriteFile(created, nil, 0666))
+		createdACL, err := getxattr(created, accessACLXattr)
+		assert.NoError(t, err)
+		assert.Equal(t, createdACL, acl, "a reset file should match a new file")
+	})
+}
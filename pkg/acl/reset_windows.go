@@ -0,0 +1,26 @@
+//go:build windows
+
+package acl
+
+import (
+	"github.com/rancher/permissions/pkg/descriptor"
+	"golang.org/x/sys/windows"
+)
+
+// reset replaces the DACL with an empty, unprotected DACL, so that only the ACEs inherited from the parent apply
+func reset(path string) error {
+	// an empty DACL is different from a nil (NULL) DACL, which would grant full access to everyone
+	dacl, err := toWindowsACL(descriptor.ACL{})
+	if err != nil {
+		return err
+	}
+	return windows.SetNamedSecurityInfo(
+		path,
+		windows.SE_FILE_OBJECT,
+		windows.DACL_SECURITY_INFORMATION|windows.UNPROTECTED_DACL_SECURITY_INFORMATION,
+		nil,
+		nil,
+		dacl,
+		nil,
+	)
+}
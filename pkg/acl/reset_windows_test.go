@@ -0,0 +1,50 @@
+//go:build windows
+
+package acl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/sid"
+	"golang.org/x/sys/windows"
+)
+
+func TestReset(t *testing.T) {
+	dir, err := os.MkdirTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sub := filepath.Join(dir, "sub")
+	f := filepath.Join(sub, "file")
+	err = Mkdir(sub, access.GrantSid(windows.GENERIC_ALL, sid.LocalSystem()), access.GrantSid(windows.GENERIC_ALL, sid.CurrentUser()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(f, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Apply(f, nil, nil, access.GrantSid(windows.GENERIC_ALL, sid.CurrentUser())); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ResetRecursive(sub); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{sub, f} {
+		aces, err := currentDACL(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(aces.Explicit()) != 0 {
+			t.Errorf("expected no explicit ACEs on %s, found %v", p, aces.Explicit())
+		}
+		if len(aces.Inherited()) == 0 {
+			t.Errorf("expected inherited ACEs on %s", p)
+		}
+	}
+}
@@ -5,6 +5,7 @@ package acl
 import (
 	"unsafe"
 
+	"github.com/rancher/permissions/pkg/descriptor"
 	"golang.org/x/sys/windows"
 )
 
@@ -15,6 +16,8 @@ type securityArgs struct {
 	group *windows.SID
 
 	access []windows.EXPLICIT_ACCESS
+
+	options Options
 }
 
 func (a *securityArgs) ToSecurityAttributes() (*windows.SecurityAttributes, error) {
@@ -76,19 +79,116 @@ func (a *securityArgs) ToSecurityInfo() windows.SECURITY_INFORMATION {
 		securityInfo |= windows.GROUP_SECURITY_INFORMATION
 	}
 
-	if len(a.access) != 0 {
+	if a.changesDACL() {
 		// override DACL
 		securityInfo |= windows.DACL_SECURITY_INFORMATION
-		securityInfo |= windows.PROTECTED_DACL_SECURITY_INFORMATION
+		if a.protectsDACL() {
+			securityInfo |= windows.PROTECTED_DACL_SECURITY_INFORMATION
+		} else if a.options.Inheritance == Unprotected {
+			securityInfo |= windows.UNPROTECTED_DACL_SECURITY_INFORMATION
+		}
+	}
+
+	if a.options.Audit != nil {
+		// override SACL
+		securityInfo |= windows.SACL_SECURITY_INFORMATION
 	}
 
 	return securityInfo
 }
 
+func (a *securityArgs) ToSACL() (*windows.ACL, error) {
+	if a.options.Audit == nil {
+		return nil, nil
+	}
+	if len(a.options.Audit) == 0 {
+		// an empty SACL removes every audit rule
+		return toWindowsACL(descriptor.ACL{})
+	}
+	return windows.ACLFromEntries(a.options.Audit, nil)
+}
+
 func (a *securityArgs) ToDACL() (*windows.ACL, error) {
-	if len(a.access) == 0 {
+	if !a.changesDACL() {
 		// No rules were specified
 		return nil, nil
 	}
-	return windows.ACLFromEntries(a.access, nil)
+
+	var aces descriptor.ACL
+	if a.options.Mode.replacesDACL() && len(a.access) != 0 {
+		dacl, err := windows.ACLFromEntries(a.access, nil)
+		if err != nil {
+			return nil, err
+		}
+		if !a.options.Canonicalize && a.options.Inheritance != ConvertInherited {
+			return dacl, nil
+		}
+		aces, err = fromWindowsACL(dacl)
+		if err != nil {
+			return nil, err
+		}
+		if a.options.Inheritance == ConvertInherited {
+			current, err := currentDACL(a.path)
+			if err != nil {
+				return nil, err
+			}
+			aces = append(aces, current.Inherited()...)
+		}
+	} else {
+		// when no rules are provided, only the inheritance of the DACL changes and the explicit ACEs are kept
+		mode := a.options.Mode
+		if mode.replacesDACL() {
+			mode = Merge
+		}
+		var err error
+		aces, err = mergeDACL(a.path, mode, a.access)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch a.options.Inheritance {
+	case Protected, Unprotected:
+		// inherited ACEs are either removed or recomputed from the parent when the DACL is written
+		aces = aces.Explicit()
+	case ConvertInherited:
+		for i := range aces {
+			aces[i].Flags &^= descriptor.Inherited
+		}
+	}
+	if a.options.Canonicalize {
+		aces = aces.Canonicalize()
+	}
+	return toWindowsACL(aces)
+}
+
+// changesDACL returns true if the DACL of the path needs to be written
+func (a *securityArgs) changesDACL() bool {
+	return len(a.access) != 0 || a.options.Inheritance != DefaultInheritance
+}
+
+// protectsDACL returns true if the written DACL should not inherit ACEs from the parent
+func (a *securityArgs) protectsDACL() bool {
+	switch a.options.Inheritance {
+	case Protected, ConvertInherited:
+		return true
+	case Unprotected:
+		return false
+	default:
+		return a.options.Mode.replacesDACL()
+	}
+}
+
+// appliesOnCreate returns true if the DACL can be set when the object is created, which is only the case when it is
+// entirely made of the provided rules
+func (a *securityArgs) appliesOnCreate() bool {
+	if a.options.Audit != nil {
+		// the SACL requires privileges to be enabled, so it is written after the object is created
+		return false
+	}
+	if len(a.access) == 0 {
+		// the object simply inherits parent rules
+		return a.options.Inheritance == DefaultInheritance
+	}
+	return a.options.Mode.replacesDACL() && a.protectsDACL() && a.options.Inheritance != ConvertInherited
 }
@@ -0,0 +1,75 @@
+//go:build linux
+
+package acl
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/posixacl"
+)
+
+// Set writes the parts of the security descriptor selected by info to the file / directory.
+//
+// On Linux, the owner and group must be S-1-22-1-<uid> and S-1-22-2-<gid> SIDs, as returned by Get, and the DACL
+// must be representable by POSIX ACLs. The ACEs that apply to the path replace its access ACL (see posixacl.FromDACL),
+// and the inheritable ACEs of a directory replace its default ACL (see posixacl.DefaultFromDACL), which is removed if
+// there are none. The inheritable ACEs of a file are ignored. The DACL must be marked with descriptor.DACLPresent when
+// it is written. A NULL DACL grants full access to everyone, and is written as the 0777 mode. SACLs and mandatory
+// labels do not exist on Linux, so they cannot be written.
+func Set(path string, sd *descriptor.SecurityDescriptor, info descriptor.Information) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	if info&descriptor.DACLInformation != 0 && sd.Control&descriptor.DACLPresent == 0 {
+		return fmt.Errorf("the DACL is selected, but descriptor.DACLPresent is not set")
+	}
+	if info&(descriptor.SACLInformation|descriptor.LabelInformation) != 0 {
+		return fmt.Errorf("SACLs and mandatory labels are not supported on Linux")
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	sys := stat.Sys().(*syscall.Stat_t)
+	uid, gid := -1, -1
+	owner, group := descriptor.UnixUserSID(sys.Uid), descriptor.UnixGroupSID(sys.Gid)
+	if info&descriptor.OwnerInformation != 0 {
+		id, ok := sd.Owner.UnixUser()
+		if !ok {
+			return fmt.Errorf("invalid owner %s: expected a Unix user SID (S-1-22-1-<uid>)", sd.Owner)
+		}
+		uid, owner = int(id), sd.Owner
+	}
+	if info&descriptor.GroupInformation != 0 {
+		id, ok := sd.Group.UnixGroup()
+		if !ok {
+			return fmt.Errorf("invalid group %s: expected a Unix group SID (S-1-22-2-<gid>)", sd.Group)
+		}
+		gid, group = int(id), sd.Group
+	}
+	if uid != -1 || gid != -1 {
+		if err := os.Chown(path, uid, gid); err != nil {
+			return err
+		}
+	}
+	if info&descriptor.DACLInformation == 0 {
+		return nil
+	}
+
+	access := posixacl.FromMode(0777)
+	var defaults posixacl.ACL
+	if sd.DACL != nil {
+		if access, err = posixacl.FromDACL(sd.DACL, owner, group); err != nil {
+			return fmt.Errorf("invalid DACL: %w", err)
+		}
+		if stat.IsDir() {
+			if defaults, err = posixacl.DefaultFromDACL(sd.DACL, owner, group); err != nil {
+				return fmt.Errorf("invalid DACL: %w", err)
+			}
+		}
+	}
+	return SetPOSIX(path, access, defaults)
+}
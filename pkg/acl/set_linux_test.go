@@ -0,0 +1,43 @@
+//go:build linux
+
+package acl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/filemode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSet(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(f, nil, 0600))
+	owner := descriptor.UnixUserSID(uint32(os.Getuid()))
+	group := descriptor.UnixGroupSID(uint32(os.Getgid()))
+
+	sd := &descriptor.SecurityDescriptor{
+		Owner:   owner,
+		Group:   group,
+		Control: descriptor.DACLPresent,
+		DACL:    filemode.ToDACL(0751, owner, group),
+	}
+	require.NoError(t, Set(f, sd, descriptor.DefaultInformation))
+	info, err := os.Stat(f)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0751), info.Mode().Perm())
+
+	require.NoError(t, Set(f, &descriptor.SecurityDescriptor{Control: descriptor.DACLPresent}, descriptor.DACLInformation))
+	info, err = os.Stat(f)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0777), info.Mode().Perm())
+
+	sd.DACL = append(sd.DACL, descriptor.ACE{Type: descriptor.AccessDenied, Mask: 0x10000, SID: descriptor.Everyone})
+	assert.Error(t, Set(f, sd, descriptor.DACLInformation))
+	assert.Error(t, Set(f, &descriptor.SecurityDescriptor{}, descriptor.DACLInformation), "a DACL that is not present should not be written")
+	assert.Error(t, Set(f, &descriptor.SecurityDescriptor{Owner: descriptor.BuiltinAdministrators}, descriptor.OwnerInformation))
+	assert.Error(t, Set(f, sd, descriptor.SACLInformation))
+}
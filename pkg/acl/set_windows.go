@@ -0,0 +1,82 @@
+//go:build windows
+
+package acl
+
+import (
+	"fmt"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"golang.org/x/sys/windows"
+)
+
+// Set writes the parts of the security descriptor selected by info to the file / directory.
+//
+// The DACL (and SACL) is protected from inheritance if descriptor.DACLProtected (and descriptor.SACLProtected) is set
+// in the control flags of the security descriptor. Otherwise, the inherited ACEs it contains are discarded and
+// recomputed from the parent of the path. The DACL must be marked with descriptor.DACLPresent when it is written; a nil
+// DACL is then written as a NULL DACL, which grants full access to everyone.
+//
+// Writing the SACL requires the SeSecurityPrivilege, which is enabled for the duration of the call. If the caller does
+// not hold it, a *PrivilegeError is returned. The SeRestorePrivilege and SeTakeOwnershipPrivilege are enabled if the
+// caller holds them when the owner is written.
+func Set(path string, sd *descriptor.SecurityDescriptor, info descriptor.Information) error {
+	if path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	if info&descriptor.DACLInformation != 0 && sd.Control&descriptor.DACLPresent == 0 {
+		return fmt.Errorf("the DACL is selected, but descriptor.DACLPresent is not set")
+	}
+	securityInfo := windows.SECURITY_INFORMATION(info)
+
+	var owner, group *windows.SID
+	var err error
+	if info&descriptor.OwnerInformation != 0 {
+		if owner, err = windows.StringToSid(sd.Owner.String()); err != nil {
+			return fmt.Errorf("invalid owner %s: %w", sd.Owner, err)
+		}
+	}
+	if info&descriptor.GroupInformation != 0 {
+		if group, err = windows.StringToSid(sd.Group.String()); err != nil {
+			return fmt.Errorf("invalid group %s: %w", sd.Group, err)
+		}
+	}
+
+	var dacl *windows.ACL
+	if info&descriptor.DACLInformation != 0 {
+		// a nil DACL is written as a NULL DACL, which grants full access to everyone
+		if sd.DACL != nil {
+			if dacl, err = toWindowsACL(sd.DACL); err != nil {
+				return err
+			}
+		}
+		if sd.Control&descriptor.DACLProtected != 0 {
+			securityInfo |= windows.PROTECTED_DACL_SECURITY_INFORMATION
+		} else {
+			securityInfo |= windows.UNPROTECTED_DACL_SECURITY_INFORMATION
+		}
+	}
+
+	var sacl *windows.ACL
+	if info&(descriptor.SACLInformation|descriptor.LabelInformation) != 0 {
+		// audit ACEs and mandatory labels are both stored in the SACL, but they are written independently
+		var aces descriptor.ACL
+		for _, ace := range sd.SACL {
+			isLabel := ace.Type == descriptor.SystemMandatoryLabel
+			if (isLabel && info&descriptor.LabelInformation != 0) || (!isLabel && info&descriptor.SACLInformation != 0) {
+				aces = append(aces, ace)
+			}
+		}
+		if sacl, err = toWindowsACL(aces); err != nil {
+			return err
+		}
+		if info&descriptor.SACLInformation != 0 {
+			if sd.Control&descriptor.SACLProtected != 0 {
+				securityInfo |= windows.PROTECTED_SACL_SECURITY_INFORMATION
+			} else {
+				securityInfo |= windows.UNPROTECTED_SACL_SECURITY_INFORMATION
+			}
+		}
+	}
+
+	return setNamedSecurityInfo(path, securityInfo, owner, group, dacl, sacl)
+}
@@ -0,0 +1,195 @@
+package acl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// Resolver expands the members of groups, so that reports can list the accounts that get rights through a group
+type Resolver interface {
+	// Members returns the SIDs of the direct members of the group, which may be groups themselves. It returns no
+	// members if the SID is not a group.
+	Members(group descriptor.SID) ([]descriptor.SID, error)
+}
+
+// StaticResolver is a Resolver backed by a map from group SIDs to the SIDs of their direct members
+type StaticResolver map[descriptor.SID][]descriptor.SID
+
+// Members implements Resolver
+func (r StaticResolver) Members(group descriptor.SID) ([]descriptor.SID, error) {
+	return r[group], nil
+}
+
+// broadWriteRights are the rights that should not be granted to broad groups such as Everyone
+const broadWriteRights = access.Write | access.Delete | access.DeleteSubdirectoriesAndFiles | access.ChangePermissions |
+	access.TakeOwnership
+
+// defaultBroadTrustees are the groups that nearly every account belongs to
+var defaultBroadTrustees = []descriptor.SID{descriptor.Everyone, descriptor.AuthenticatedUsers, descriptor.BuiltinUsers}
+
+// ReportOptions controls how access reports are built
+type ReportOptions struct {
+	// Resolver expands the members of the groups found in the DACL. If nil, groups are not expanded.
+	Resolver Resolver
+	// BroadTrustees are the SIDs that are flagged when they are granted write access. Defaults to Everyone,
+	// Authenticated Users and Users.
+	BroadTrustees []descriptor.SID
+}
+
+// TrusteeAccess holds the effective rights of a trustee
+type TrusteeAccess struct {
+	SID descriptor.SID `json:"sid"`
+	// Via lists the trustees of the report that the trustee is a member of, as found by expanding group membership
+	Via []descriptor.SID `json:"via,omitempty"`
+	// Rights holds the effective rights of the trustee, as returned by access.Check
+	Rights uint32 `json:"rights"`
+}
+
+// Finding is a grant flagged by an access report
+type Finding struct {
+	SID     descriptor.SID `json:"sid"`
+	Rights  uint32         `json:"rights"`
+	Message string         `json:"message"`
+}
+
+// AccessReport lists who has access to a file / directory
+type AccessReport struct {
+	Path     string          `json:"path"`
+	Owner    descriptor.SID  `json:"owner,omitempty"`
+	Trustees []TrusteeAccess `json:"trustees"`
+	Findings []Finding       `json:"findings,omitempty"`
+}
+
+func (r *AccessReport) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s (owner %s)\n", r.Path, r.Owner)
+	for _, trustee := range r.Trustees {
+		fmt.Fprintf(&sb, "  %s", trustee.SID)
+		if len(trustee.Via) > 0 {
+			via := make([]string, len(trustee.Via))
+			for i, group := range trustee.Via {
+				via[i] = group.String()
+			}
+			fmt.Fprintf(&sb, " (via %s)", strings.Join(via, ", "))
+		}
+		fmt.Fprintf(&sb, ": %s\n", access.FileRightsString(trustee.Rights))
+	}
+	for _, finding := range r.Findings {
+		fmt.Fprintf(&sb, "  ! %s\n", finding.Message)
+	}
+	return sb.String()
+}
+
+// ReportDescriptor builds an access report from a security descriptor that has been read with Get. The path of the
+// report is left empty.
+//
+// The effective rights of each trustee are computed with access.Check, for a token that holds the SID of the trustee,
+// the groups of the DACL it is a member of and the Everyone and Authenticated Users groups, which every account
+// belongs to. The owner and the broad trustees are always evaluated, and are listed if they have any rights.
+// Placeholder SIDs such as Creator Owner, which are only meaningful in inheritable ACEs, are not listed.
+func ReportDescriptor(sd *descriptor.SecurityDescriptor, opts ReportOptions) (*AccessReport, error) {
+	broadTrustees := opts.BroadTrustees
+	if broadTrustees == nil {
+		broadTrustees = defaultBroadTrustees
+	}
+
+	var trustees []descriptor.SID
+	seen := make(map[descriptor.SID]bool)
+	add := func(sid descriptor.SID) {
+		if sid == "" || seen[sid] || isPlaceholderSID(sid) {
+			return
+		}
+		seen[sid] = true
+		trustees = append(trustees, sid)
+	}
+	if sd.Owner != "" {
+		add(sd.Owner)
+	}
+	for _, ace := range sd.DACL {
+		if ace.AppliesToObject() && (ace.Type == descriptor.AccessAllowed || ace.Type == descriptor.AccessDenied) {
+			add(ace.SID)
+		}
+	}
+	for _, sid := range broadTrustees {
+		add(sid)
+	}
+
+	memberOf := make(map[descriptor.SID][]descriptor.SID)
+	if opts.Resolver != nil {
+		groups := trustees
+		for _, group := range groups {
+			members, err := expandMembers(opts.Resolver, group)
+			if err != nil {
+				return nil, err
+			}
+			for _, member := range members {
+				memberOf[member] = append(memberOf[member], group)
+				add(member)
+			}
+		}
+	}
+
+	report := &AccessReport{Owner: sd.Owner}
+	for _, sid := range trustees {
+		token := access.Token{User: sid, Groups: append(implicitGroups(sid), memberOf[sid]...)}
+		rights := access.Check(sd, token, access.FileGenericMapping.All).Granted
+		if rights == 0 {
+			continue
+		}
+		report.Trustees = append(report.Trustees, TrusteeAccess{SID: sid, Via: memberOf[sid], Rights: rights})
+		for _, broad := range broadTrustees {
+			if sid == broad && rights&broadWriteRights != 0 {
+				report.Findings = append(report.Findings, Finding{
+					SID:     sid,
+					Rights:  rights & broadWriteRights,
+					Message: fmt.Sprintf("%s has write access: %s", sid, access.FileRightsString(rights&broadWriteRights)),
+				})
+			}
+		}
+	}
+	return report, nil
+}
+
+// expandMembers returns the direct and nested members of the group
+func expandMembers(resolver Resolver, group descriptor.SID) ([]descriptor.SID, error) {
+	var members []descriptor.SID
+	visited := map[descriptor.SID]bool{group: true}
+	queue := []descriptor.SID{group}
+	for len(queue) > 0 {
+		direct, err := resolver.Members(queue[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve the members of %s: %w", queue[0], err)
+		}
+		queue = queue[1:]
+		for _, member := range direct {
+			if visited[member] {
+				continue
+			}
+			visited[member] = true
+			members = append(members, member)
+			queue = append(queue, member)
+		}
+	}
+	return members, nil
+}
+
+// implicitGroups returns the well-known groups that the token of any account holds
+func implicitGroups(sid descriptor.SID) []descriptor.SID {
+	switch sid {
+	case descriptor.Everyone:
+		return nil
+	case descriptor.AuthenticatedUsers:
+		return []descriptor.SID{descriptor.Everyone}
+	default:
+		return []descriptor.SID{descriptor.Everyone, descriptor.AuthenticatedUsers}
+	}
+}
+
+// isPlaceholderSID returns true for the SIDs that are replaced when an ACE is inherited, and for Owner Rights, which
+// applies to whoever owns the object
+func isPlaceholderSID(sid descriptor.SID) bool {
+	return sid == descriptor.CreatorOwner || sid == descriptor.CreatorGroup || sid == descriptor.OwnerRights
+}
@@ -0,0 +1,79 @@
+package acl
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportDescriptor(t *testing.T) {
+	alice := descriptor.MustParseSID("S-1-5-21-1-2-3-1001")
+	bob := descriptor.MustParseSID("S-1-5-21-1-2-3-1002")
+	operators := descriptor.MustParseSID("S-1-5-21-1-2-3-2000")
+
+	sd := &descriptor.SecurityDescriptor{
+		Owner:   descriptor.BuiltinAdministrators,
+		Control: descriptor.DACLPresent,
+		DACL: descriptor.ACL{
+			{Type: descriptor.AccessDenied, Mask: access.Delete, SID: bob},
+			{Type: descriptor.AccessAllowed, Mask: access.FullControl, SID: descriptor.BuiltinAdministrators},
+			{Type: descriptor.AccessAllowed, Mask: access.Modify, SID: operators},
+			{Type: descriptor.AccessAllowed, Mask: access.ReadAndExecute | access.Write, SID: descriptor.BuiltinUsers},
+			{Type: descriptor.AccessAllowed, Flags: descriptor.ContainerInherit | descriptor.InheritOnly, Mask: access.GenericAll, SID: descriptor.CreatorOwner},
+		},
+	}
+
+	t.Run("Without resolver", func(t *testing.T) {
+		report, err := ReportDescriptor(sd, ReportOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []TrusteeAccess{
+			{SID: descriptor.BuiltinAdministrators, Rights: access.FullControl},
+			{SID: operators, Rights: access.Modify},
+			{SID: descriptor.BuiltinUsers, Rights: access.ReadAndExecute | access.Write},
+		}, report.Trustees)
+		assert.Equal(t, []Finding{
+			{SID: descriptor.BuiltinUsers, Rights: access.Write, Message: "S-1-5-32-545 has write access: Write"},
+		}, report.Findings)
+	})
+
+	t.Run("With resolver", func(t *testing.T) {
+		resolver := StaticResolver{
+			operators:                     {alice, bob},
+			descriptor.BuiltinUsers:       {operators},
+			descriptor.AuthenticatedUsers: {descriptor.BuiltinUsers},
+		}
+		report, err := ReportDescriptor(sd, ReportOptions{Resolver: resolver, BroadTrustees: []descriptor.SID{descriptor.Everyone}})
+		require.NoError(t, err)
+		assert.Equal(t, []TrusteeAccess{
+			{SID: descriptor.BuiltinAdministrators, Rights: access.FullControl},
+			{SID: bob, Via: []descriptor.SID{operators, descriptor.BuiltinUsers}, Rights: access.Modify &^ access.Delete},
+			{SID: operators, Via: []descriptor.SID{descriptor.BuiltinUsers}, Rights: access.Modify},
+			{SID: descriptor.BuiltinUsers, Rights: access.ReadAndExecute | access.Write},
+			{SID: alice, Via: []descriptor.SID{operators, descriptor.BuiltinUsers}, Rights: access.Modify},
+		}, report.Trustees)
+		assert.Empty(t, report.Findings)
+	})
+
+	t.Run("NULL DACL", func(t *testing.T) {
+		report, err := ReportDescriptor(&descriptor.SecurityDescriptor{Control: descriptor.DACLPresent}, ReportOptions{})
+		require.NoError(t, err)
+		require.Len(t, report.Findings, 3)
+		assert.Equal(t, descriptor.Everyone, report.Findings[0].SID)
+		assert.Equal(t, broadWriteRights, report.Findings[0].Rights)
+	})
+
+	t.Run("Resolver error", func(t *testing.T) {
+		_, err := ReportDescriptor(sd, ReportOptions{Resolver: failingResolver{}})
+		assert.Error(t, err)
+	})
+}
+
+type failingResolver struct{}
+
+func (failingResolver) Members(descriptor.SID) ([]descriptor.SID, error) {
+	return nil, errors.New("domain controller unreachable")
+}
@@ -0,0 +1,64 @@
+//go:build linux
+
+package acl
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/rancher/permissions/pkg/posixacl"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	accessACLXattr  = posixacl.AccessXattr
+	defaultACLXattr = posixacl.DefaultXattr
+)
+
+// getxattr returns the value of the extended attribute, or nil if it is not set
+func getxattr(path, name string) ([]byte, error) {
+	for {
+		size, err := unix.Getxattr(path, name, nil)
+		if err != nil {
+			if isNoXattr(err) {
+				return nil, nil
+			}
+			return nil, &fs.PathError{Op: "getxattr", Path: path, Err: err}
+		}
+		buf := make([]byte, size)
+		n, err := unix.Getxattr(path, name, buf)
+		if errors.Is(err, unix.ERANGE) {
+			// the attribute grew between both calls
+			continue
+		}
+		if err != nil {
+			if isNoXattr(err) {
+				return nil, nil
+			}
+			return nil, &fs.PathError{Op: "getxattr", Path: path, Err: err}
+		}
+		return buf[:n], nil
+	}
+}
+
+// setxattr sets the value of the extended attribute
+func setxattr(path, name string, value []byte) error {
+	if err := unix.Setxattr(path, name, value, 0); err != nil {
+		return &fs.PathError{Op: "setxattr", Path: path, Err: err}
+	}
+	return nil
+}
+
+// removexattr removes the extended attribute. It is not an error if the attribute is not set.
+func removexattr(path, name string) error {
+	if err := unix.Removexattr(path, name); err != nil && !isNoXattr(err) {
+		return &fs.PathError{Op: "removexattr", Path: path, Err: err}
+	}
+	return nil
+}
+
+// isNoXattr returns true if the error indicates that the attribute is not set, or that the file system does not
+// support it (in which case it cannot be set either)
+func isNoXattr(err error) bool {
+	return errors.Is(err, unix.ENODATA) || errors.Is(err, unix.ENOTSUP)
+}
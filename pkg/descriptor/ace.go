@@ -0,0 +1,218 @@
+package descriptor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ACEType is the type of an access control entry.
+type ACEType uint8
+
+// Supported ACE types. These have the same values as the Windows ACE_HEADER AceType field.
+const (
+	AccessAllowed        ACEType = 0x00
+	AccessDenied         ACEType = 0x01
+	SystemAudit          ACEType = 0x02
+	SystemMandatoryLabel ACEType = 0x11
+)
+
+// ACEFlags holds the inheritance and audit flags of an access control entry.
+type ACEFlags uint8
+
+// ACE flags. These have the same values as the Windows ACE_HEADER AceFlags field.
+const (
+	ObjectInherit      ACEFlags = 0x01
+	ContainerInherit   ACEFlags = 0x02
+	NoPropagateInherit ACEFlags = 0x04
+	InheritOnly        ACEFlags = 0x08
+	Inherited          ACEFlags = 0x10
+	SuccessfulAccess   ACEFlags = 0x40
+	FailedAccess       ACEFlags = 0x80
+
+	// InheritanceFlags is the set of flags that control how an ACE is inherited by children
+	InheritanceFlags = ObjectInherit | ContainerInherit | NoPropagateInherit | InheritOnly
+	// AuditFlags is the set of flags that control when an audit ACE generates an audit record
+	AuditFlags = SuccessfulAccess | FailedAccess
+)
+
+const aceHeaderLength = 8
+
+// ACE is an access control entry, which grants, denies or audits the rights in Mask for the trustee identified by SID.
+type ACE struct {
+	Type  ACEType  `json:"type"`
+	Flags ACEFlags `json:"flags,omitempty"`
+	Mask  uint32   `json:"mask"`
+	SID   SID      `json:"sid"`
+}
+
+// IsInherited returns true if the ACE was inherited from a parent object
+func (a ACE) IsInherited() bool {
+	return a.Flags&Inherited != 0
+}
+
+// IsInheritable returns true if the ACE will be inherited by the children of a container
+func (a ACE) IsInheritable() bool {
+	return a.Flags&(ObjectInherit|ContainerInherit) != 0
+}
+
+// AppliesToObject returns true if the ACE is used in access checks against the object it is attached to
+func (a ACE) AppliesToObject() bool {
+	return a.Flags&InheritOnly == 0
+}
+
+func (a ACE) String() string {
+	return fmt.Sprintf("%s;%s;%#08x;%s", a.Type, a.Flags, a.Mask, a.SID)
+}
+
+func (t ACEType) String() string {
+	switch t {
+	case AccessAllowed:
+		return "Allow"
+	case AccessDenied:
+		return "Deny"
+	case SystemAudit:
+		return "Audit"
+	case SystemMandatoryLabel:
+		return "Label"
+	default:
+		return fmt.Sprintf("ACEType(%#02x)", uint8(t))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (t ACEType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (t *ACEType) UnmarshalText(text []byte) error {
+	for _, aceType := range []ACEType{AccessAllowed, AccessDenied, SystemAudit, SystemMandatoryLabel} {
+		if strings.EqualFold(aceType.String(), string(text)) {
+			*t = aceType
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown ACE type %q", string(text))
+}
+
+// aceFlagNames are the SDDL abbreviations of the ACE flags
+var aceFlagNames = []struct {
+	flag ACEFlags
+	name string
+}{
+	{ObjectInherit, "OI"},
+	{ContainerInherit, "CI"},
+	{NoPropagateInherit, "NP"},
+	{InheritOnly, "IO"},
+	{Inherited, "ID"},
+	{SuccessfulAccess, "SA"},
+	{FailedAccess, "FA"},
+}
+
+// String returns the SDDL abbreviations of the flags (e.g. "OICIID")
+func (f ACEFlags) String() string {
+	var sb strings.Builder
+	for _, flag := range aceFlagNames {
+		if f&flag.flag != 0 {
+			sb.WriteString(flag.name)
+		}
+	}
+	if rest := f &^ (InheritanceFlags | Inherited | AuditFlags); rest != 0 {
+		fmt.Fprintf(&sb, "%#02x", uint8(rest))
+	}
+	return sb.String()
+}
+
+// ParseACEFlags parses the SDDL abbreviations of ACE flags, as returned by ACEFlags.String, which may end with the
+// hexadecimal value of the flags that have no abbreviation (e.g. "OICI0x20")
+func ParseACEFlags(s string) (ACEFlags, error) {
+	var flags ACEFlags
+	for i := 0; i < len(s); i += 2 {
+		if rest := s[i:]; len(rest) > 2 && rest[0] == '0' && (rest[1] == 'x' || rest[1] == 'X') {
+			v, err := strconv.ParseUint(rest[2:], 16, 8)
+			if err != nil {
+				return 0, fmt.Errorf("invalid ACE flags %q: invalid value %q", s, rest)
+			}
+			return flags | ACEFlags(v), nil
+		}
+		if i+2 > len(s) {
+			return 0, fmt.Errorf("invalid ACE flags %q", s)
+		}
+		found := false
+		for _, flag := range aceFlagNames {
+			if strings.EqualFold(flag.name, s[i:i+2]) {
+				flags |= flag.flag
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("invalid ACE flags %q: unknown flag %q", s, s[i:i+2])
+		}
+	}
+	return flags, nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (f ACEFlags) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (f *ACEFlags) UnmarshalText(text []byte) error {
+	flags, err := ParseACEFlags(string(text))
+	if err != nil {
+		return err
+	}
+	*f = flags
+	return nil
+}
+
+// MarshalBinary returns the ACE in the binary format used by Windows ACLs
+func (a ACE) MarshalBinary() ([]byte, error) {
+	switch a.Type {
+	case AccessAllowed, AccessDenied, SystemAudit, SystemMandatoryLabel:
+	default:
+		return nil, fmt.Errorf("unsupported ACE type %s", a.Type)
+	}
+	sid, err := a.SID.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, aceHeaderLength+len(sid))
+	b[0] = byte(a.Type)
+	b[1] = byte(a.Flags)
+	binary.LittleEndian.PutUint16(b[2:], uint16(len(b)))
+	binary.LittleEndian.PutUint32(b[4:], a.Mask)
+	copy(b[aceHeaderLength:], sid)
+	return b, nil
+}
+
+// parseACE decodes the ACE at the start of b. It returns the ACE and the number of bytes read.
+func parseACE(b []byte) (ACE, int, error) {
+	if len(b) < aceHeaderLength {
+		return ACE{}, 0, fmt.Errorf("ACE is too short: %d bytes", len(b))
+	}
+	size := int(binary.LittleEndian.Uint16(b[2:]))
+	if size < aceHeaderLength || size > len(b) {
+		return ACE{}, 0, fmt.Errorf("invalid ACE size %d", size)
+	}
+	a := ACE{
+		Type:  ACEType(b[0]),
+		Flags: ACEFlags(b[1]),
+		Mask:  binary.LittleEndian.Uint32(b[4:]),
+	}
+	switch a.Type {
+	case AccessAllowed, AccessDenied, SystemAudit, SystemMandatoryLabel:
+	default:
+		return ACE{}, 0, fmt.Errorf("unsupported ACE type %s", a.Type)
+	}
+	sid, _, err := SIDFromBytes(b[aceHeaderLength:size])
+	if err != nil {
+		return ACE{}, 0, err
+	}
+	a.SID = sid
+	return a, size, nil
+}
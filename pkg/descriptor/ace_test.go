@@ -0,0 +1,44 @@
+package descriptor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestACEFlags(t *testing.T) {
+	flags := ObjectInherit | ContainerInherit | Inherited
+	assert.Equal(t, "OICIID", flags.String())
+	parsed, err := ParseACEFlags("oiciID")
+	assert.NoError(t, err)
+	assert.Equal(t, flags, parsed)
+
+	empty, err := ParseACEFlags("")
+	assert.NoError(t, err)
+	assert.Equal(t, ACEFlags(0), empty)
+
+	unknown := ObjectInherit | 0x20
+	assert.Equal(t, "OI0x20", unknown.String())
+	parsed, err = ParseACEFlags(unknown.String())
+	assert.NoError(t, err)
+	assert.Equal(t, unknown, parsed)
+
+	_, err = ParseACEFlags("OIX")
+	assert.Error(t, err)
+	_, err = ParseACEFlags("XX")
+	assert.Error(t, err)
+	_, err = ParseACEFlags("0x100")
+	assert.Error(t, err)
+}
+
+func TestACETypeText(t *testing.T) {
+	for _, aceType := range []ACEType{AccessAllowed, AccessDenied, SystemAudit, SystemMandatoryLabel} {
+		text, err := aceType.MarshalText()
+		assert.NoError(t, err)
+		var parsed ACEType
+		assert.NoError(t, parsed.UnmarshalText(text))
+		assert.Equal(t, aceType, parsed)
+	}
+	var parsed ACEType
+	assert.Error(t, parsed.UnmarshalText([]byte("Grant")))
+}
@@ -0,0 +1,82 @@
+package descriptor
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	aclRevision     = 2
+	aclRevisionDS   = 4
+	aclHeaderLength = 8
+)
+
+// ACL is an ordered list of access control entries
+type ACL []ACE
+
+// ParseACL decodes an ACL in the binary format used by Windows
+func ParseACL(b []byte) (ACL, error) {
+	if len(b) < aclHeaderLength {
+		return nil, fmt.Errorf("ACL is too short: %d bytes", len(b))
+	}
+	if b[0] != aclRevision && b[0] != aclRevisionDS {
+		return nil, fmt.Errorf("unsupported ACL revision %d", b[0])
+	}
+	size := int(binary.LittleEndian.Uint16(b[2:]))
+	if size < aclHeaderLength || size > len(b) {
+		return nil, fmt.Errorf("invalid ACL size %d", size)
+	}
+	count := int(binary.LittleEndian.Uint16(b[4:]))
+	acl := make(ACL, 0, count)
+	offset := aclHeaderLength
+	for i := 0; i < count; i++ {
+		ace, n, err := parseACE(b[offset:size])
+		if err != nil {
+			return nil, fmt.Errorf("ACE %d: %w", i, err)
+		}
+		acl = append(acl, ace)
+		offset += n
+	}
+	return acl, nil
+}
+
+// MarshalBinary returns the ACL in the binary format used by Windows
+func (acl ACL) MarshalBinary() ([]byte, error) {
+	b := make([]byte, aclHeaderLength)
+	for i, ace := range acl {
+		aceBytes, err := ace.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("ACE %d: %w", i, err)
+		}
+		b = append(b, aceBytes...)
+	}
+	if len(b) > 0xFFFF {
+		return nil, fmt.Errorf("ACL is too large: %d bytes", len(b))
+	}
+	b[0] = aclRevision
+	binary.LittleEndian.PutUint16(b[2:], uint16(len(b)))
+	binary.LittleEndian.PutUint16(b[4:], uint16(len(acl)))
+	return b, nil
+}
+
+// Explicit returns the ACEs that were not inherited from a parent object
+func (acl ACL) Explicit() ACL {
+	var explicit ACL
+	for _, ace := range acl {
+		if !ace.IsInherited() {
+			explicit = append(explicit, ace)
+		}
+	}
+	return explicit
+}
+
+// Inherited returns the ACEs that were inherited from a parent object
+func (acl ACL) Inherited() ACL {
+	var inherited ACL
+	for _, ace := range acl {
+		if ace.IsInherited() {
+			inherited = append(inherited, ace)
+		}
+	}
+	return inherited
+}
@@ -0,0 +1,113 @@
+package descriptor
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	sdRevision     = 1
+	sdHeaderLength = 20
+)
+
+// ParseSecurityDescriptor decodes a security descriptor in the self-relative binary format used by Windows (e.g. as
+// returned by GetFileSecurity, or stored in the security.NTACL extended attribute by Samba). The SelfRelative flag is
+// cleared from the control flags of the result.
+func ParseSecurityDescriptor(b []byte) (*SecurityDescriptor, error) {
+	if len(b) < sdHeaderLength {
+		return nil, fmt.Errorf("security descriptor is too short: %d bytes", len(b))
+	}
+	if b[0] != sdRevision {
+		return nil, fmt.Errorf("unsupported security descriptor revision %d", b[0])
+	}
+	control := Control(binary.LittleEndian.Uint16(b[2:]))
+	if control&SelfRelative == 0 {
+		return nil, fmt.Errorf("security descriptor is not self-relative")
+	}
+	sd := &SecurityDescriptor{Control: control &^ SelfRelative}
+
+	// offset returns the part of b that starts at the offset stored at position i of the header, or nil if the offset
+	// is zero
+	offset := func(i int, name string) ([]byte, error) {
+		off := binary.LittleEndian.Uint32(b[i:])
+		if off == 0 {
+			return nil, nil
+		}
+		if off < sdHeaderLength || uint64(off) >= uint64(len(b)) {
+			return nil, fmt.Errorf("invalid %s offset %d", name, off)
+		}
+		return b[off:], nil
+	}
+	var err error
+	var data [4][]byte
+	for i, name := range []string{"owner", "group", "SACL", "DACL"} {
+		if data[i], err = offset(4+4*i, name); err != nil {
+			return nil, err
+		}
+	}
+	if data[0] != nil {
+		if sd.Owner, _, err = SIDFromBytes(data[0]); err != nil {
+			return nil, fmt.Errorf("owner: %w", err)
+		}
+	}
+	if data[1] != nil {
+		if sd.Group, _, err = SIDFromBytes(data[1]); err != nil {
+			return nil, fmt.Errorf("group: %w", err)
+		}
+	}
+	// a present ACL without an offset is a NULL ACL
+	if control&SACLPresent != 0 && data[2] != nil {
+		if sd.SACL, err = ParseACL(data[2]); err != nil {
+			return nil, fmt.Errorf("SACL: %w", err)
+		}
+	}
+	if control&DACLPresent != 0 && data[3] != nil {
+		if sd.DACL, err = ParseACL(data[3]); err != nil {
+			return nil, fmt.Errorf("DACL: %w", err)
+		}
+	}
+	return sd, nil
+}
+
+// MarshalBinary returns the security descriptor in the self-relative binary format used by Windows. The owner,
+// group, SACL and DACL follow the header in that order, as Samba writes them.
+func (sd *SecurityDescriptor) MarshalBinary() ([]byte, error) {
+	b := make([]byte, sdHeaderLength)
+	b[0] = sdRevision
+	binary.LittleEndian.PutUint16(b[2:], uint16(sd.Control|SelfRelative))
+
+	// add appends data and stores its offset at position i of the header
+	add := func(i int, data []byte) {
+		binary.LittleEndian.PutUint32(b[i:], uint32(len(b)))
+		b = append(b, data...)
+	}
+	for i, sid := range []SID{sd.Owner, sd.Group} {
+		if sid == "" {
+			continue
+		}
+		data, err := sid.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		add(4+4*i, data)
+	}
+	acls := []struct {
+		present Control
+		acl     ACL
+		name    string
+	}{
+		{SACLPresent, sd.SACL, "SACL"},
+		{DACLPresent, sd.DACL, "DACL"},
+	}
+	for i, acl := range acls {
+		if sd.Control&acl.present == 0 || acl.acl == nil {
+			continue
+		}
+		data, err := acl.acl.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", acl.name, err)
+		}
+		add(12+4*i, data)
+	}
+	return b, nil
+}
@@ -0,0 +1,51 @@
+package descriptor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityDescriptorBinary(t *testing.T) {
+	sd, err := ParseSDDL("O:BAG:SYD:PAI(A;OICI;FA;;;SY)(A;;FR;;;BU)S:(AU;FA;FA;;;WD)")
+	require.NoError(t, err)
+
+	b, err := sd.MarshalBinary()
+	require.NoError(t, err)
+	// revision, control and the offsets of the owner, group, SACL and DACL
+	assert.Equal(t, []byte{1, 0, 0x14, 0x94, 20, 0, 0, 0, 36, 0, 0, 0, 48, 0, 0, 0, 76, 0, 0, 0}, b[:20])
+	owner, _ := BuiltinAdministrators.Bytes()
+	assert.Equal(t, owner, b[20:36])
+
+	parsed, err := ParseSecurityDescriptor(b)
+	require.NoError(t, err)
+	assert.Equal(t, sd, parsed)
+
+	nullDACL := &SecurityDescriptor{Control: DACLPresent}
+	b, err = nullDACL.MarshalBinary()
+	require.NoError(t, err)
+	assert.Len(t, b, 20)
+	parsed, err = ParseSecurityDescriptor(b)
+	require.NoError(t, err)
+	assert.Equal(t, nullDACL, parsed)
+
+	empty := &SecurityDescriptor{Control: DACLPresent, DACL: ACL{}}
+	b, err = empty.MarshalBinary()
+	require.NoError(t, err)
+	parsed, err = ParseSecurityDescriptor(b)
+	require.NoError(t, err)
+	assert.Equal(t, empty, parsed)
+
+	for name, invalid := range map[string][]byte{
+		"Too short":         {1, 0, 0x04, 0x80},
+		"Revision":          append([]byte{2, 0, 0x04, 0x80}, make([]byte, 16)...),
+		"Absolute":          append([]byte{1, 0, 0x04, 0x00}, make([]byte, 16)...),
+		"Offset":            append([]byte{1, 0, 0x04, 0x80, 0xFF}, make([]byte, 15)...),
+		"Truncated owner":   append([]byte{1, 0, 0x04, 0x80, 20}, make([]byte, 17)...),
+		"Header as the ACL": append(append([]byte{1, 0, 0x04, 0x80}, make([]byte, 12)...), 4, 0, 0, 0),
+	} {
+		_, err := ParseSecurityDescriptor(invalid)
+		assert.Error(t, err, name)
+	}
+}
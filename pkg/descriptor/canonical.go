@@ -0,0 +1,158 @@
+package descriptor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// IssueKind identifies a problem found while validating an ACL
+type IssueKind string
+
+const (
+	// NonCanonicalOrder is reported when an ACE is out of the canonical order: explicit deny ACEs, then explicit allow
+	// ACEs, then inherited ACEs
+	NonCanonicalOrder IssueKind = "NonCanonicalOrder"
+	// DuplicateTrustee is reported when an explicit ACE has the same type, flags and trustee as a previous explicit ACE
+	DuplicateTrustee IssueKind = "DuplicateTrustee"
+	// ZeroMask is reported when an ACE does not grant, deny or audit any rights
+	ZeroMask IssueKind = "ZeroMask"
+	// ConflictingInheritance is reported when the inheritance flags of an ACE contradict each other
+	ConflictingInheritance IssueKind = "ConflictingInheritance"
+)
+
+// Issue is a problem found in a single ACE of an ACL
+type Issue struct {
+	Index   int
+	Kind    IssueKind
+	ACE     ACE
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("ACE %d (%s): %s", i.Index, i.ACE, i.Message)
+}
+
+// ValidationError is returned by Validate when an ACL has one or more issues
+type ValidationError struct {
+	Issues []Issue
+}
+
+func (e *ValidationError) Error() string {
+	issues := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		issues[i] = issue.String()
+	}
+	return fmt.Sprintf("invalid ACL: %s", strings.Join(issues, "; "))
+}
+
+// Validate checks the ACL for ACEs in non-canonical order, duplicate trustees, zero masks and conflicting inheritance
+// flags. It returns a *ValidationError listing every issue found, or nil if the ACL is valid.
+func (acl ACL) Validate() error {
+	var issues []Issue
+	type aceKey struct {
+		aceType ACEType
+		flags   ACEFlags
+		sid     SID
+	}
+	seen := make(map[aceKey]bool)
+	for i, ace := range acl {
+		if i > 0 && canonicalRank(ace) < canonicalRank(acl[i-1]) {
+			issues = append(issues, Issue{
+				Index:   i,
+				Kind:    NonCanonicalOrder,
+				ACE:     ace,
+				Message: fmt.Sprintf("%s ACE must come before the %s ACE at index %d", describeRank(ace), describeRank(acl[i-1]), i-1),
+			})
+		}
+		if ace.Mask == 0 {
+			issues = append(issues, Issue{
+				Index:   i,
+				Kind:    ZeroMask,
+				ACE:     ace,
+				Message: "access mask is empty",
+			})
+		}
+		if !ace.IsInherited() {
+			key := aceKey{aceType: ace.Type, flags: ace.Flags, sid: ace.SID}
+			if seen[key] {
+				issues = append(issues, Issue{
+					Index:   i,
+					Kind:    DuplicateTrustee,
+					ACE:     ace,
+					Message: fmt.Sprintf("duplicate %s ACE for %s", ace.Type, ace.SID),
+				})
+			}
+			seen[key] = true
+		}
+		if !ace.IsInheritable() {
+			if ace.Flags&InheritOnly != 0 {
+				issues = append(issues, Issue{
+					Index:   i,
+					Kind:    ConflictingInheritance,
+					ACE:     ace,
+					Message: "inherit-only ACE is not inherited by objects or containers, so it never applies",
+				})
+			}
+			if ace.Flags&NoPropagateInherit != 0 {
+				issues = append(issues, Issue{
+					Index:   i,
+					Kind:    ConflictingInheritance,
+					ACE:     ace,
+					Message: "no-propagate flag is set on an ACE that is not inheritable",
+				})
+			}
+		}
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+	return &ValidationError{Issues: issues}
+}
+
+// IsCanonical returns true if the ACEs are in canonical order: explicit deny ACEs, then explicit allow ACEs, then
+// inherited ACEs
+func (acl ACL) IsCanonical() bool {
+	for i := 1; i < len(acl); i++ {
+		if canonicalRank(acl[i]) < canonicalRank(acl[i-1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Canonicalize returns a copy of the ACL with the ACEs in canonical order. The relative order of ACEs within the
+// explicit deny, explicit allow and inherited groups is preserved.
+func (acl ACL) Canonicalize() ACL {
+	if acl == nil {
+		return nil
+	}
+	canonical := make(ACL, len(acl))
+	copy(canonical, acl)
+	sort.SliceStable(canonical, func(i, j int) bool {
+		return canonicalRank(canonical[i]) < canonicalRank(canonical[j])
+	})
+	return canonical
+}
+
+func canonicalRank(ace ACE) int {
+	switch {
+	case ace.IsInherited():
+		return 2
+	case ace.Type == AccessDenied:
+		return 0
+	default:
+		return 1
+	}
+}
+
+func describeRank(ace ACE) string {
+	switch canonicalRank(ace) {
+	case 0:
+		return "explicit deny"
+	case 1:
+		return "explicit " + strings.ToLower(ace.Type.String())
+	default:
+		return "inherited"
+	}
+}
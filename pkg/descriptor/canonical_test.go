@@ -0,0 +1,145 @@
+package descriptor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	user := MustParseSID("S-1-5-21-1-2-3-1001")
+
+	testCases := []struct {
+		name           string
+		acl            ACL
+		expectedIssues []IssueKind
+	}{
+		{
+			name: "Canonical ACL",
+			acl: ACL{
+				{Type: AccessDenied, Mask: 0x1, SID: user},
+				{Type: AccessAllowed, Mask: 0x1F01FF, SID: BuiltinAdministrators},
+				{Type: AccessAllowed, Flags: Inherited, Mask: 0x1200A9, SID: BuiltinUsers},
+			},
+		},
+		{
+			name: "Allow before deny",
+			acl: ACL{
+				{Type: AccessAllowed, Mask: 0x1F01FF, SID: BuiltinAdministrators},
+				{Type: AccessDenied, Mask: 0x1, SID: user},
+			},
+			expectedIssues: []IssueKind{NonCanonicalOrder},
+		},
+		{
+			name: "Inherited before explicit",
+			acl: ACL{
+				{Type: AccessAllowed, Flags: Inherited, Mask: 0x1200A9, SID: BuiltinUsers},
+				{Type: AccessAllowed, Mask: 0x1F01FF, SID: BuiltinAdministrators},
+			},
+			expectedIssues: []IssueKind{NonCanonicalOrder},
+		},
+		{
+			name: "Duplicate trustee",
+			acl: ACL{
+				{Type: AccessAllowed, Mask: 0x1200A9, SID: user},
+				{Type: AccessAllowed, Mask: 0x120116, SID: user},
+			},
+			expectedIssues: []IssueKind{DuplicateTrustee},
+		},
+		{
+			name: "Inherited duplicates are allowed",
+			acl: ACL{
+				{Type: AccessAllowed, Flags: Inherited, Mask: 0x1200A9, SID: user},
+				{Type: AccessAllowed, Flags: Inherited, Mask: 0x1200A9, SID: user},
+			},
+		},
+		{
+			name: "Zero mask",
+			acl: ACL{
+				{Type: AccessAllowed, SID: user},
+			},
+			expectedIssues: []IssueKind{ZeroMask},
+		},
+		{
+			name: "Inherit only without inheritance",
+			acl: ACL{
+				{Type: AccessAllowed, Flags: InheritOnly, Mask: 0x1200A9, SID: user},
+			},
+			expectedIssues: []IssueKind{ConflictingInheritance},
+		},
+		{
+			name: "No propagate without inheritance",
+			acl: ACL{
+				{Type: AccessAllowed, Flags: NoPropagateInherit, Mask: 0x1200A9, SID: user},
+			},
+			expectedIssues: []IssueKind{ConflictingInheritance},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.acl.Validate()
+			if len(tc.expectedIssues) == 0 {
+				assert.NoError(t, err)
+				assert.True(t, tc.acl.IsCanonical())
+				return
+			}
+			var validationErr *ValidationError
+			if !assert.True(t, errors.As(err, &validationErr), "expected a validation error, found %v", err) {
+				return
+			}
+			var kinds []IssueKind
+			for _, issue := range validationErr.Issues {
+				kinds = append(kinds, issue.Kind)
+			}
+			assert.Equal(t, tc.expectedIssues, kinds)
+		})
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	user := MustParseSID("S-1-5-21-1-2-3-1001")
+	acl := ACL{
+		{Type: AccessAllowed, Flags: Inherited, Mask: 0x1200A9, SID: BuiltinUsers},
+		{Type: AccessAllowed, Mask: 0x1F01FF, SID: BuiltinAdministrators},
+		{Type: AccessDenied, Flags: Inherited, Mask: 0x1, SID: Everyone},
+		{Type: AccessAllowed, Mask: 0x1F01FF, SID: LocalSystem},
+		{Type: AccessDenied, Mask: 0x1, SID: user},
+	}
+	assert.False(t, acl.IsCanonical())
+
+	canonical := acl.Canonicalize()
+	assert.True(t, canonical.IsCanonical())
+	assert.Equal(t, ACL{
+		{Type: AccessDenied, Mask: 0x1, SID: user},
+		{Type: AccessAllowed, Mask: 0x1F01FF, SID: BuiltinAdministrators},
+		{Type: AccessAllowed, Mask: 0x1F01FF, SID: LocalSystem},
+		{Type: AccessAllowed, Flags: Inherited, Mask: 0x1200A9, SID: BuiltinUsers},
+		{Type: AccessDenied, Flags: Inherited, Mask: 0x1, SID: Everyone},
+	}, canonical)
+	// the original ACL is left untouched
+	assert.Equal(t, BuiltinUsers, acl[0].SID)
+}
+
+func TestACLBinary(t *testing.T) {
+	acl := ACL{
+		{Type: AccessDenied, Flags: ObjectInherit | ContainerInherit, Mask: 0x1, SID: MustParseSID("S-1-5-21-1-2-3-1001")},
+		{Type: AccessAllowed, Mask: 0x1F01FF, SID: BuiltinAdministrators},
+		{Type: AccessAllowed, Flags: Inherited, Mask: 0x1200A9, SID: Everyone},
+	}
+	b, err := acl.MarshalBinary()
+	assert.NoError(t, err)
+	// header: revision 2, size, 3 ACEs
+	assert.Equal(t, []byte{2, 0, byte(len(b)), 0, 3, 0, 0, 0}, b[:8])
+
+	decoded, err := ParseACL(b)
+	assert.NoError(t, err)
+	assert.Equal(t, acl, decoded)
+
+	empty, err := ACL{}.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Len(t, empty, 8)
+
+	_, err = ParseACL(b[:len(b)-4])
+	assert.Error(t, err)
+}
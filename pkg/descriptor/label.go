@@ -0,0 +1,79 @@
+package descriptor
+
+import "fmt"
+
+// Mandatory integrity levels
+const (
+	UntrustedIntegrity  SID = "S-1-16-0"
+	LowIntegrity        SID = "S-1-16-4096"
+	MediumIntegrity     SID = "S-1-16-8192"
+	MediumPlusIntegrity SID = "S-1-16-8448"
+	HighIntegrity       SID = "S-1-16-12288"
+	SystemIntegrity     SID = "S-1-16-16384"
+)
+
+// LabelPolicy defines which accesses are denied to subjects with a lower integrity level than the object. It is
+// stored in the access mask of a mandatory label ACE.
+type LabelPolicy uint32
+
+// Mandatory label policies
+const (
+	NoWriteUp   LabelPolicy = 0x1
+	NoReadUp    LabelPolicy = 0x2
+	NoExecuteUp LabelPolicy = 0x4
+)
+
+// LabelACE creates the mandatory label ACE assigning the integrity level and policy to an object
+func LabelACE(level SID, policy LabelPolicy, flags ACEFlags) ACE {
+	return ACE{
+		Type:  SystemMandatoryLabel,
+		Flags: flags,
+		Mask:  uint32(policy),
+		SID:   level,
+	}
+}
+
+// Label returns the mandatory label ACE of the SACL that applies to the object itself, if any
+func (acl ACL) Label() (ACE, bool) {
+	for _, ace := range acl {
+		if ace.Type == SystemMandatoryLabel && ace.AppliesToObject() {
+			return ace, true
+		}
+	}
+	return ACE{}, false
+}
+
+// IsIntegrityLevel returns true if the SID is a mandatory integrity level (S-1-16-*)
+func (s SID) IsIntegrityLevel() bool {
+	return len(s) > len("S-1-16-") && s[:len("S-1-16-")] == "S-1-16-"
+}
+
+func (p LabelPolicy) String() string {
+	var s string
+	for _, policy := range []struct {
+		flag LabelPolicy
+		name string
+	}{
+		{NoWriteUp, "NW"},
+		{NoReadUp, "NR"},
+		{NoExecuteUp, "NX"},
+	} {
+		if p&policy.flag != 0 {
+			s += policy.name
+		}
+	}
+	if rest := p &^ (NoWriteUp | NoReadUp | NoExecuteUp); rest != 0 {
+		s += fmt.Sprintf("%#x", uint32(rest))
+	}
+	return s
+}
+
+// ParseLabelPolicy parses the SDDL abbreviations of a label policy (e.g. "NWNR"), as returned by LabelPolicy.String.
+// Numbers are also accepted.
+func ParseLabelPolicy(s string) (LabelPolicy, error) {
+	mask, err := parseSDDLRights(s, sddlLabelRights)
+	if err != nil {
+		return 0, fmt.Errorf("invalid label policy %q", s)
+	}
+	return LabelPolicy(mask), nil
+}
@@ -0,0 +1,37 @@
+package descriptor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabel(t *testing.T) {
+	sacl := ACL{
+		{Type: SystemAudit, Flags: FailedAccess, Mask: 0x40000000, SID: Everyone},
+		LabelACE(HighIntegrity, NoWriteUp, ObjectInherit|ContainerInherit|InheritOnly),
+		LabelACE(LowIntegrity, NoWriteUp|NoReadUp, ObjectInherit|ContainerInherit),
+	}
+	label, ok := sacl.Label()
+	assert.True(t, ok)
+	assert.Equal(t, LowIntegrity, label.SID)
+	assert.Equal(t, NoWriteUp|NoReadUp, LabelPolicy(label.Mask))
+	assert.Equal(t, "NWNR", LabelPolicy(label.Mask).String())
+	policy, err := ParseLabelPolicy("NWNR")
+	assert.NoError(t, err)
+	assert.Equal(t, NoWriteUp|NoReadUp, policy)
+	_, err = ParseLabelPolicy("NWXX")
+	assert.Error(t, err)
+
+	b, err := sacl.MarshalBinary()
+	assert.NoError(t, err)
+	decoded, err := ParseACL(b)
+	assert.NoError(t, err)
+	assert.Equal(t, sacl, decoded)
+
+	_, ok = ACL{}.Label()
+	assert.False(t, ok)
+
+	assert.True(t, SystemIntegrity.IsIntegrityLevel())
+	assert.False(t, LocalSystem.IsIntegrityLevel())
+}
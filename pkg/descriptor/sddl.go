@@ -0,0 +1,378 @@
+package descriptor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sddlSIDAliases are the two-letter SDDL abbreviations of well-known SIDs
+var sddlSIDAliases = []struct {
+	alias string
+	sid   SID
+}{
+	{"WD", Everyone},
+	{"CO", CreatorOwner},
+	{"CG", CreatorGroup},
+	{"OW", OwnerRights},
+	{"NU", "S-1-5-2"},
+	{"IU", "S-1-5-4"},
+	{"SU", "S-1-5-6"},
+	{"AN", "S-1-5-7"},
+	{"AU", AuthenticatedUsers},
+	{"RC", "S-1-5-12"},
+	{"SY", LocalSystem},
+	{"LS", "S-1-5-19"},
+	{"NS", "S-1-5-20"},
+	{"BA", BuiltinAdministrators},
+	{"BU", BuiltinUsers},
+	{"BG", "S-1-5-32-546"},
+	{"PU", "S-1-5-32-547"},
+	{"BO", "S-1-5-32-551"},
+	{"RD", "S-1-5-32-555"},
+	{"AC", "S-1-15-2-1"},
+	{"LW", LowIntegrity},
+	{"ME", MediumIntegrity},
+	{"MP", MediumPlusIntegrity},
+	{"HI", HighIntegrity},
+	{"SI", SystemIntegrity},
+}
+
+// sddlRights are the SDDL abbreviations of access rights. The file rights come first, since they are only used when
+// they match the whole mask.
+var sddlRights = []struct {
+	alias string
+	mask  uint32
+}{
+	{"FA", 0x001F01FF},
+	{"FR", 0x00120089},
+	{"FW", 0x00120116},
+	{"FX", 0x001200A0},
+	{"GA", 0x10000000},
+	{"GR", 0x80000000},
+	{"GW", 0x40000000},
+	{"GX", 0x20000000},
+	{"RC", 0x00020000},
+	{"SD", 0x00010000},
+	{"WD", 0x00040000},
+	{"WO", 0x00080000},
+	{"CC", 0x00000001},
+	{"DC", 0x00000002},
+	{"LC", 0x00000004},
+	{"SW", 0x00000008},
+	{"RP", 0x00000010},
+	{"WP", 0x00000020},
+	{"DT", 0x00000040},
+	{"LO", 0x00000080},
+	{"CR", 0x00000100},
+}
+
+// sddlLabelRights are the SDDL abbreviations of the policies of mandatory label ACEs
+var sddlLabelRights = []struct {
+	alias string
+	mask  uint32
+}{
+	{"NW", uint32(NoWriteUp)},
+	{"NR", uint32(NoReadUp)},
+	{"NX", uint32(NoExecuteUp)},
+}
+
+var sddlACETypes = []struct {
+	alias   string
+	aceType ACEType
+}{
+	{"A", AccessAllowed},
+	{"D", AccessDenied},
+	{"AU", SystemAudit},
+	{"ML", SystemMandatoryLabel},
+}
+
+// sddlNullACL is the SDDL representation of a NULL ACL
+const sddlNullACL = "NO_ACCESS_CONTROL"
+
+// SDDL returns the security descriptor in the Security Descriptor Definition Language (e.g.
+// "O:BAG:SYD:PAI(A;OICI;FA;;;SY)"), using the abbreviations of well-known SIDs and rights like the Windows
+// ConvertSecurityDescriptorToStringSecurityDescriptor function.
+func (sd *SecurityDescriptor) SDDL() string {
+	var sb strings.Builder
+	if sd.Owner != "" {
+		sb.WriteString("O:" + formatSDDLSID(sd.Owner))
+	}
+	if sd.Group != "" {
+		sb.WriteString("G:" + formatSDDLSID(sd.Group))
+	}
+	if sd.Control&DACLPresent != 0 {
+		sb.WriteString("D:")
+		writeSDDLACL(&sb, sd.DACL, sd.Control&DACLProtected != 0, sd.Control&DACLAutoInherited != 0, sd.Control&DACLAutoInheritReq != 0)
+	}
+	if sd.Control&SACLPresent != 0 {
+		sb.WriteString("S:")
+		writeSDDLACL(&sb, sd.SACL, sd.Control&SACLProtected != 0, sd.Control&SACLAutoInherited != 0, sd.Control&SACLAutoInheritReq != 0)
+	}
+	return sb.String()
+}
+
+func writeSDDLACL(sb *strings.Builder, acl ACL, protected, autoInherited, autoInheritReq bool) {
+	if protected {
+		sb.WriteString("P")
+	}
+	if autoInheritReq {
+		sb.WriteString("AR")
+	}
+	if autoInherited {
+		sb.WriteString("AI")
+	}
+	if acl == nil {
+		sb.WriteString(sddlNullACL)
+		return
+	}
+	for _, ace := range acl {
+		sb.WriteString(formatSDDLACE(ace))
+	}
+}
+
+func formatSDDLACE(ace ACE) string {
+	aceType := fmt.Sprintf("0x%x", uint8(ace.Type))
+	for _, t := range sddlACETypes {
+		if t.aceType == ace.Type {
+			aceType = t.alias
+		}
+	}
+	return fmt.Sprintf("(%s;%s;%s;;;%s)", aceType, ace.Flags, formatSDDLRights(ace), formatSDDLSID(ace.SID))
+}
+
+func formatSDDLRights(ace ACE) string {
+	if ace.Mask == 0 {
+		return ""
+	}
+	rights := sddlRights
+	if ace.Type == SystemMandatoryLabel {
+		rights = sddlLabelRights
+	} else {
+		for _, right := range sddlRights[:4] {
+			if ace.Mask == right.mask {
+				return right.alias
+			}
+		}
+		rights = sddlRights[4:]
+	}
+	var sb strings.Builder
+	mask := ace.Mask
+	for _, right := range rights {
+		if mask&right.mask == right.mask {
+			sb.WriteString(right.alias)
+			mask &^= right.mask
+		}
+	}
+	if mask != 0 {
+		return fmt.Sprintf("0x%x", ace.Mask)
+	}
+	return sb.String()
+}
+
+func formatSDDLSID(sid SID) string {
+	for _, alias := range sddlSIDAliases {
+		if alias.sid == sid {
+			return alias.alias
+		}
+	}
+	return string(sid)
+}
+
+// ParseSDDL parses a security descriptor in the Security Descriptor Definition Language, as returned by
+// SecurityDescriptor.SDDL or by Windows tools such as icacls /save and Get-Acl. Conditional and object ACEs are not
+// supported.
+func ParseSDDL(s string) (*SecurityDescriptor, error) {
+	sd := &SecurityDescriptor{}
+	rest := strings.TrimSpace(s)
+	seen := make(map[byte]bool)
+	for rest != "" {
+		if len(rest) < 2 || rest[1] != ':' {
+			return nil, fmt.Errorf("invalid SDDL %q: expected O:, G:, D: or S: at %q", s, rest)
+		}
+		section := rest[0]
+		if seen[section] {
+			return nil, fmt.Errorf("invalid SDDL %q: duplicate %c: section", s, section)
+		}
+		seen[section] = true
+		end := nextSDDLSection(rest)
+		value := rest[2:end]
+		rest = rest[end:]
+
+		var err error
+		switch section {
+		case 'O':
+			sd.Owner, err = parseSDDLSID(value)
+		case 'G':
+			sd.Group, err = parseSDDLSID(value)
+		case 'D':
+			var protected, autoInherited, autoInheritReq bool
+			sd.DACL, protected, autoInherited, autoInheritReq, err = parseSDDLACL(value)
+			sd.Control |= DACLPresent
+			if protected {
+				sd.Control |= DACLProtected
+			}
+			if autoInherited {
+				sd.Control |= DACLAutoInherited
+			}
+			if autoInheritReq {
+				sd.Control |= DACLAutoInheritReq
+			}
+		case 'S':
+			var protected, autoInherited, autoInheritReq bool
+			sd.SACL, protected, autoInherited, autoInheritReq, err = parseSDDLACL(value)
+			sd.Control |= SACLPresent
+			if protected {
+				sd.Control |= SACLProtected
+			}
+			if autoInherited {
+				sd.Control |= SACLAutoInherited
+			}
+			if autoInheritReq {
+				sd.Control |= SACLAutoInheritReq
+			}
+		default:
+			err = fmt.Errorf("unknown section %c:", section)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid SDDL %q: %w", s, err)
+		}
+	}
+	return sd, nil
+}
+
+// nextSDDLSection returns the index of the next section of the SDDL string, which starts with a section letter
+// followed by a colon outside of an ACE
+func nextSDDLSection(s string) int {
+	depth := 0
+	for i := 2; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ':':
+			if depth == 0 && strings.IndexByte("OGDS", s[i-1]) >= 0 {
+				return i - 1
+			}
+		}
+	}
+	return len(s)
+}
+
+func parseSDDLACL(s string) (acl ACL, protected, autoInherited, autoInheritReq bool, err error) {
+	flags := s
+	if i := strings.IndexByte(s, '('); i >= 0 {
+		flags, s = s[:i], s[i:]
+	} else {
+		s = ""
+	}
+	acl = ACL{}
+	for flags != "" {
+		switch {
+		case strings.HasPrefix(flags, "P"):
+			protected, flags = true, flags[1:]
+		case strings.HasPrefix(flags, "AI"):
+			autoInherited, flags = true, flags[2:]
+		case strings.HasPrefix(flags, "AR"):
+			autoInheritReq, flags = true, flags[2:]
+		case strings.HasPrefix(flags, sddlNullACL):
+			acl, flags = nil, flags[len(sddlNullACL):]
+		default:
+			return nil, false, false, false, fmt.Errorf("unknown ACL flags %q", flags)
+		}
+	}
+	for s != "" {
+		end := strings.IndexByte(s, ')')
+		if s[0] != '(' || end < 0 {
+			return nil, false, false, false, fmt.Errorf("invalid ACE %q", s)
+		}
+		if acl == nil {
+			return nil, false, false, false, fmt.Errorf("a NULL ACL cannot have ACEs")
+		}
+		ace, err := parseSDDLACE(s[1:end])
+		if err != nil {
+			return nil, false, false, false, err
+		}
+		acl = append(acl, ace)
+		s = s[end+1:]
+	}
+	return acl, protected, autoInherited, autoInheritReq, nil
+}
+
+func parseSDDLACE(s string) (ACE, error) {
+	fields := strings.Split(s, ";")
+	if len(fields) != 6 {
+		return ACE{}, fmt.Errorf("invalid ACE %q: expected 6 fields", s)
+	}
+	if fields[3] != "" || fields[4] != "" {
+		return ACE{}, fmt.Errorf("invalid ACE %q: object ACEs are not supported", s)
+	}
+	var ace ACE
+	found := false
+	for _, t := range sddlACETypes {
+		if t.alias == fields[0] {
+			ace.Type, found = t.aceType, true
+			break
+		}
+	}
+	if !found {
+		return ACE{}, fmt.Errorf("invalid ACE %q: unsupported type %q", s, fields[0])
+	}
+	flags, err := ParseACEFlags(fields[1])
+	if err != nil {
+		return ACE{}, fmt.Errorf("invalid ACE %q: %w", s, err)
+	}
+	ace.Flags = flags
+	rights := sddlRights
+	if ace.Type == SystemMandatoryLabel {
+		rights = sddlLabelRights
+	}
+	if ace.Mask, err = parseSDDLRights(fields[2], rights); err != nil {
+		return ACE{}, fmt.Errorf("invalid ACE %q: %w", s, err)
+	}
+	if ace.SID, err = parseSDDLSID(fields[5]); err != nil {
+		return ACE{}, fmt.Errorf("invalid ACE %q: %w", s, err)
+	}
+	return ace, nil
+}
+
+func parseSDDLRights(s string, rights []struct {
+	alias string
+	mask  uint32
+}) (uint32, error) {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") || (s != "" && s[0] >= '0' && s[0] <= '9') {
+		v, err := strconv.ParseUint(s, 0, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid rights %q", s)
+		}
+		return uint32(v), nil
+	}
+	var mask uint32
+	for i := 0; i < len(s); i += 2 {
+		if i+2 > len(s) {
+			return 0, fmt.Errorf("invalid rights %q", s)
+		}
+		found := false
+		for _, right := range rights {
+			if right.alias == s[i:i+2] {
+				mask |= right.mask
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("invalid rights %q: unknown right %q", s, s[i:i+2])
+		}
+	}
+	return mask, nil
+}
+
+func parseSDDLSID(s string) (SID, error) {
+	for _, alias := range sddlSIDAliases {
+		if alias.alias == s {
+			return alias.sid, nil
+		}
+	}
+	return ParseSID(s)
+}
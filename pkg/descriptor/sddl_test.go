@@ -0,0 +1,71 @@
+package descriptor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSDDL(t *testing.T) {
+	bob := MustParseSID("S-1-5-21-1-2-3-1002")
+	sd := &SecurityDescriptor{
+		Owner:   BuiltinAdministrators,
+		Group:   LocalSystem,
+		Control: DACLPresent | DACLProtected | DACLAutoInherited | SACLPresent,
+		DACL: ACL{
+			{Type: AccessDenied, Mask: 0x00010000, SID: bob},
+			{Type: AccessAllowed, Flags: ObjectInherit | ContainerInherit, Mask: 0x001F01FF, SID: LocalSystem},
+			{Type: AccessAllowed, Flags: ObjectInherit | ContainerInherit | Inherited, Mask: 0x001200A9, SID: BuiltinUsers},
+			{Type: AccessAllowed, Mask: 0x00100003, SID: bob},
+		},
+		SACL: ACL{
+			{Type: SystemAudit, Flags: FailedAccess, Mask: 0x40000000, SID: Everyone},
+			LabelACE(HighIntegrity, NoWriteUp, ObjectInherit|ContainerInherit),
+		},
+	}
+
+	s := sd.SDDL()
+	assert.Equal(t, "O:BAG:SYD:PAI(D;;SD;;;S-1-5-21-1-2-3-1002)(A;OICI;FA;;;SY)(A;OICIID;0x1200a9;;;BU)"+
+		"(A;;0x100003;;;S-1-5-21-1-2-3-1002)S:(AU;FA;GW;;;WD)(ML;OICI;NW;;;HI)", s)
+
+	parsed, err := ParseSDDL(s)
+	require.NoError(t, err)
+	assert.Equal(t, sd, parsed)
+	assert.Equal(t, OwnerInformation|GroupInformation|DACLInformation|SACLInformation|LabelInformation, parsed.Information())
+}
+
+func TestParseSDDL(t *testing.T) {
+	sd, err := ParseSDDL("D:(A;OICI;FRFW;;;S-1-5-32-545)(A;;CCDCLCSWRPWPDTLOCRSDRCWDWO;;;BA)")
+	require.NoError(t, err)
+	assert.Equal(t, &SecurityDescriptor{
+		Control: DACLPresent,
+		DACL: ACL{
+			{Type: AccessAllowed, Flags: ObjectInherit | ContainerInherit, Mask: 0x00120089 | 0x00120116, SID: BuiltinUsers},
+			{Type: AccessAllowed, Mask: 0x000F01FF, SID: BuiltinAdministrators},
+		},
+	}, sd)
+	assert.Equal(t, DACLInformation, sd.Information())
+
+	sd, err = ParseSDDL("O:S-1-22-1-1000D:NO_ACCESS_CONTROL")
+	require.NoError(t, err)
+	assert.Equal(t, SID("S-1-22-1-1000"), sd.Owner)
+	assert.Nil(t, sd.DACL)
+	assert.Equal(t, "O:S-1-22-1-1000D:NO_ACCESS_CONTROL", sd.SDDL())
+
+	for _, invalid := range []string{
+		"X:BA",
+		"O:BAO:SY",
+		"O:XX",
+		"D:(A;;FA;;;SY",
+		"D:(A;;FA;;SY)",
+		"D:(OA;;FA;bf967aba-0de6-11d0-a285-00aa003049e2;;;SY)",
+		"D:(A;;ZZ;;;SY)",
+		"D:(A;XX;FA;;;SY)",
+		"D:Q(A;;FA;;;SY)",
+		"D:NO_ACCESS_CONTROL(A;;FA;;;SY)",
+	} {
+		_, err := ParseSDDL(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,114 @@
+package descriptor
+
+// Control holds the control flags of a security descriptor. These have the same values as the Windows
+// SECURITY_DESCRIPTOR_CONTROL flags.
+type Control uint16
+
+// Security descriptor control flags
+const (
+	OwnerDefaulted     Control = 0x0001
+	GroupDefaulted     Control = 0x0002
+	DACLPresent        Control = 0x0004
+	DACLDefaulted      Control = 0x0008
+	SACLPresent        Control = 0x0010
+	SACLDefaulted      Control = 0x0020
+	DACLAutoInheritReq Control = 0x0100
+	SACLAutoInheritReq Control = 0x0200
+	DACLAutoInherited  Control = 0x0400
+	SACLAutoInherited  Control = 0x0800
+	DACLProtected      Control = 0x1000
+	SACLProtected      Control = 0x2000
+	SelfRelative       Control = 0x8000
+)
+
+// Information selects the parts of a security descriptor that are read or written. These have the same values as
+// the Windows SECURITY_INFORMATION flags.
+type Information uint32
+
+// Security information flags
+const (
+	OwnerInformation Information = 0x01
+	GroupInformation Information = 0x02
+	DACLInformation  Information = 0x04
+	SACLInformation  Information = 0x08
+	LabelInformation Information = 0x10
+
+	// DefaultInformation selects the owner, group and DACL, which can be read and written without any privilege
+	DefaultInformation = OwnerInformation | GroupInformation | DACLInformation
+)
+
+// SecurityDescriptor holds the owner, group, discretionary ACL (access rules) and system ACL (audit rules and
+// mandatory label) of an object. Empty SIDs and ACLs whose presence flag is not set in Control are not part of the
+// security descriptor. A nil DACL whose presence flag is set is a NULL DACL, which grants full access to everyone,
+// unlike an empty DACL, which grants no access at all.
+type SecurityDescriptor struct {
+	Owner   SID     `json:"owner,omitempty"`
+	Group   SID     `json:"group,omitempty"`
+	Control Control `json:"control"`
+	DACL    ACL     `json:"dacl"`
+	SACL    ACL     `json:"sacl,omitempty"`
+}
+
+// IsDACLProtected returns true if the DACL does not inherit ACEs from the parent object
+func (sd *SecurityDescriptor) IsDACLProtected() bool {
+	return sd.Control&DACLProtected != 0
+}
+
+// Information returns the parts that the security descriptor holds: the owner and group if they are set, the DACL and
+// SACL if their presence flag is set, and the mandatory label if the SACL has a label ACE
+func (sd *SecurityDescriptor) Information() Information {
+	var info Information
+	if sd.Owner != "" {
+		info |= OwnerInformation
+	}
+	if sd.Group != "" {
+		info |= GroupInformation
+	}
+	if sd.Control&DACLPresent != 0 {
+		info |= DACLInformation
+	}
+	if sd.Control&SACLPresent != 0 {
+		info |= SACLInformation
+		if _, ok := sd.SACL.Label(); ok {
+			info |= LabelInformation
+		}
+	}
+	return info
+}
+
+// Select returns a copy of the security descriptor that only holds the parts selected by info, along with their
+// control flags. In the SACL, the mandatory label ACEs are selected by LabelInformation, and the other ACEs by
+// SACLInformation.
+func (sd *SecurityDescriptor) Select(info Information) *SecurityDescriptor {
+	result := &SecurityDescriptor{}
+	if info&OwnerInformation != 0 {
+		result.Owner = sd.Owner
+		result.Control |= sd.Control & OwnerDefaulted
+	}
+	if info&GroupInformation != 0 {
+		result.Group = sd.Group
+		result.Control |= sd.Control & GroupDefaulted
+	}
+	if info&DACLInformation != 0 {
+		result.Control |= sd.Control & (DACLPresent | DACLDefaulted | DACLAutoInheritReq | DACLAutoInherited | DACLProtected)
+		if sd.DACL != nil {
+			result.DACL = append(ACL{}, sd.DACL...)
+		}
+	}
+	if info&(SACLInformation|LabelInformation) != 0 && sd.Control&SACLPresent != 0 {
+		result.Control |= SACLPresent
+		if info&SACLInformation != 0 {
+			result.Control |= sd.Control & (SACLDefaulted | SACLAutoInheritReq | SACLAutoInherited | SACLProtected)
+		}
+		if sd.SACL != nil {
+			result.SACL = ACL{}
+		}
+		for _, ace := range sd.SACL {
+			isLabel := ace.Type == SystemMandatoryLabel
+			if (isLabel && info&LabelInformation != 0) || (!isLabel && info&SACLInformation != 0) {
+				result.SACL = append(result.SACL, ace)
+			}
+		}
+	}
+	return result
+}
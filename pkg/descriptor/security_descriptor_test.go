@@ -0,0 +1,27 @@
+package descriptor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelect(t *testing.T) {
+	sd, err := ParseSDDL("O:BAG:SYD:PAI(A;;FA;;;SY)S:P(AU;FA;FA;;;WD)(ML;;NW;;;HI)")
+	require.NoError(t, err)
+
+	assert.Equal(t, sd, sd.Select(sd.Information()))
+	assert.Equal(t, &SecurityDescriptor{Owner: BuiltinAdministrators}, sd.Select(OwnerInformation))
+
+	dacl := sd.Select(DACLInformation)
+	assert.Equal(t, "D:PAI(A;;FA;;;SY)", dacl.SDDL())
+	dacl.DACL[0].Mask = 0
+	assert.NotZero(t, sd.DACL[0].Mask, "the ACLs should be copied")
+
+	assert.Equal(t, "S:(ML;;NW;;;HI)", sd.Select(LabelInformation).SDDL())
+	assert.Equal(t, "S:P(AU;FA;FA;;;WD)", sd.Select(SACLInformation).SDDL())
+
+	null := &SecurityDescriptor{Control: DACLPresent}
+	assert.Equal(t, null, null.Select(DefaultInformation))
+}
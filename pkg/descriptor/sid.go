@@ -0,0 +1,159 @@
+package descriptor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SID is a security identifier in its string form (e.g. S-1-5-32-544).
+//
+// Unlike windows.SID, it can be used on any platform, compared with == and used as a map key.
+type SID string
+
+// Well-known SIDs
+const (
+	Everyone              SID = "S-1-1-0"
+	CreatorOwner          SID = "S-1-3-0"
+	CreatorGroup          SID = "S-1-3-1"
+	OwnerRights           SID = "S-1-3-4"
+	AuthenticatedUsers    SID = "S-1-5-11"
+	LocalSystem           SID = "S-1-5-18"
+	BuiltinAdministrators SID = "S-1-5-32-544"
+	BuiltinUsers          SID = "S-1-5-32-545"
+)
+
+const (
+	sidRevision          = 1
+	maxSubAuthorities    = 15
+	maxDecimalAuthority  = 1<<32 - 1
+	sidHeaderLength      = 8
+	subAuthorityByteSize = 4
+)
+
+// ParseSID parses the string form of a SID and returns it in its canonical form.
+func ParseSID(s string) (SID, error) {
+	authority, sub, err := parseSID(s)
+	if err != nil {
+		return "", err
+	}
+	return formatSID(authority, sub), nil
+}
+
+// MustParseSID is like ParseSID, but panics if the SID cannot be parsed.
+func MustParseSID(s string) SID {
+	sid, err := ParseSID(s)
+	if err != nil {
+		panic(err)
+	}
+	return sid
+}
+
+// SIDFromBytes decodes the binary form of a SID at the start of b. It returns the SID and the number of bytes read.
+func SIDFromBytes(b []byte) (SID, int, error) {
+	if len(b) < sidHeaderLength {
+		return "", 0, fmt.Errorf("SID is too short: %d bytes", len(b))
+	}
+	if b[0] != sidRevision {
+		return "", 0, fmt.Errorf("unsupported SID revision %d", b[0])
+	}
+	count := int(b[1])
+	if count > maxSubAuthorities {
+		return "", 0, fmt.Errorf("SID has too many sub authorities: %d", count)
+	}
+	length := sidHeaderLength + count*subAuthorityByteSize
+	if len(b) < length {
+		return "", 0, fmt.Errorf("SID is too short: expected %d bytes, found %d", length, len(b))
+	}
+	var authority uint64
+	for _, v := range b[2:8] {
+		authority = authority<<8 | uint64(v)
+	}
+	sub := make([]uint32, count)
+	for i := range sub {
+		sub[i] = binary.LittleEndian.Uint32(b[sidHeaderLength+i*subAuthorityByteSize:])
+	}
+	return formatSID(authority, sub), length, nil
+}
+
+// Bytes returns the binary form of the SID.
+func (s SID) Bytes() ([]byte, error) {
+	authority, sub, err := parseSID(string(s))
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, sidHeaderLength+len(sub)*subAuthorityByteSize)
+	b[0] = sidRevision
+	b[1] = byte(len(sub))
+	for i := 0; i < 6; i++ {
+		b[7-i] = byte(authority >> (8 * i))
+	}
+	for i, v := range sub {
+		binary.LittleEndian.PutUint32(b[sidHeaderLength+i*subAuthorityByteSize:], v)
+	}
+	return b, nil
+}
+
+// Valid returns true if the SID is in its canonical string form.
+func (s SID) Valid() bool {
+	parsed, err := ParseSID(string(s))
+	return err == nil && parsed == s
+}
+
+// String returns the SID as a string
+func (s SID) String() string {
+	return string(s)
+}
+
+func parseSID(s string) (uint64, []uint32, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) < 3 || (parts[0] != "S" && parts[0] != "s") {
+		return 0, nil, fmt.Errorf("invalid SID %q", s)
+	}
+	revision, err := strconv.ParseUint(parts[1], 10, 8)
+	if err != nil || revision != sidRevision {
+		return 0, nil, fmt.Errorf("invalid SID %q: unsupported revision", s)
+	}
+	authority, err := parseAuthority(parts[2])
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid SID %q: invalid identifier authority", s)
+	}
+	subAuthorities := parts[3:]
+	if len(subAuthorities) > maxSubAuthorities {
+		return 0, nil, fmt.Errorf("invalid SID %q: too many sub authorities", s)
+	}
+	sub := make([]uint32, len(subAuthorities))
+	for i, p := range subAuthorities {
+		v, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid SID %q: invalid sub authority %q", s, p)
+		}
+		sub[i] = uint32(v)
+	}
+	return authority, sub, nil
+}
+
+// parseAuthority parses the identifier authority of a SID, which is decimal, or hexadecimal with an explicit "0x"
+// prefix as Windows writes authorities of 2^32 and above
+func parseAuthority(s string) (uint64, error) {
+	if len(s) > 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		return strconv.ParseUint(s[2:], 16, 48)
+	}
+	return strconv.ParseUint(s, 10, 48)
+}
+
+func formatSID(authority uint64, sub []uint32) SID {
+	var sb strings.Builder
+	sb.WriteString("S-1-")
+	if authority > maxDecimalAuthority {
+		fmt.Fprintf(&sb, "0x%012X", authority)
+	} else {
+		sb.WriteString(strconv.FormatUint(authority, 10))
+	}
+	for _, v := range sub {
+		sb.WriteByte('-')
+		sb.WriteString(strconv.FormatUint(uint64(v), 10))
+	}
+	return SID(sb.String())
+}
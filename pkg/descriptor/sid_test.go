@@ -0,0 +1,96 @@
+package descriptor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSID(t *testing.T) {
+	testCases := []struct {
+		name        string
+		sid         string
+		expected    SID
+		expectedErr bool
+	}{
+		{
+			name:     "Well-known SID",
+			sid:      "S-1-5-32-544",
+			expected: BuiltinAdministrators,
+		},
+		{
+			name:     "Lowercase prefix",
+			sid:      "s-1-1-0",
+			expected: Everyone,
+		},
+		{
+			name:     "Hexadecimal authority",
+			sid:      "S-1-0x5-18",
+			expected: LocalSystem,
+		},
+		{
+			name:     "Large authority",
+			sid:      "S-1-0x123456789ABC-1",
+			expected: "S-1-0x123456789ABC-1",
+		},
+		{
+			name:     "Decimal authority with leading zeros",
+			sid:      "S-1-010-1",
+			expected: "S-1-10-1",
+		},
+		{
+			name:        "Underscore in authority",
+			sid:         "S-1-0_5-18",
+			expectedErr: true,
+		},
+		{
+			name:        "Empty hexadecimal authority",
+			sid:         "S-1-0x-18",
+			expectedErr: true,
+		},
+		{
+			name:        "Not a SID",
+			sid:         "BUILTIN\\Administrators",
+			expectedErr: true,
+		},
+		{
+			name:        "Unsupported revision",
+			sid:         "S-2-5-18",
+			expectedErr: true,
+		},
+		{
+			name:        "Invalid sub authority",
+			sid:         "S-1-5-4294967296",
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sid, err := ParseSID(tc.sid)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, sid)
+		})
+	}
+}
+
+func TestSIDBytes(t *testing.T) {
+	b, err := BuiltinAdministrators.Bytes()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 0, 0, 0, 0, 0, 5, 32, 0, 0, 0, 0x20, 2, 0, 0}, b)
+
+	for _, sid := range []SID{Everyone, LocalSystem, "S-1-5-21-1004336348-1177238915-682003330-512", "S-1-0x123456789ABC-1"} {
+		b, err := sid.Bytes()
+		assert.NoError(t, err)
+		decoded, n, err := SIDFromBytes(b)
+		assert.NoError(t, err)
+		assert.Equal(t, len(b), n)
+		assert.Equal(t, sid, decoded)
+	}
+
+	_, _, err = SIDFromBytes(b[:10])
+	assert.Error(t, err)
+}
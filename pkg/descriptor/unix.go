@@ -0,0 +1,44 @@
+package descriptor
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Prefixes of the SIDs that Samba and Windows NFS use for Unix users and groups without a Windows account
+const (
+	unixUserPrefix  = "S-1-22-1-"
+	unixGroupPrefix = "S-1-22-2-"
+)
+
+// UnixUserSID returns the SID of a Unix user (S-1-22-1-<uid>)
+func UnixUserSID(uid uint32) SID {
+	return SID(unixUserPrefix + strconv.FormatUint(uint64(uid), 10))
+}
+
+// UnixGroupSID returns the SID of a Unix group (S-1-22-2-<gid>)
+func UnixGroupSID(gid uint32) SID {
+	return SID(unixGroupPrefix + strconv.FormatUint(uint64(gid), 10))
+}
+
+// UnixUser returns the user ID of a SID returned by UnixUserSID
+func (s SID) UnixUser() (uint32, bool) {
+	return unixID(s, unixUserPrefix)
+}
+
+// UnixGroup returns the group ID of a SID returned by UnixGroupSID
+func (s SID) UnixGroup() (uint32, bool) {
+	return unixID(s, unixGroupPrefix)
+}
+
+func unixID(s SID, prefix string) (uint32, bool) {
+	id, ok := strings.CutPrefix(string(s), prefix)
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(v), true
+}
@@ -0,0 +1,27 @@
+package descriptor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnixSIDs(t *testing.T) {
+	user := UnixUserSID(1000)
+	assert.Equal(t, SID("S-1-22-1-1000"), user)
+	assert.True(t, user.Valid())
+	uid, ok := user.UnixUser()
+	assert.True(t, ok)
+	assert.Equal(t, uint32(1000), uid)
+	_, ok = user.UnixGroup()
+	assert.False(t, ok)
+
+	gid, ok := UnixGroupSID(0).UnixGroup()
+	assert.True(t, ok)
+	assert.Equal(t, uint32(0), gid)
+
+	_, ok = SID("S-1-22-1-99999999999").UnixUser()
+	assert.False(t, ok)
+	_, ok = BuiltinUsers.UnixUser()
+	assert.False(t, ok)
+}
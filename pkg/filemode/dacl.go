@@ -0,0 +1,90 @@
+package filemode
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// Rights returns the file rights granted by the rwx permission bits in the lowest 3 bits of perm (e.g. mode>>6 for
+// the owner). Like Convert, it maps read to GENERIC_READ, write to GENERIC_WRITE and DELETE, and execute to
+// GENERIC_EXECUTE, and the result is normalized with access.FileGenericMapping.
+func Rights(perm fs.FileMode) uint32 {
+	var mask uint32
+	if perm&04 != 0 {
+		mask |= access.GenericRead
+	}
+	if perm&02 != 0 {
+		mask |= access.GenericWrite | access.Delete
+	}
+	if perm&01 != 0 {
+		mask |= access.GenericExecute
+	}
+	return access.FileGenericMapping.Normalize(mask)
+}
+
+// ToDACL returns the ACL equivalent to the permission bits of the mode: allow ACEs for the owner, the group and
+// Everyone, in this order, without inheritance flags. It mirrors the rules that ToExplicitAccessCustom creates on
+// Windows, including the ACE for the Administrators when both the owner and group are LocalSystem.
+func ToDACL(mode fs.FileMode, owner, group descriptor.SID) descriptor.ACL {
+	acl := descriptor.ACL{}
+	add := func(perm fs.FileMode, sid descriptor.SID) {
+		if rights := Rights(perm); rights != 0 {
+			acl = append(acl, descriptor.ACE{Type: descriptor.AccessAllowed, Mask: rights, SID: sid})
+		}
+	}
+	add(mode>>6, owner)
+	add(mode>>3, group)
+	add(mode, descriptor.Everyone)
+	if owner == descriptor.LocalSystem && group == descriptor.LocalSystem {
+		add(mode>>6, descriptor.BuiltinAdministrators)
+	}
+	return acl
+}
+
+// FromDACL returns the permission bits equivalent to an ACL made of allow ACEs for the owner, the group and Everyone,
+// such as the ACLs returned by ToDACL. A permission bit is set when the trustee is granted all the rights that Rights
+// maps it to, so rights that permission bits cannot express are dropped. ACLs with deny ACEs, inherit-only ACEs or
+// other trustees cannot be represented by permission bits, and return an error. The Administrators are accepted as
+// an alias of the owner when both the owner and group are LocalSystem, like in ToDACL. When the owner and group are
+// the same SID, the rights of its ACEs count for both.
+func FromDACL(acl descriptor.ACL, owner, group descriptor.SID) (fs.FileMode, error) {
+	var ownerRights, groupRights, otherRights uint32
+	for i, ace := range acl {
+		if ace.Type != descriptor.AccessAllowed {
+			return 0, fmt.Errorf("ACE %d: %s ACEs cannot be represented by permission bits", i, ace.Type)
+		}
+		if !ace.AppliesToObject() {
+			return 0, fmt.Errorf("ACE %d: inherit-only ACEs cannot be represented by permission bits", i)
+		}
+		rights := access.FileGenericMapping.Normalize(ace.Mask)
+		isAdministrators := ace.SID == descriptor.BuiltinAdministrators && owner == descriptor.LocalSystem && group == descriptor.LocalSystem
+		if ace.SID != owner && ace.SID != group && ace.SID != descriptor.Everyone && !isAdministrators {
+			return 0, fmt.Errorf("ACE %d: %s is neither the owner, the group nor Everyone", i, ace.SID)
+		}
+		if ace.SID == owner || isAdministrators {
+			ownerRights |= rights
+		}
+		if ace.SID == group {
+			groupRights |= rights
+		}
+		if ace.SID == descriptor.Everyone {
+			otherRights |= rights
+		}
+	}
+	return Perm(ownerRights)<<6 | Perm(groupRights)<<3 | Perm(otherRights), nil
+}
+
+// Perm returns the rwx permission bits whose rights are all included in rights, in the lowest 3 bits. It is the
+// inverse of Rights.
+func Perm(rights uint32) fs.FileMode {
+	var perm fs.FileMode
+	for _, bit := range []fs.FileMode{04, 02, 01} {
+		if r := Rights(bit); rights&r == r {
+			perm |= bit
+		}
+	}
+	return perm
+}
@@ -0,0 +1,59 @@
+package filemode
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToDACL(t *testing.T) {
+	owner := descriptor.UnixUserSID(1000)
+	group := descriptor.UnixGroupSID(100)
+
+	assert.Equal(t, descriptor.ACL{
+		{Type: descriptor.AccessAllowed, Mask: access.Modify | access.Synchronize, SID: owner},
+		{Type: descriptor.AccessAllowed, Mask: access.ReadAndExecute | access.Synchronize, SID: group},
+	}, ToDACL(0750, owner, group))
+
+	assert.Equal(t, descriptor.ACL{
+		{Type: descriptor.AccessAllowed, Mask: Rights(07), SID: descriptor.LocalSystem},
+		{Type: descriptor.AccessAllowed, Mask: Rights(07), SID: descriptor.BuiltinAdministrators},
+	}, ToDACL(0700, descriptor.LocalSystem, descriptor.LocalSystem))
+
+	assert.Empty(t, ToDACL(0, owner, group))
+	assert.Equal(t, access.Read|access.Synchronize, Rights(04))
+}
+
+func TestFromDACL(t *testing.T) {
+	owner := descriptor.UnixUserSID(1000)
+	group := descriptor.UnixGroupSID(100)
+
+	for _, mode := range []fs.FileMode{0, 0644, 0750, 0777, 0005} {
+		actual, err := FromDACL(ToDACL(mode, owner, group), owner, group)
+		assert.NoError(t, err)
+		assert.Equal(t, mode, actual)
+	}
+	mode, err := FromDACL(ToDACL(0750, descriptor.LocalSystem, descriptor.LocalSystem), descriptor.LocalSystem, descriptor.LocalSystem)
+	assert.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0770), mode)
+
+	mode, err = FromDACL(descriptor.ACL{
+		{Type: descriptor.AccessAllowed, Flags: descriptor.ObjectInherit | descriptor.ContainerInherit, Mask: access.FullControl, SID: owner},
+		{Type: descriptor.AccessAllowed, Mask: access.ReadData, SID: group},
+		{Type: descriptor.AccessAllowed, Mask: access.GenericRead, SID: descriptor.Everyone},
+	}, owner, group)
+	assert.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0704), mode)
+
+	for _, acl := range []descriptor.ACL{
+		{{Type: descriptor.AccessDenied, Mask: access.Write, SID: group}},
+		{{Type: descriptor.AccessAllowed, Flags: descriptor.ObjectInherit | descriptor.InheritOnly, Mask: access.Read, SID: owner}},
+		{{Type: descriptor.AccessAllowed, Mask: access.Read, SID: descriptor.BuiltinUsers}},
+	} {
+		_, err := FromDACL(acl, owner, group)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,176 @@
+package icacls
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// Entry is an access rule in icacls syntax, such as `BUILTIN\Users:(OI)(CI)RX` or `*S-1-1-0:(DENY)(W)`
+type Entry struct {
+	// Trustee is an account name, or a SID prefixed with "*" as icacls accepts them
+	Trustee string
+	// Type is descriptor.AccessAllowed or descriptor.AccessDenied
+	Type descriptor.ACEType
+	// Replace is set for the entries of /grant:r, which replace the rights previously granted to the trustee instead
+	// of adding to them. Such entries are applied with ApplyEntries.
+	Replace bool
+	Flags   descriptor.ACEFlags
+	Rights  uint32
+}
+
+// SID returns the SID of the trustee if it is written as "*S-1-..."
+func (e Entry) SID() (descriptor.SID, bool) {
+	if !strings.HasPrefix(e.Trustee, "*") {
+		return "", false
+	}
+	sid, err := descriptor.ParseSID(e.Trustee[1:])
+	return sid, err == nil
+}
+
+// ApplyEntries returns a copy of the DACL with the entries applied as icacls applies them: each entry adds an
+// explicit ACE, and the entries of /grant:r first remove the explicit allow ACEs of their trustee, while its deny ACEs
+// are kept. The DACL is returned in canonical order.
+//
+// Trustees written as account names are resolved with lookup. If it is nil, only SIDs and the names of WellKnownSID
+// are accepted.
+func ApplyEntries(dacl descriptor.ACL, lookup func(name string) (descriptor.SID, error), entries ...Entry) (descriptor.ACL, error) {
+	result := append(descriptor.ACL{}, dacl...)
+	for _, e := range entries {
+		sid, ok := e.SID()
+		if !ok {
+			sid, ok = WellKnownSID(e.Trustee)
+		}
+		if !ok {
+			if lookup == nil {
+				return nil, fmt.Errorf("cannot resolve trustee %q", e.Trustee)
+			}
+			var err error
+			if sid, err = lookup(e.Trustee); err != nil {
+				return nil, fmt.Errorf("cannot resolve trustee %q: %w", e.Trustee, err)
+			}
+		}
+		if e.Replace && e.Type != descriptor.AccessDenied {
+			kept := descriptor.ACL{}
+			for _, ace := range result {
+				if ace.SID != sid || ace.Type != descriptor.AccessAllowed || ace.Flags&descriptor.Inherited != 0 {
+					kept = append(kept, ace)
+				}
+			}
+			result = kept
+		}
+		result = append(result, descriptor.ACE{
+			Type:  e.Type,
+			Flags: e.Flags & descriptor.InheritanceFlags,
+			Mask:  e.Rights,
+			SID:   sid,
+		})
+	}
+	return result.Canonicalize(), nil
+}
+
+// String returns the entry as icacls prints it, e.g. `BUILTIN\Users:(OI)(CI)(RX)`
+func (e Entry) String() string {
+	s := e.Trustee + ":" + formatFlags(e.Flags)
+	if e.Type == descriptor.AccessDenied {
+		s += "(DENY)"
+	}
+	return s + "(" + FormatRights(e.Rights) + ")"
+}
+
+// ParseEntry parses an access rule in the syntax of the icacls /grant and /deny options: a trustee, a colon, optional
+// inheritance flags in parentheses and rights, which are either a single simple right (e.g. "RX") or a list of rights
+// in parentheses (e.g. "(RD,WD)"). It also accepts the output of icacls, where simple rights are in parentheses and
+// denied rights are preceded by "(DENY)".
+func ParseEntry(s string) (Entry, error) {
+	i := strings.LastIndex(s, ":")
+	if i <= 0 {
+		return Entry{}, fmt.Errorf("invalid entry %q: expected TRUSTEE:PERMISSIONS", s)
+	}
+	e := Entry{Trustee: strings.TrimSpace(s[:i]), Type: descriptor.AccessAllowed}
+	if strings.HasPrefix(e.Trustee, "*") {
+		if _, ok := e.SID(); !ok {
+			return Entry{}, fmt.Errorf("invalid entry %q: invalid SID %q", s, e.Trustee[1:])
+		}
+	}
+
+	rest := strings.TrimSpace(s[i+1:])
+	hasRights := false
+	for rest != "" {
+		var token string
+		if rest[0] == '(' {
+			end := strings.IndexByte(rest, ')')
+			if end < 0 {
+				return Entry{}, fmt.Errorf("invalid entry %q: missing )", s)
+			}
+			token, rest = rest[1:end], rest[end+1:]
+		} else {
+			end := strings.IndexByte(rest, '(')
+			if end < 0 {
+				end = len(rest)
+			}
+			token, rest = rest[:end], rest[end:]
+		}
+		if flag, ok := lookupFlag(token); ok {
+			e.Flags |= flag
+			continue
+		}
+		if strings.EqualFold(token, "DENY") {
+			e.Type = descriptor.AccessDenied
+			continue
+		}
+		rights, err := ParseRights(token)
+		if err != nil {
+			return Entry{}, fmt.Errorf("invalid entry %q: %w", s, err)
+		}
+		e.Rights |= rights
+		hasRights = true
+	}
+	if !hasRights {
+		return Entry{}, fmt.Errorf("invalid entry %q: missing rights", s)
+	}
+	return e, nil
+}
+
+func lookupFlag(name string) (descriptor.ACEFlags, bool) {
+	for _, f := range inheritanceFlags {
+		if strings.EqualFold(f.name, name) {
+			return f.flag, true
+		}
+	}
+	return 0, false
+}
+
+// ParseArgs parses the /grant, /grant:r and /deny options of an icacls command line, each followed by one or more
+// entries (e.g. ["/grant", `BUILTIN\Users:(OI)(CI)RX`, "/deny", "*S-1-1-0:W"]). Other options are rejected.
+func ParseArgs(args []string) ([]Entry, error) {
+	var entries []Entry
+	option := ""
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "/") {
+			switch strings.ToLower(arg) {
+			case "/grant", "/grant:r", "/deny":
+				option = strings.ToLower(arg)
+				continue
+			default:
+				return nil, fmt.Errorf("unsupported option %q: expected /grant, /grant:r or /deny", arg)
+			}
+		}
+		if option == "" {
+			return nil, fmt.Errorf("entry %q must follow /grant, /grant:r or /deny", arg)
+		}
+		e, err := ParseEntry(arg)
+		if err != nil {
+			return nil, err
+		}
+		switch option {
+		case "/grant:r":
+			e.Replace = true
+		case "/deny":
+			e.Type = descriptor.AccessDenied
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
@@ -0,0 +1,69 @@
+//go:build windows
+
+package icacls
+
+import (
+	"fmt"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"golang.org/x/sys/windows"
+)
+
+// ExplicitAccess converts the entry to an EXPLICIT_ACCESS that can be passed to acl.Apply. The inherited flag is
+// dropped, since the ACEs written by acl.Apply are explicit.
+//
+// Entries of /grant:r are rejected: the only EXPLICIT_ACCESS mode that replaces rights, SET_ACCESS, also removes the
+// deny ACEs of the trustee, which icacls keeps. Use ApplyEntries to apply them to a DACL instead.
+func (e Entry) ExplicitAccess() (windows.EXPLICIT_ACCESS, error) {
+	var entry windows.EXPLICIT_ACCESS
+	if e.Replace && e.Type != descriptor.AccessDenied {
+		return entry, fmt.Errorf("entry %s of /grant:r cannot be converted to an EXPLICIT_ACCESS: use ApplyEntries", e)
+	}
+	if sid, ok := e.SID(); ok {
+		winSID, err := windows.StringToSid(sid.String())
+		if err != nil {
+			return entry, fmt.Errorf("invalid trustee %s: %w", e.Trustee, err)
+		}
+		if e.Type == descriptor.AccessDenied {
+			entry = access.DenySid(windows.ACCESS_MASK(e.Rights), winSID)
+		} else {
+			entry = access.GrantSid(windows.ACCESS_MASK(e.Rights), winSID)
+		}
+	} else if e.Type == descriptor.AccessDenied {
+		entry = access.DenyName(windows.ACCESS_MASK(e.Rights), e.Trustee)
+	} else {
+		entry = access.GrantName(windows.ACCESS_MASK(e.Rights), e.Trustee)
+	}
+	entry.Inheritance = uint32(e.Flags & descriptor.InheritanceFlags)
+	return entry, nil
+}
+
+// ExplicitAccess converts the entries to EXPLICIT_ACCESS values, as Entry.ExplicitAccess does
+func ExplicitAccess(entries ...Entry) ([]windows.EXPLICIT_ACCESS, error) {
+	explicitAccess := make([]windows.EXPLICIT_ACCESS, len(entries))
+	for i, e := range entries {
+		var err error
+		if explicitAccess[i], err = e.ExplicitAccess(); err != nil {
+			return nil, err
+		}
+	}
+	return explicitAccess, nil
+}
+
+// LookupName is a NameFunc that resolves SIDs to DOMAIN\account names with LookupAccountSid. Well-known SIDs that
+// belong to no domain, such as Everyone, are returned without a domain, as icacls does.
+func LookupName(sid descriptor.SID) string {
+	winSID, err := windows.StringToSid(sid.String())
+	if err != nil {
+		return ""
+	}
+	account, domain, _, err := winSID.LookupAccount("")
+	if err != nil {
+		return ""
+	}
+	if domain == "" {
+		return account
+	}
+	return domain + `\` + account
+}
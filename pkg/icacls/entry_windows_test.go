@@ -0,0 +1,42 @@
+//go:build windows
+
+package icacls
+
+import (
+	"testing"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"golang.org/x/sys/windows"
+)
+
+func TestExplicitAccess(t *testing.T) {
+	entries, err := ParseArgs([]string{"/grant", "*S-1-5-32-545:(OI)(CI)RX", "/deny", "*S-1-1-0:W"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	explicitAccess, err := ExplicitAccess(entries...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if explicitAccess[0].AccessMode != windows.GRANT_ACCESS || explicitAccess[0].Inheritance != windows.SUB_CONTAINERS_AND_OBJECTS_INHERIT {
+		t.Errorf("unexpected EXPLICIT_ACCESS for %s: %+v", entries[0], explicitAccess[0])
+	}
+	if explicitAccess[1].AccessMode != windows.DENY_ACCESS || explicitAccess[1].Inheritance != windows.NO_INHERITANCE {
+		t.Errorf("unexpected EXPLICIT_ACCESS for %s: %+v", entries[1], explicitAccess[1])
+	}
+	if explicitAccess[1].Trustee.TrusteeForm != windows.TRUSTEE_IS_SID {
+		t.Errorf("expected a SID trustee, found %d", explicitAccess[1].Trustee.TrusteeForm)
+	}
+
+	replace, err := ParseArgs([]string{"/grant:r", "*S-1-5-32-545:RX"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ExplicitAccess(replace...); err == nil {
+		t.Error("expected an error for /grant:r, which SET_ACCESS cannot represent")
+	}
+
+	if name := LookupName(descriptor.BuiltinAdministrators); name == "" {
+		t.Error("failed to look up the name of the Administrators")
+	}
+}
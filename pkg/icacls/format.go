@@ -0,0 +1,80 @@
+package icacls
+
+import (
+	"strings"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// wellKnownNames are the names that icacls prints for well-known SIDs on an English system
+var wellKnownNames = map[descriptor.SID]string{
+	descriptor.Everyone:              "Everyone",
+	descriptor.CreatorOwner:          "CREATOR OWNER",
+	descriptor.CreatorGroup:          "CREATOR GROUP",
+	descriptor.OwnerRights:           "OWNER RIGHTS",
+	descriptor.AuthenticatedUsers:    `NT AUTHORITY\Authenticated Users`,
+	descriptor.LocalSystem:           `NT AUTHORITY\SYSTEM`,
+	descriptor.BuiltinAdministrators: `BUILTIN\Administrators`,
+	descriptor.BuiltinUsers:          `BUILTIN\Users`,
+}
+
+// NameFunc returns the name of the account of a SID, or an empty string if it cannot be resolved
+type NameFunc func(sid descriptor.SID) string
+
+// WellKnownName returns the English name of well-known SIDs such as Everyone or BUILTIN\Administrators. It returns an
+// empty string for other SIDs.
+func WellKnownName(sid descriptor.SID) string {
+	return wellKnownNames[sid]
+}
+
+// WellKnownSID returns the SID of the well-known accounts named by WellKnownName. The name is not case-sensitive.
+func WellKnownSID(name string) (descriptor.SID, bool) {
+	for sid, wellKnown := range wellKnownNames {
+		if strings.EqualFold(wellKnown, name) {
+			return sid, true
+		}
+	}
+	return "", false
+}
+
+// FormatACE returns the ACE as an icacls entry. The trustee is the name returned by names, or the SID if it cannot be
+// resolved. If names is nil, WellKnownName is used.
+func FormatACE(ace descriptor.ACE, names NameFunc) Entry {
+	if names == nil {
+		names = WellKnownName
+	}
+	trustee := names(ace.SID)
+	if trustee == "" {
+		trustee = ace.SID.String()
+	}
+	return Entry{Trustee: trustee, Type: ace.Type, Flags: ace.Flags, Rights: ace.Mask}
+}
+
+// Format renders the DACL of the security descriptor like icacls: the path followed by the first entry, and the other
+// entries on their own line, aligned with the first one. Audit and label ACEs, which icacls does not print, are left
+// out. A NULL DACL is rendered as full control for Everyone, which is how Windows evaluates it.
+func Format(path string, sd *descriptor.SecurityDescriptor, names NameFunc) string {
+	dacl := sd.DACL
+	if sd.Control&descriptor.DACLPresent == 0 || dacl == nil {
+		dacl = descriptor.ACL{{Type: descriptor.AccessAllowed, Mask: access.FileGenericMapping.All, SID: descriptor.Everyone}}
+	}
+	var sb strings.Builder
+	indent := strings.Repeat(" ", len(path)+1)
+	sb.WriteString(path + " ")
+	first := true
+	for _, ace := range dacl {
+		if ace.Type != descriptor.AccessAllowed && ace.Type != descriptor.AccessDenied {
+			continue
+		}
+		if !first {
+			sb.WriteString(indent)
+		}
+		sb.WriteString(FormatACE(ace, names).String() + "\n")
+		first = false
+	}
+	if first {
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
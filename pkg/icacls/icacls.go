@@ -0,0 +1,142 @@
+// Package icacls formats and parses access rules in the syntax of the Windows icacls tool, such as
+// `BUILTIN\Administrators:(OI)(CI)(F)`.
+package icacls
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// maximumAllowed is the MAXIMUM_ALLOWED access right
+const maximumAllowed uint32 = 0x02000000
+
+// simpleRights are the icacls abbreviations of common combinations of rights, from the largest to the smallest
+var simpleRights = []struct {
+	name string
+	mask uint32
+}{
+	{"F", access.FileGenericMapping.All},
+	{"M", access.Modify | access.Synchronize},
+	{"RX", access.FileGenericMapping.Read | access.FileGenericMapping.Execute},
+	{"R", access.FileGenericMapping.Read},
+	{"W", access.FileGenericMapping.Write &^ access.ReadPermissions},
+	{"D", access.Delete},
+}
+
+// specificRights are the icacls abbreviations of individual rights
+var specificRights = []struct {
+	name string
+	mask uint32
+}{
+	{"DE", access.Delete},
+	{"RC", access.ReadPermissions},
+	{"WDAC", access.ChangePermissions},
+	{"WO", access.TakeOwnership},
+	{"S", access.Synchronize},
+	{"AS", access.AccessSystemSecurity},
+	{"MA", maximumAllowed},
+	{"GR", access.GenericRead},
+	{"GW", access.GenericWrite},
+	{"GE", access.GenericExecute},
+	{"GA", access.GenericAll},
+	{"RD", access.ReadData},
+	{"WD", access.WriteData},
+	{"AD", access.AppendData},
+	{"REA", access.ReadExtendedAttributes},
+	{"WEA", access.WriteExtendedAttributes},
+	{"X", access.ExecuteFile},
+	{"DC", access.DeleteSubdirectoriesAndFiles},
+	{"RA", access.ReadAttributes},
+	{"WA", access.WriteAttributes},
+}
+
+// inheritanceFlags are the icacls abbreviations of ACE flags, in the order icacls prints them
+var inheritanceFlags = []struct {
+	name string
+	flag descriptor.ACEFlags
+}{
+	{"I", descriptor.Inherited},
+	{"OI", descriptor.ObjectInherit},
+	{"CI", descriptor.ContainerInherit},
+	{"IO", descriptor.InheritOnly},
+	{"NP", descriptor.NoPropagateInherit},
+}
+
+// FormatRights returns the rights of the mask as icacls prints them: the simple rights that the mask includes (e.g.
+// "RX,W"), followed by the specific rights that they do not cover (e.g. "M,DC"). Bits that have no abbreviation are
+// formatted as a hexadecimal number.
+func FormatRights(mask uint32) string {
+	if mask == 0 {
+		return "N"
+	}
+	var names []string
+	var covered uint32
+	for _, right := range simpleRights {
+		if mask&right.mask == right.mask && right.mask&^covered != 0 {
+			names = append(names, right.name)
+			covered |= right.mask
+		}
+	}
+	for _, right := range specificRights {
+		if mask&right.mask != 0 && covered&right.mask == 0 {
+			names = append(names, right.name)
+			covered |= right.mask
+		}
+	}
+	if rest := mask &^ covered; rest != 0 {
+		names = append(names, fmt.Sprintf("%#x", rest))
+	}
+	return strings.Join(names, ",")
+}
+
+// ParseRights parses rights separated by commas, as accepted by icacls (e.g. "RX", "RD,WD" or "M,DC"). Simple and
+// specific rights can be mixed, the case is ignored, and hexadecimal numbers are accepted as well.
+func ParseRights(s string) (uint32, error) {
+	var mask uint32
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if strings.EqualFold(name, "N") {
+			continue
+		}
+		right, ok := lookupRight(name)
+		if !ok {
+			return 0, fmt.Errorf("unknown right %q", name)
+		}
+		mask |= right
+	}
+	return mask, nil
+}
+
+func lookupRight(name string) (uint32, bool) {
+	for _, right := range simpleRights {
+		if strings.EqualFold(right.name, name) {
+			return right.mask, true
+		}
+	}
+	for _, right := range specificRights {
+		if strings.EqualFold(right.name, name) {
+			return right.mask, true
+		}
+	}
+	if strings.HasPrefix(name, "0x") || strings.HasPrefix(name, "0X") {
+		if v, err := strconv.ParseUint(name, 0, 32); err == nil {
+			return uint32(v), true
+		}
+	}
+	return 0, false
+}
+
+// formatFlags returns the ACE flags as icacls prints them (e.g. "(I)(OI)(CI)")
+func formatFlags(flags descriptor.ACEFlags) string {
+	var sb strings.Builder
+	for _, f := range inheritanceFlags {
+		if flags&f.flag != 0 {
+			sb.WriteString("(" + f.name + ")")
+		}
+	}
+	return sb.String()
+}
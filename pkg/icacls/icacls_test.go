@@ -0,0 +1,119 @@
+package icacls
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRights(t *testing.T) {
+	for mask, expected := range map[uint32]string{
+		0:                                  "N",
+		access.FileGenericMapping.All:      "F",
+		access.Modify | access.Synchronize: "M",
+		0x001200A9:                         "RX",
+		0x001201BF:                         "RX,W",
+		access.Modify | access.Synchronize | access.DeleteSubdirectoriesAndFiles: "M,DC",
+		access.ReadData | access.WriteData:                                       "RD,WD",
+		access.GenericAll:                                                        "GA",
+		access.Delete | access.ReadPermissions:                                   "D,RC",
+		0x00000200:                                                               "0x200",
+	} {
+		assert.Equal(t, expected, FormatRights(mask))
+		parsed, err := ParseRights(expected)
+		require.NoError(t, err)
+		assert.Equal(t, mask, parsed, expected)
+	}
+	_, err := ParseRights("RWX")
+	assert.Error(t, err)
+}
+
+func TestParseEntry(t *testing.T) {
+	e, err := ParseEntry(`BUILTIN\Users:(OI)(CI)RX`)
+	require.NoError(t, err)
+	assert.Equal(t, Entry{
+		Trustee: `BUILTIN\Users`,
+		Type:    descriptor.AccessAllowed,
+		Flags:   descriptor.ObjectInherit | descriptor.ContainerInherit,
+		Rights:  0x001200A9,
+	}, e)
+	assert.Equal(t, `BUILTIN\Users:(OI)(CI)(RX)`, e.String())
+
+	e, err = ParseEntry("*S-1-1-0:(oi)(DENY)(w,d)")
+	require.NoError(t, err)
+	sid, ok := e.SID()
+	assert.True(t, ok)
+	assert.Equal(t, descriptor.Everyone, sid)
+	assert.Equal(t, descriptor.AccessDenied, e.Type)
+	assert.Equal(t, "*S-1-1-0:(OI)(DENY)(W,D)", e.String())
+
+	roundTrip, err := ParseEntry(`NT AUTHORITY\SYSTEM:(I)(OI)(CI)(F)`)
+	require.NoError(t, err)
+	assert.Equal(t, descriptor.Inherited|descriptor.ObjectInherit|descriptor.ContainerInherit, roundTrip.Flags)
+
+	for _, invalid := range []string{"Users", ":F", "Users:(OI)", "Users:(OI", "Users:RWX", "*S-1-X:F"} {
+		_, err := ParseEntry(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	entries, err := ParseArgs([]string{"/grant:r", `BUILTIN\Users:(OI)(CI)RX`, "bob:M", "/deny", "*S-1-1-0:(WD)"})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.True(t, entries[0].Replace)
+	assert.True(t, entries[1].Replace)
+	assert.Equal(t, descriptor.AccessDenied, entries[2].Type)
+	assert.Equal(t, access.WriteData, entries[2].Rights)
+
+	_, err = ParseArgs([]string{"bob:M"})
+	assert.Error(t, err)
+	_, err = ParseArgs([]string{"/remove", "bob"})
+	assert.Error(t, err)
+}
+
+func TestApplyEntries(t *testing.T) {
+	dacl, err := descriptor.ParseSDDL("D:(D;;WD;;;S-1-5-21-1-2-3-1002)(A;;FA;;;SY)(A;OICI;FR;;;S-1-5-21-1-2-3-1002)(A;ID;FA;;;S-1-5-21-1-2-3-1002)")
+	require.NoError(t, err)
+	lookup := func(name string) (descriptor.SID, error) {
+		if name == "bob" {
+			return "S-1-5-21-1-2-3-1002", nil
+		}
+		return "", fmt.Errorf("not found")
+	}
+
+	// /grant:r replaces the explicit allow ACEs of bob, but keeps the deny ACEs of bob and the inherited ACEs
+	entries, err := ParseArgs([]string{"/grant:r", "bob:RX", "/grant", "Everyone:R"})
+	require.NoError(t, err)
+	result, err := ApplyEntries(dacl.DACL, lookup, entries...)
+	require.NoError(t, err)
+	assert.Equal(t, "D:(D;;WD;;;S-1-5-21-1-2-3-1002)(A;;FA;;;SY)(A;;0x1200a9;;;S-1-5-21-1-2-3-1002)(A;;FR;;;WD)"+
+		"(A;ID;FA;;;S-1-5-21-1-2-3-1002)", (&descriptor.SecurityDescriptor{Control: descriptor.DACLPresent, DACL: result}).SDDL())
+	assert.Len(t, dacl.DACL, 4, "the DACL should not be modified")
+
+	_, err = ApplyEntries(dacl.DACL, nil, entries...)
+	assert.Error(t, err, "bob cannot be resolved without a lookup function")
+	_, err = ApplyEntries(dacl.DACL, lookup, Entry{Trustee: "alice", Type: descriptor.AccessAllowed, Rights: access.Read})
+	assert.Error(t, err)
+}
+
+func TestFormat(t *testing.T) {
+	bob := descriptor.MustParseSID("S-1-5-21-1-2-3-1002")
+	sd := &descriptor.SecurityDescriptor{
+		Control: descriptor.DACLPresent,
+		DACL: descriptor.ACL{
+			{Type: descriptor.AccessDenied, Mask: access.Delete, SID: bob},
+			{Type: descriptor.AccessAllowed, Flags: descriptor.ObjectInherit | descriptor.ContainerInherit, Mask: access.FileGenericMapping.All, SID: descriptor.BuiltinAdministrators},
+			{Type: descriptor.AccessAllowed, Flags: descriptor.Inherited | descriptor.ObjectInherit | descriptor.ContainerInherit | descriptor.InheritOnly, Mask: access.GenericAll, SID: descriptor.CreatorOwner},
+		},
+	}
+	assert.Equal(t, `C:\data S-1-5-21-1-2-3-1002:(DENY)(D)`+"\n"+
+		`        BUILTIN\Administrators:(OI)(CI)(F)`+"\n"+
+		`        CREATOR OWNER:(I)(OI)(CI)(IO)(GA)`+"\n", Format(`C:\data`, sd, nil))
+
+	assert.Equal(t, "/data Everyone:(F)\n", Format("/data", &descriptor.SecurityDescriptor{}, nil))
+}
@@ -0,0 +1,102 @@
+// Package nfs4acl models NFSv4 access control lists (RFC 7530), as used by NFSv4 mounts, ZFS and CephFS, and encodes
+// them in the format of the system.nfs4_acl extended attribute.
+//
+// NFSv4 ACLs were designed after Windows DACLs: their ACE types, inheritance flags and access masks have the same
+// meaning and, except for some flags, the same values. They map to pkg/descriptor ACLs with ACL.ToACL and FromACL,
+// which convert the OWNER@ and GROUP@ principals to the SIDs of the owner and group, or to Creator Owner and Creator
+// Group in inherit-only ACEs, and expand generic rights, which NFSv4 does not have.
+package nfs4acl
+
+import "fmt"
+
+// ACEType is the type of an NFSv4 ACE
+type ACEType uint32
+
+// ACE types
+const (
+	Allow ACEType = 0
+	Deny  ACEType = 1
+	Audit ACEType = 2
+	Alarm ACEType = 3
+)
+
+func (t ACEType) String() string {
+	switch t {
+	case Allow:
+		return "A"
+	case Deny:
+		return "D"
+	case Audit:
+		return "U"
+	case Alarm:
+		return "L"
+	default:
+		return fmt.Sprintf("%#x", uint32(t))
+	}
+}
+
+// Flags holds the flags of an NFSv4 ACE
+type Flags uint32
+
+// ACE flags
+const (
+	FileInherit        Flags = 0x00000001
+	DirectoryInherit   Flags = 0x00000002
+	NoPropagateInherit Flags = 0x00000004
+	InheritOnly        Flags = 0x00000008
+	SuccessfulAccess   Flags = 0x00000010
+	FailedAccess       Flags = 0x00000020
+	// IdentifierGroup indicates that the principal of the ACE is a group
+	IdentifierGroup Flags = 0x00000040
+	// Inherited indicates that the ACE was inherited from the parent directory
+	Inherited Flags = 0x00000080
+)
+
+// Access rights. They have the same values as the pkg/access file rights.
+const (
+	ReadData           uint32 = 0x00000001
+	ListDirectory      uint32 = 0x00000001
+	WriteData          uint32 = 0x00000002
+	AddFile            uint32 = 0x00000002
+	AppendData         uint32 = 0x00000004
+	AddSubdirectory    uint32 = 0x00000004
+	ReadNamedAttrs     uint32 = 0x00000008
+	WriteNamedAttrs    uint32 = 0x00000010
+	Execute            uint32 = 0x00000020
+	DeleteChild        uint32 = 0x00000040
+	ReadAttributes     uint32 = 0x00000080
+	WriteAttributes    uint32 = 0x00000100
+	WriteRetention     uint32 = 0x00000200
+	WriteRetentionHold uint32 = 0x00000400
+	Delete             uint32 = 0x00010000
+	ReadACL            uint32 = 0x00020000
+	WriteACL           uint32 = 0x00040000
+	WriteOwner         uint32 = 0x00080000
+	Synchronize        uint32 = 0x00100000
+
+	// allRights holds all the access rights defined by NFSv4
+	allRights = 0x001F07FF
+)
+
+// Special principals
+const (
+	// Owner is the owner of the file
+	Owner = "OWNER@"
+	// Group is the owning group of the file
+	Group = "GROUP@"
+	// Everyone is everyone, including the owner and the owning group
+	Everyone = "EVERYONE@"
+)
+
+// ACE is an NFSv4 access control entry
+type ACE struct {
+	Type  ACEType
+	Flags Flags
+	Mask  uint32
+	// Who is the principal of the ACE: one of the special principals, or a user or group (if IdentifierGroup is set)
+	// written as name@domain or as a numeric ID
+	Who string
+}
+
+// ACL is an ordered list of NFSv4 ACEs
+type ACL []ACE
@@ -0,0 +1,134 @@
+package nfs4acl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXattr(t *testing.T) {
+	acl := ACL{
+		{Type: Allow, Flags: FileInherit | DirectoryInherit, Mask: 0x001F01FF, Who: Owner},
+		{Type: Deny, Flags: IdentifierGroup, Mask: WriteData | AppendData, Who: "1001"},
+		{Type: Allow, Flags: IdentifierGroup | Inherited, Mask: ReadData | Execute | Synchronize, Who: Group},
+		{Type: Audit, Flags: FailedAccess, Mask: Delete, Who: "alice@example.com"},
+	}
+	b, err := acl.MarshalXattr()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 4}, b[:4])
+	// OWNER@ is 6 bytes long, so it is padded with 2 bytes
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 3, 0, 0x1F, 0x01, 0xFF, 0, 0, 0, 6, 'O', 'W', 'N', 'E', 'R', '@', 0, 0}, b[4:28])
+
+	parsed, err := ParseXattr(b)
+	require.NoError(t, err)
+	assert.Equal(t, acl, parsed)
+
+	for name, invalid := range map[string][]byte{
+		"Empty":          {},
+		"Too many ACEs":  {0, 0, 0, 9, 0, 0, 0, 0},
+		"Truncated":      b[:len(b)-4],
+		"Trailing bytes": append(append([]byte{}, b...), 0, 0, 0, 0),
+		"Long principal": binary.BigEndian.AppendUint32([]byte{0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, 1<<20),
+	} {
+		_, err := ParseXattr(invalid)
+		assert.Error(t, err, name)
+	}
+	_, err = ACL{{Type: Allow, Mask: ReadData}}.MarshalXattr()
+	assert.Error(t, err)
+	_, err = ACL{{Type: Allow, Mask: 0x10000000, Who: Everyone}}.MarshalXattr()
+	assert.Error(t, err, "generic rights are not NFSv4 rights")
+}
+
+func TestACL(t *testing.T) {
+	domain := map[string]descriptor.SID{"alice@example.com": descriptor.MustParseSID("S-1-5-21-1-2-3-1001")}
+	p := Principals{
+		SID: func(who string, group bool) (descriptor.SID, error) {
+			if sid, ok := domain[who]; ok {
+				return sid, nil
+			}
+			return "", fmt.Errorf("unknown principal")
+		},
+		Who: func(sid descriptor.SID) (string, bool, error) {
+			for who, s := range domain {
+				if s == sid {
+					return who, false, nil
+				}
+			}
+			return "", false, fmt.Errorf("unknown SID")
+		},
+	}
+	acl := ACL{
+		{Type: Allow, Flags: FileInherit | DirectoryInherit | InheritOnly, Mask: 0x001F01FF, Who: Owner},
+		{Type: Deny, Flags: IdentifierGroup, Mask: WriteData | AppendData, Who: "1001"},
+		{Type: Allow, Flags: IdentifierGroup | Inherited | NoPropagateInherit, Mask: ReadData | Execute | Synchronize, Who: Group},
+		{Type: Allow, Mask: ReadData | WriteRetention, Who: "1000"},
+		{Type: Allow, Mask: ReadACL, Who: Everyone},
+		{Type: Audit, Flags: FailedAccess | SuccessfulAccess, Mask: Delete, Who: "alice@example.com"},
+		{Type: Alarm, Mask: WriteACL, Who: "alice@example.com"},
+	}
+	owner, group := descriptor.UnixUserSID(500), descriptor.UnixGroupSID(500)
+	converted, err := acl.ToACL(owner, group, p)
+	require.NoError(t, err)
+	assert.Equal(t, descriptor.ACL{
+		{Type: descriptor.AccessAllowed, Flags: descriptor.ObjectInherit | descriptor.ContainerInherit | descriptor.InheritOnly, Mask: 0x001F01FF, SID: descriptor.CreatorOwner},
+		{Type: descriptor.AccessDenied, Mask: 0x6, SID: descriptor.UnixGroupSID(1001)},
+		{Type: descriptor.AccessAllowed, Flags: descriptor.Inherited | descriptor.NoPropagateInherit, Mask: 0x100021, SID: group},
+		{Type: descriptor.AccessAllowed, Mask: 0x201, SID: descriptor.UnixUserSID(1000)},
+		{Type: descriptor.AccessAllowed, Mask: 0x20000, SID: descriptor.Everyone},
+		{Type: descriptor.SystemAudit, Flags: descriptor.AuditFlags, Mask: 0x10000, SID: domain["alice@example.com"]},
+		{Type: descriptor.ACEType(3), Mask: 0x40000, SID: domain["alice@example.com"]},
+	}, converted)
+
+	back, err := FromACL(converted, owner, group, p)
+	require.NoError(t, err)
+	assert.Equal(t, acl, back)
+
+	_, err = acl.ToACL(owner, group, Principals{})
+	assert.Error(t, err)
+	_, err = acl.ToACL("", "", p)
+	assert.Error(t, err, "the owner is required to convert effective OWNER@ ACEs")
+	_, err = FromACL(descriptor.ACL{descriptor.LabelACE(descriptor.HighIntegrity, descriptor.NoWriteUp, 0)}, owner, group, p)
+	assert.Error(t, err)
+	_, err = FromACL(descriptor.ACL{{Type: descriptor.AccessAllowed, Mask: 1, SID: descriptor.BuiltinUsers}}, owner, group, Principals{})
+	assert.Error(t, err)
+	_, err = ACL{{Type: Allow, Flags: 0x100, Mask: 1, Who: Everyone}}.ToACL(owner, group, p)
+	assert.Error(t, err)
+}
+
+func TestOwnerAndGroup(t *testing.T) {
+	owner, group := descriptor.UnixUserSID(1000), descriptor.UnixGroupSID(100)
+
+	// an effective and inheritable OWNER@ ACE is split
+	converted, err := ACL{{Type: Allow, Flags: FileInherit | DirectoryInherit, Mask: ReadData | WriteData, Who: Owner}}.ToACL(owner, group, Principals{})
+	require.NoError(t, err)
+	assert.Equal(t, descriptor.ACL{
+		{Type: descriptor.AccessAllowed, Mask: 0x3, SID: owner},
+		{Type: descriptor.AccessAllowed, Flags: descriptor.ObjectInherit | descriptor.ContainerInherit | descriptor.InheritOnly, Mask: 0x3, SID: descriptor.CreatorOwner},
+	}, converted)
+	back, err := FromACL(converted, owner, group, Principals{})
+	require.NoError(t, err)
+	assert.Equal(t, ACL{
+		{Type: Allow, Mask: ReadData | WriteData, Who: Owner},
+		{Type: Allow, Flags: FileInherit | DirectoryInherit | InheritOnly, Mask: ReadData | WriteData, Who: Owner},
+	}, back)
+
+	// Creator Owner only applies to the files and directories that inherit the ACE, and generic rights are expanded
+	nfs4, err := FromACL(descriptor.ACL{
+		{Type: descriptor.AccessAllowed, Flags: descriptor.ObjectInherit | descriptor.ContainerInherit, Mask: 0x10000000, SID: descriptor.CreatorOwner},
+		{Type: descriptor.AccessAllowed, Mask: 0x10000000, SID: descriptor.CreatorGroup},
+		{Type: descriptor.AccessAllowed, Flags: descriptor.ContainerInherit, Mask: 0x80000000, SID: owner},
+		{Type: descriptor.AccessAllowed, Mask: 0x80000000, SID: group},
+	}, owner, group, Principals{})
+	require.NoError(t, err)
+	assert.Equal(t, ACL{
+		{Type: Allow, Flags: FileInherit | DirectoryInherit | InheritOnly, Mask: 0x001F01FF, Who: Owner},
+		{Type: Allow, Flags: DirectoryInherit, Mask: 0x00120089, Who: "1000"},
+		{Type: Allow, Flags: IdentifierGroup, Mask: 0x00120089, Who: Group},
+	}, nfs4)
+	_, err = nfs4.MarshalXattr()
+	assert.NoError(t, err)
+}
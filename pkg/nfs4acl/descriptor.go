@@ -0,0 +1,205 @@
+package nfs4acl
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// flagMapping lists the NFSv4 flags that have an equivalent ACE flag. IdentifierGroup has none, since SIDs tell users
+// from groups.
+var flagMapping = []struct {
+	nfs4 Flags
+	ace  descriptor.ACEFlags
+}{
+	{FileInherit, descriptor.ObjectInherit},
+	{DirectoryInherit, descriptor.ContainerInherit},
+	{NoPropagateInherit, descriptor.NoPropagateInherit},
+	{InheritOnly, descriptor.InheritOnly},
+	{SuccessfulAccess, descriptor.SuccessfulAccess},
+	{FailedAccess, descriptor.FailedAccess},
+	{Inherited, descriptor.Inherited},
+}
+
+// inheritable are the ACE flags that make an ACE inherited by new files and directories
+const inheritable = descriptor.ObjectInherit | descriptor.ContainerInherit
+
+// Principals converts named principals to and from SIDs. The zero value only converts numeric principals, which map
+// to the S-1-22-1-<uid> and S-1-22-2-<gid> SIDs of Unix accounts.
+type Principals struct {
+	// SID returns the SID of a named principal, such as alice@example.com
+	SID func(who string, group bool) (descriptor.SID, error)
+	// Who returns the named principal of a SID that is neither a Unix account nor a special SID
+	Who func(sid descriptor.SID) (who string, group bool, err error)
+}
+
+// ToACL converts the NFSv4 ACL to an ACL of the descriptor package, for a file / directory owned by the owner and
+// group SIDs. The ACE types and access masks are kept as they are, and the flags are mapped to the ACE flags with the
+// same meaning. EVERYONE@ maps to Everyone, and numeric principals to the SIDs of Unix accounts.
+//
+// Windows ignores Creator Owner and Creator Group in the ACEs that apply to the file / directory itself, so OWNER@ and
+// GROUP@ map to the owner and group SIDs in these ACEs, and to Creator Owner and Creator Group in inherit-only ACEs.
+// An OWNER@ or GROUP@ ACE that is both effective and inheritable is split into an ACE for the owner or group and an
+// inherit-only ACE for Creator Owner or Creator Group, as Windows does when it propagates such ACEs.
+func (a ACL) ToACL(owner, group descriptor.SID, p Principals) (descriptor.ACL, error) {
+	acl := make(descriptor.ACL, 0, len(a))
+	for i, ace := range a {
+		if ace.Type > Alarm {
+			return nil, fmt.Errorf("ACE %d: unknown type %s", i, ace.Type)
+		}
+		flags, err := toACEFlags(ace.Flags)
+		if err != nil {
+			return nil, fmt.Errorf("ACE %d: %w", i, err)
+		}
+		converted := descriptor.ACE{Type: descriptor.ACEType(ace.Type), Flags: flags, Mask: ace.Mask}
+		switch ace.Who {
+		case Owner:
+			if owner == "" && flags&descriptor.InheritOnly == 0 {
+				return nil, fmt.Errorf("ACE %d: the owner is required to convert %s", i, Owner)
+			}
+			acl = append(acl, creatorACEs(converted, owner, descriptor.CreatorOwner)...)
+		case Group:
+			if group == "" && flags&descriptor.InheritOnly == 0 {
+				return nil, fmt.Errorf("ACE %d: the group is required to convert %s", i, Group)
+			}
+			acl = append(acl, creatorACEs(converted, group, descriptor.CreatorGroup)...)
+		default:
+			if converted.SID, err = p.sid(ace.Who, ace.Flags&IdentifierGroup != 0); err != nil {
+				return nil, fmt.Errorf("ACE %d: %w", i, err)
+			}
+			acl = append(acl, converted)
+		}
+	}
+	return acl, nil
+}
+
+// creatorACEs returns the ACEs of an OWNER@ or GROUP@ ACE, given the owner or group SID and the Creator Owner or
+// Creator Group SID
+func creatorACEs(ace descriptor.ACE, sid, creator descriptor.SID) descriptor.ACL {
+	if ace.Flags&descriptor.InheritOnly != 0 {
+		ace.SID = creator
+		return descriptor.ACL{ace}
+	}
+	effective := ace
+	effective.SID = sid
+	if ace.Flags&inheritable == 0 {
+		return descriptor.ACL{effective}
+	}
+	effective.Flags &^= descriptor.InheritanceFlags
+	ace.SID = creator
+	ace.Flags |= descriptor.InheritOnly
+	return descriptor.ACL{effective, ace}
+}
+
+// FromACL converts an ACL of the descriptor package, for a file / directory owned by the owner and group SIDs, to an
+// NFSv4 ACL. It is the inverse of ACL.ToACL: the ACEs for the owner and group that are not inheritable map to OWNER@
+// and GROUP@, and so do the ACEs for Creator Owner and Creator Group, which become inherit-only since Windows only
+// applies them to the files and directories that inherit them. The Creator Owner and Creator Group ACEs that are not
+// inheritable have no effect, and are dropped.
+//
+// NFSv4 has no generic rights, so they are expanded with access.FileGenericMapping. Mandatory label ACEs have no NFSv4
+// equivalent, and return an error.
+func FromACL(acl descriptor.ACL, owner, group descriptor.SID, p Principals) (ACL, error) {
+	result := make(ACL, 0, len(acl))
+	for i, ace := range acl {
+		if ace.Type > descriptor.ACEType(Alarm) {
+			return nil, fmt.Errorf("ACE %d: %s ACEs have no NFSv4 equivalent", i, ace.Type)
+		}
+		flags, err := fromACEFlags(ace.Flags)
+		if err != nil {
+			return nil, fmt.Errorf("ACE %d: %w", i, err)
+		}
+		var who string
+		var isGroup bool
+		switch {
+		case ace.SID == descriptor.CreatorOwner || ace.SID == descriptor.CreatorGroup:
+			if ace.Flags&inheritable == 0 {
+				continue
+			}
+			flags |= InheritOnly
+			who, isGroup = Owner, false
+			if ace.SID == descriptor.CreatorGroup {
+				who, isGroup = Group, true
+			}
+		case ace.Flags&inheritable == 0 && ace.SID == owner:
+			who = Owner
+		case ace.Flags&inheritable == 0 && ace.SID == group:
+			who, isGroup = Group, true
+		default:
+			if who, isGroup, err = p.who(ace.SID); err != nil {
+				return nil, fmt.Errorf("ACE %d: %w", i, err)
+			}
+		}
+		// GROUP@ is a group, and is flagged as such by Linux and the NFS servers
+		if isGroup {
+			flags |= IdentifierGroup
+		}
+		mask := access.FileGenericMapping.Expand(ace.Mask)
+		result = append(result, ACE{Type: ACEType(ace.Type), Flags: flags, Mask: mask, Who: who})
+	}
+	return result, nil
+}
+
+func toACEFlags(flags Flags) (descriptor.ACEFlags, error) {
+	var result descriptor.ACEFlags
+	rest := flags &^ IdentifierGroup
+	for _, m := range flagMapping {
+		if flags&m.nfs4 != 0 {
+			result |= m.ace
+			rest &^= m.nfs4
+		}
+	}
+	if rest != 0 {
+		return 0, fmt.Errorf("unknown flags %#x", uint32(rest))
+	}
+	return result, nil
+}
+
+func fromACEFlags(flags descriptor.ACEFlags) (Flags, error) {
+	var result Flags
+	rest := flags
+	for _, m := range flagMapping {
+		if flags&m.ace != 0 {
+			result |= m.nfs4
+			rest &^= m.ace
+		}
+	}
+	if rest != 0 {
+		return 0, fmt.Errorf("unknown flags %#x", uint8(rest))
+	}
+	return result, nil
+}
+
+func (p Principals) sid(who string, group bool) (descriptor.SID, error) {
+	if who == Everyone {
+		return descriptor.Everyone, nil
+	}
+	if id, err := strconv.ParseUint(who, 10, 32); err == nil {
+		if group {
+			return descriptor.UnixGroupSID(uint32(id)), nil
+		}
+		return descriptor.UnixUserSID(uint32(id)), nil
+	}
+	if p.SID == nil {
+		return "", fmt.Errorf("cannot resolve principal %q", who)
+	}
+	return p.SID(who, group)
+}
+
+func (p Principals) who(sid descriptor.SID) (string, bool, error) {
+	if sid == descriptor.Everyone {
+		return Everyone, false, nil
+	}
+	if uid, ok := sid.UnixUser(); ok {
+		return strconv.FormatUint(uint64(uid), 10), false, nil
+	}
+	if gid, ok := sid.UnixGroup(); ok {
+		return strconv.FormatUint(uint64(gid), 10), true, nil
+	}
+	if p.Who == nil {
+		return "", false, fmt.Errorf("%s has no NFSv4 principal", sid)
+	}
+	return p.Who(sid)
+}
@@ -0,0 +1,103 @@
+package nfs4acl
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Xattr is the extended attribute that holds the NFSv4 ACL of a file on Linux
+const Xattr = "system.nfs4_acl"
+
+// maxWhoLength is the maximum length of a principal, which bounds the allocations made by ParseXattr
+const maxWhoLength = 1024
+
+// ParseXattr decodes an NFSv4 ACL in the XDR format of the system.nfs4_acl extended attribute: the number of ACEs,
+// followed by the type, flags, access mask and principal of each ACE, as big-endian 32-bit integers and a
+// length-prefixed string padded to 4 bytes.
+func ParseXattr(b []byte) (ACL, error) {
+	d := decoder{b: b}
+	count, err := d.uint32()
+	if err != nil {
+		return nil, err
+	}
+	// each ACE takes at least 16 bytes, which bounds the number of ACEs
+	if uint64(count)*16 > uint64(len(d.b)) {
+		return nil, fmt.Errorf("invalid NFSv4 ACL: %d ACEs do not fit in %d bytes", count, len(b))
+	}
+	acl := make(ACL, 0, count)
+	for i := uint32(0); i < count; i++ {
+		var ace ACE
+		var v [3]uint32
+		for j := range v {
+			if v[j], err = d.uint32(); err != nil {
+				return nil, fmt.Errorf("ACE %d: %w", i, err)
+			}
+		}
+		ace.Type, ace.Flags, ace.Mask = ACEType(v[0]), Flags(v[1]), v[2]
+		if ace.Who, err = d.string(); err != nil {
+			return nil, fmt.Errorf("ACE %d: %w", i, err)
+		}
+		acl = append(acl, ace)
+	}
+	if len(d.b) != 0 {
+		return nil, fmt.Errorf("invalid NFSv4 ACL: %d trailing bytes", len(d.b))
+	}
+	return acl, nil
+}
+
+// MarshalXattr encodes the ACL in the XDR format of the system.nfs4_acl extended attribute. Access masks with rights
+// that NFSv4 does not define, such as generic rights, are rejected.
+func (a ACL) MarshalXattr() ([]byte, error) {
+	b := binary.BigEndian.AppendUint32(nil, uint32(len(a)))
+	for i, ace := range a {
+		if ace.Who == "" || len(ace.Who) > maxWhoLength {
+			return nil, fmt.Errorf("ACE %d: invalid principal %q", i, ace.Who)
+		}
+		if ace.Mask&^allRights != 0 {
+			return nil, fmt.Errorf("ACE %d: access mask %#x has rights that NFSv4 does not define", i, ace.Mask)
+		}
+		b = binary.BigEndian.AppendUint32(b, uint32(ace.Type))
+		b = binary.BigEndian.AppendUint32(b, uint32(ace.Flags))
+		b = binary.BigEndian.AppendUint32(b, ace.Mask)
+		b = binary.BigEndian.AppendUint32(b, uint32(len(ace.Who)))
+		b = append(b, ace.Who...)
+		b = append(b, make([]byte, padding(len(ace.Who)))...)
+	}
+	return b, nil
+}
+
+// padding returns the number of bytes that align XDR opaque data of length n to 4 bytes
+func padding(n int) int {
+	return (4 - n%4) % 4
+}
+
+// decoder reads XDR values
+type decoder struct {
+	b []byte
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	if len(d.b) < 4 {
+		return 0, fmt.Errorf("invalid NFSv4 ACL: unexpected end of data")
+	}
+	v := binary.BigEndian.Uint32(d.b)
+	d.b = d.b[4:]
+	return v, nil
+}
+
+func (d *decoder) string() (string, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return "", err
+	}
+	if n > maxWhoLength {
+		return "", fmt.Errorf("invalid NFSv4 ACL: principal of %d bytes", n)
+	}
+	length := int(n) + padding(int(n))
+	if len(d.b) < length {
+		return "", fmt.Errorf("invalid NFSv4 ACL: unexpected end of data")
+	}
+	s := string(d.b[:n])
+	d.b = d.b[length:]
+	return s, nil
+}
@@ -0,0 +1,280 @@
+// Package ntacl decodes and encodes the security.NTACL extended attribute, in which the acl_xattr VFS module of Samba
+// stores the Windows security descriptor of the files of a share, next to the POSIX ACL that it maps it to.
+//
+// The attribute holds an xattr_NTACL structure in NDR (the DCE/RPC marshalling format): a version number, followed
+// by the self-relative security descriptor and, from version 2 on, hashes that Samba uses to detect changes made to
+// the POSIX ACL by other processes.
+package ntacl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// Xattr is the extended attribute in which Samba stores security descriptors. Shares can use another name with the
+// acl_xattr:security_acl_name option, e.g. user.NTACL, which does not require CAP_SYS_ADMIN.
+const Xattr = "security.NTACL"
+
+// HashType identifies the algorithm of the hashes of version 3 and 4 blobs
+type HashType uint16
+
+// Hash types
+const (
+	HashNone   HashType = 0
+	HashSHA256 HashType = 1
+)
+
+const (
+	// hashLength is the size of the hash fields of version 3 and 4 blobs
+	hashLength = 64
+	// hashV2Length is the size of the hash field of version 2 blobs
+	hashV2Length = 16
+	// maxDescriptionLength bounds the description of version 4 blobs
+	maxDescriptionLength = 1024
+	// firstReferentID is the value of the first non-NULL pointer written by Samba
+	firstReferentID = 0x00020000
+	// ntEpochOffset is the number of 100-nanosecond intervals between 1601-01-01 and 1970-01-01
+	ntEpochOffset = 116444736000000000
+)
+
+// NTACL is the content of the security.NTACL extended attribute
+type NTACL struct {
+	// Version is the version of the blob, from 1 to 4
+	Version uint16
+	// SD is the security descriptor of the file
+	SD *descriptor.SecurityDescriptor
+	// HashType is the algorithm of Hash and SysACLHash in version 3 and 4 blobs
+	HashType HashType
+	// Hash is, in version 2 to 4 blobs, the hash of the security descriptor that Samba derived from the POSIX ACL
+	// when it stored SD. It is 16 bytes long in version 2 blobs, and 64 bytes long in version 3 and 4 blobs.
+	Hash []byte
+	// Description records what created a version 4 blob, e.g. "posix_acl"
+	Description string
+	// Time is the time at which a version 4 blob was created
+	Time time.Time
+	// SysACLHash is, in version 4 blobs, the 64-byte hash of the POSIX ACL of the file when SD was stored
+	SysACLHash []byte
+}
+
+// New returns a version 1 blob holding the security descriptor. Samba checks the hashes of version 3 and 4 blobs
+// against the POSIX ACL of the file, which only Samba can compute, and ignores the blob when they do not match, while
+// it always uses the security descriptor of version 1 and 2 blobs, as written by its NTVFS file server and by
+// samba-tool ntacl.
+func New(sd *descriptor.SecurityDescriptor) *NTACL {
+	return &NTACL{Version: 1, SD: sd}
+}
+
+// Parse decodes the content of the security.NTACL extended attribute
+func Parse(b []byte) (*NTACL, error) {
+	d := decoder{b: b}
+	version := d.uint16()
+	level := d.uint16()
+	if d.err == nil && level != version {
+		return nil, fmt.Errorf("invalid NTACL: union level %d does not match version %d", level, version)
+	}
+	if d.err == nil && (version < 1 || version > 4) {
+		return nil, fmt.Errorf("unsupported NTACL version %d", version)
+	}
+	n := &NTACL{Version: version}
+	if d.uint32() == 0 && d.err == nil {
+		return nil, fmt.Errorf("invalid NTACL: no security descriptor")
+	}
+	if version > 1 {
+		// the security_descriptor_hash_vN structure, whose first member points to the security descriptor
+		d.align(4)
+		if d.uint32() == 0 && d.err == nil {
+			return nil, fmt.Errorf("invalid NTACL: no security descriptor")
+		}
+		switch version {
+		case 2:
+			n.Hash = d.bytes(hashV2Length)
+		case 3, 4:
+			n.HashType = HashType(d.uint16())
+			n.Hash = d.bytes(hashLength)
+		}
+		if version == 4 {
+			n.Description = d.string()
+			n.Time = ntTime(d.uint64())
+			n.SysACLHash = d.bytes(hashLength)
+		}
+	}
+	d.align(4)
+	if d.err != nil {
+		return nil, d.err
+	}
+	sd, err := descriptor.ParseSecurityDescriptor(d.b[d.offset:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid NTACL: %w", err)
+	}
+	n.SD = sd
+	return n, nil
+}
+
+// MarshalBinary encodes the blob in the format of the security.NTACL extended attribute. Hashes that are shorter
+// than the size of their field are padded with zeros.
+func (n *NTACL) MarshalBinary() ([]byte, error) {
+	if n.Version < 1 || n.Version > 4 {
+		return nil, fmt.Errorf("unsupported NTACL version %d", n.Version)
+	}
+	if n.SD == nil {
+		return nil, fmt.Errorf("NTACL has no security descriptor")
+	}
+	sd, err := n.SD.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	var e encoder
+	e.uint16(n.Version)
+	e.uint16(n.Version)
+	e.uint32(firstReferentID)
+	if n.Version > 1 {
+		e.uint32(firstReferentID + 4)
+		switch n.Version {
+		case 2:
+			if err := e.hash(n.Hash, hashV2Length); err != nil {
+				return nil, err
+			}
+		case 3, 4:
+			e.uint16(uint16(n.HashType))
+			if err := e.hash(n.Hash, hashLength); err != nil {
+				return nil, err
+			}
+		}
+		if n.Version == 4 {
+			if len(n.Description) > maxDescriptionLength {
+				return nil, fmt.Errorf("NTACL description is too long: %d bytes", len(n.Description))
+			}
+			e.b = append(append(e.b, n.Description...), 0)
+			e.align(4)
+			e.uint64(ntTimeFromTime(n.Time))
+			if err := e.hash(n.SysACLHash, hashLength); err != nil {
+				return nil, err
+			}
+		}
+	}
+	e.align(4)
+	return append(e.b, sd...), nil
+}
+
+// ntTime converts an NTTIME, the number of 100-nanosecond intervals since 1601-01-01 UTC, to a time. Zero is
+// converted to the zero time.
+func ntTime(t uint64) time.Time {
+	if t == 0 {
+		return time.Time{}
+	}
+	unix := int64(t - ntEpochOffset)
+	return time.Unix(unix/1e7, unix%1e7*100).UTC()
+}
+
+// ntTimeFromTime converts a time to an NTTIME. The zero time is converted to zero.
+func ntTimeFromTime(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64(t.Unix()*1e7+int64(t.Nanosecond()/100)) + ntEpochOffset
+}
+
+// decoder reads little-endian NDR values, whose alignment is relative to the start of the blob. The first error is
+// kept in err, and the values read after it are zero.
+type decoder struct {
+	b      []byte
+	offset int
+	err    error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return make([]byte, n)
+	}
+	if len(d.b)-d.offset < n {
+		d.err = fmt.Errorf("invalid NTACL: unexpected end of data")
+		return make([]byte, n)
+	}
+	b := d.b[d.offset : d.offset+n]
+	d.offset += n
+	return b
+}
+
+func (d *decoder) align(n int) {
+	if pad := (n - d.offset%n) % n; pad > 0 {
+		d.next(pad)
+	}
+}
+
+func (d *decoder) uint16() uint16 {
+	d.align(2)
+	return binary.LittleEndian.Uint16(d.next(2))
+}
+
+func (d *decoder) uint32() uint32 {
+	d.align(4)
+	return binary.LittleEndian.Uint32(d.next(4))
+}
+
+// uint64 reads an NDR udlong, which is made of two 32-bit integers and is aligned to 4 bytes
+func (d *decoder) uint64() uint64 {
+	low := d.uint32()
+	return uint64(d.uint32())<<32 | uint64(low)
+}
+
+func (d *decoder) bytes(n int) []byte {
+	return append([]byte(nil), d.next(n)...)
+}
+
+// string reads a null-terminated UTF-8 string
+func (d *decoder) string() string {
+	if d.err != nil {
+		return ""
+	}
+	rest := d.b[d.offset:]
+	for i, c := range rest {
+		if c == 0 {
+			d.offset += i + 1
+			return string(rest[:i])
+		}
+		if i == maxDescriptionLength {
+			break
+		}
+	}
+	d.err = fmt.Errorf("invalid NTACL: unterminated description")
+	return ""
+}
+
+// encoder writes little-endian NDR values
+type encoder struct {
+	b []byte
+}
+
+func (e *encoder) align(n int) {
+	for len(e.b)%n != 0 {
+		e.b = append(e.b, 0)
+	}
+}
+
+func (e *encoder) uint16(v uint16) {
+	e.align(2)
+	e.b = binary.LittleEndian.AppendUint16(e.b, v)
+}
+
+func (e *encoder) uint32(v uint32) {
+	e.align(4)
+	e.b = binary.LittleEndian.AppendUint32(e.b, v)
+}
+
+func (e *encoder) uint64(v uint64) {
+	e.uint32(uint32(v))
+	e.uint32(uint32(v >> 32))
+}
+
+// hash writes a fixed-size hash field, padding the hash with zeros
+func (e *encoder) hash(hash []byte, size int) error {
+	if len(hash) > size {
+		return fmt.Errorf("NTACL hash is too long: %d bytes, expected at most %d", len(hash), size)
+	}
+	e.b = append(e.b, hash...)
+	e.b = append(e.b, make([]byte, size-len(hash))...)
+	return nil
+}
@@ -0,0 +1,185 @@
+package ntacl
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNTACL(t *testing.T) {
+	sd, err := descriptor.ParseSDDL("O:S-1-22-1-1000G:S-1-22-2-1000D:PAI(A;OICI;FA;;;S-1-22-1-1000)(A;OICI;0x1200a9;;;WD)")
+	require.NoError(t, err)
+	sdBytes, err := sd.MarshalBinary()
+	require.NoError(t, err)
+
+	v1, err := New(sd).MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 0, 1, 0, 0, 0, 2, 0}, v1[:8])
+	assert.Equal(t, sdBytes, v1[8:])
+	parsed, err := Parse(v1)
+	require.NoError(t, err)
+	assert.Equal(t, New(sd), parsed)
+
+	hash := bytes.Repeat([]byte{0xAB}, 32)
+	v4 := &NTACL{
+		Version:     4,
+		SD:          sd,
+		HashType:    HashSHA256,
+		Hash:        append(hash, make([]byte, 32)...),
+		Description: "posix_acl",
+		Time:        time.Date(2024, 5, 6, 7, 8, 9, 100, time.UTC),
+		SysACLHash:  append(hash, make([]byte, 32)...),
+	}
+	b, err := v4.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{4, 0, 4, 0, 0, 0, 2, 0, 4, 0, 2, 0, 1, 0}, b[:14])
+	assert.Equal(t, hash, b[14:46])
+	assert.Equal(t, []byte("posix_acl\x00"), b[78:88])
+	assert.Equal(t, sdBytes, b[160:])
+	parsed, err = Parse(b)
+	require.NoError(t, err)
+	assert.Equal(t, v4, parsed)
+
+	// the security descriptor of version 3 blobs is aligned to 4 bytes after the 78 bytes of the header
+	v3 := &NTACL{Version: 3, SD: sd, HashType: HashSHA256, Hash: make([]byte, 64)}
+	b, err = v3.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, sdBytes, b[80:])
+	parsed, err = Parse(b)
+	require.NoError(t, err)
+	assert.Equal(t, v3, parsed)
+
+	v2 := &NTACL{Version: 2, SD: sd, Hash: make([]byte, 16)}
+	b, err = v2.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, sdBytes, b[28:])
+	parsed, err = Parse(b)
+	require.NoError(t, err)
+	assert.Equal(t, v2, parsed)
+
+	_, err = (&NTACL{Version: 5, SD: sd}).MarshalBinary()
+	assert.Error(t, err)
+	_, err = (&NTACL{Version: 1}).MarshalBinary()
+	assert.Error(t, err)
+	_, err = (&NTACL{Version: 2, SD: sd, Hash: make([]byte, 32)}).MarshalBinary()
+	assert.Error(t, err)
+
+	for name, invalid := range map[string][]byte{
+		"Empty":          {},
+		"Version":        {5, 0, 5, 0, 0, 0, 2, 0},
+		"Level":          {1, 0, 2, 0, 0, 0, 2, 0},
+		"NULL pointer":   append([]byte{1, 0, 1, 0, 0, 0, 0, 0}, sdBytes...),
+		"Truncated hash": {2, 0, 2, 0, 0, 0, 2, 0, 4, 0, 2, 0, 0},
+		"Unterminated":   append([]byte{4, 0, 4, 0, 0, 0, 2, 0, 4, 0, 2, 0, 1, 0}, bytes.Repeat([]byte{'a'}, 2000)...),
+		"Descriptor":     v1[:12],
+	} {
+		_, err := Parse(invalid)
+		assert.Error(t, err, name)
+	}
+}
+
+// sambaSD is the self-relative security descriptor O:S-1-22-1-1000G:S-1-22-2-1000D:P(A;;FA;;;S-1-22-1-1000), laid
+// out as Samba's NDR marshalling writes it: the owner, group and DACL follow the header in this order.
+const sambaSD = "" +
+	"01 00 04 90 14 00 00 00 24 00 00 00 00 00 00 00 34 00 00 00" + // header: revision, control, offsets
+	"01 02 00 00 00 00 00 16 01 00 00 00 e8 03 00 00" + // owner S-1-22-1-1000
+	"01 02 00 00 00 00 00 16 02 00 00 00 e8 03 00 00" + // group S-1-22-2-1000
+	"02 00 20 00 01 00 00 00" + // DACL header: revision 2, 32 bytes, 1 ACE
+	"00 00 18 00 ff 01 1f 00 01 02 00 00 00 00 00 16 01 00 00 00 e8 03 00 00" // (A;;FA;;;S-1-22-1-1000)
+
+// sambaHash is a SHA-256 hash, which Samba stores in 64-byte fields padded with zeros
+const sambaHash = "" +
+	"00 01 02 03 04 05 06 07 08 09 0a 0b 0c 0d 0e 0f 10 11 12 13 14 15 16 17 18 19 1a 1b 1c 1d 1e 1f" +
+	"00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00"
+
+func fromHex(t *testing.T, s ...string) []byte {
+	b, err := hex.DecodeString(strings.ReplaceAll(strings.Join(s, ""), " ", ""))
+	require.NoError(t, err)
+	return b
+}
+
+// TestSambaLayout checks the blobs against the xattr_NTACL structure of Samba's librpc/idl/xattr.idl, assembled field
+// by field, so that a layout mistake shared by the decoder and the encoder is caught
+func TestSambaLayout(t *testing.T) {
+	sd, err := descriptor.ParseSDDL("O:S-1-22-1-1000G:S-1-22-2-1000D:P(A;;FA;;;S-1-22-1-1000)")
+	require.NoError(t, err)
+	hash := fromHex(t, sambaHash)
+
+	testCases := []struct {
+		name     string
+		blob     []byte
+		expected *NTACL
+	}{
+		{
+			name: "Version 1",
+			blob: fromHex(t,
+				"01 00 01 00", // version, union level
+				"00 00 02 00", // referent ID of the security descriptor
+				sambaSD,
+			),
+			expected: &NTACL{Version: 1, SD: sd},
+		},
+		{
+			name: "Version 3",
+			blob: fromHex(t,
+				"03 00 03 00",      // version, union level
+				"00 00 02 00",      // referent ID of security_descriptor_hash_v3
+				"04 00 02 00",      // referent ID of the security descriptor
+				"01 00", sambaHash, // hash_type (SHA-256), hash[64]
+				"00 00", // the security descriptor is aligned to 4 bytes
+				sambaSD,
+			),
+			expected: &NTACL{Version: 3, SD: sd, HashType: HashSHA256, Hash: hash},
+		},
+		{
+			name: "Version 4",
+			blob: fromHex(t,
+				"04 00 04 00",      // version, union level
+				"00 00 02 00",      // referent ID of security_descriptor_hash_v4
+				"04 00 02 00",      // referent ID of the security descriptor
+				"01 00", sambaHash, // hash_type (SHA-256), hash[64]
+				"70 6f 73 69 78 5f 61 63 6c 00", // description "posix_acl", NUL-terminated and not aligned
+				"80 92 6c 26 84 9f da 01",       // time, an NTTIME aligned to 4 bytes
+				sambaHash,                       // sys_acl_hash[64]
+				sambaSD,
+			),
+			expected: &NTACL{
+				Version:     4,
+				SD:          sd,
+				HashType:    HashSHA256,
+				Hash:        hash,
+				Description: "posix_acl",
+				Time:        time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC),
+				SysACLHash:  hash,
+			},
+		},
+		{
+			name: "Version 4 with an empty description",
+			blob: fromHex(t,
+				"04 00 04 00", "00 00 02 00", "04 00 02 00", "00 00", sambaHash,
+				"00", // empty description
+				"00", // the NTTIME is aligned to 4 bytes
+				"00 00 00 00 00 00 00 00",
+				sambaHash,
+				sambaSD,
+			),
+			expected: &NTACL{Version: 4, SD: sd, Hash: hash, SysACLHash: hash},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parsed, err := Parse(tc.blob)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, parsed)
+			b, err := tc.expected.MarshalBinary()
+			require.NoError(t, err)
+			assert.Equal(t, tc.blob, b)
+		})
+	}
+}
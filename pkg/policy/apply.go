@@ -0,0 +1,71 @@
+//go:build windows || linux
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/rancher/permissions/pkg/acl"
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// errNotSupported is returned for the settings of a rule that cannot be applied on the current platform
+var errNotSupported = errors.New("not supported on this platform")
+
+// ApplyOptions controls how ApplyCustom applies the policy
+type ApplyOptions struct {
+	// Backend lists the paths, and reads and writes their security descriptors. Defaults to acl.System.
+	Backend acl.Backend
+}
+
+// Apply applies the policy to the paths its rules match. Symbolic links are not followed, and skipped as Reconcile
+// does. It stops at the first error.
+func (p *Policy) Apply() error {
+	return p.ApplyCustom(ApplyOptions{})
+}
+
+// ApplyCustom behaves like Apply, but lets the caller customize how the policy is applied through opts
+func (p *Policy) ApplyCustom(opts ApplyOptions) error {
+	b := backend(opts.Backend)
+	paths, rules, err := p.resolve(b)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		info, err := b.Lstat(path)
+		if err != nil {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			continue
+		}
+		if err := applyRule(b, path, rules[path], info.IsDir()); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// applyRule writes the parts of the security descriptor of the path that the rule sets, as computed by
+// ruleDescriptor from its current security descriptor
+func applyRule(b acl.Backend, path string, rule Rule, isDir bool) error {
+	current, err := b.Get(path, descriptor.DefaultInformation)
+	if err != nil {
+		return err
+	}
+	sd, info, err := ruleDescriptor(rule, current, isDir)
+	if err != nil || info == 0 {
+		return err
+	}
+	return b.Set(path, sd, info)
+}
+
+// backend returns b, or acl.System if it is nil
+func backend(b acl.Backend) acl.Backend {
+	if b == nil {
+		return acl.System
+	}
+	return b
+}
@@ -0,0 +1,18 @@
+//go:build linux
+
+package policy
+
+import (
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// ruleDescriptor returns the security descriptor that applyRule writes, and the parts of it that are written. This is
+// the descriptor expectedDescriptor computes, which acl.Set maps to the POSIX access ACL and, for directories, to the
+// default ACL. The inheritance of a rule is ignored, since POSIX ACLs are not inherited once they have been set.
+func ruleDescriptor(rule Rule, current *descriptor.SecurityDescriptor, isDir bool) (*descriptor.SecurityDescriptor, descriptor.Information, error) {
+	expected, err := expectedDescriptor(rule, current, isDir)
+	if err != nil {
+		return nil, 0, err
+	}
+	return expected, rule.information(), nil
+}
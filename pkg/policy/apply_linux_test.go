@@ -0,0 +1,90 @@
+//go:build linux
+
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/acl"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/filemode"
+	"github.com/rancher/permissions/pkg/posixacl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestApply(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "bin")
+	require.NoError(t, os.Mkdir(bin, 0777))
+	tool := filepath.Join(bin, "tool")
+	require.NoError(t, os.WriteFile(tool, nil, 0666))
+
+	mode := Mode(0o750)
+	dataMode := Mode(0o640)
+	p := &Policy{Rules: []Rule{
+		{Path: bin, Recursive: true, Owner: strconv.Itoa(os.Getuid()), Mode: &mode},
+		{Path: filepath.Join(bin, "*"), Mode: &dataMode},
+	}}
+	require.NoError(t, p.Apply())
+
+	info, err := os.Stat(bin)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o750), info.Mode().Perm())
+	info, err = os.Stat(tool)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+
+	// the ACEs of a rule are written as POSIX ACLs, and inheritable ACEs make up the default ACL of directories
+	user := descriptor.UnixUserSID(1001).String()
+	p = &Policy{Rules: []Rule{{Path: bin, Recursive: true, Mode: &mode, ACEs: []ACE{
+		{Trustee: user, Rights: Rights(filemode.Rights(0o5))},
+	}}}}
+	skipWithoutACLSupport(t, dir)
+	require.NoError(t, p.Apply())
+	named := posixacl.Entry{Tag: posixacl.User, ID: 1001, Perm: posixacl.Read | posixacl.Execute}
+	for _, path := range []string{bin, tool} {
+		access, defaults, err := acl.GetPOSIX(path)
+		require.NoError(t, err)
+		assert.Contains(t, access, named, path)
+		assert.Contains(t, access, posixacl.Entry{Tag: posixacl.UserObj, Perm: posixacl.All}, path)
+		if path == bin {
+			assert.Contains(t, defaults, named)
+			assert.Contains(t, defaults, posixacl.Entry{Tag: posixacl.Other, Perm: 0})
+		} else {
+			assert.Nil(t, defaults)
+		}
+	}
+
+	// a mode without inheritable ACEs keeps the default ACL
+	p = &Policy{Rules: []Rule{{Path: bin, Mode: &mode}}}
+	require.NoError(t, p.Apply())
+	access, defaults, err := acl.GetPOSIX(bin)
+	require.NoError(t, err)
+	assert.Equal(t, posixacl.FromMode(0o750), access)
+	assert.Contains(t, defaults, named)
+
+	for _, ace := range []ACE{
+		{Type: descriptor.AccessDenied, Trustee: user, Rights: 1},
+		{Trustee: descriptor.BuiltinAdministrators.String(), Rights: 1},
+	} {
+		p = &Policy{Rules: []Rule{{Path: tool, ACEs: []ACE{ace}}}}
+		assert.ErrorIs(t, p.Apply(), errNotSupported)
+	}
+}
+
+func skipWithoutACLSupport(t *testing.T, dir string) {
+	extended := append(posixacl.FromMode(0o750), posixacl.Entry{Tag: posixacl.User, ID: 1001, Perm: posixacl.Read})
+	err := acl.SetPOSIX(dir, extended.WithMask().Sorted(), nil)
+	if errors.Is(err, unix.ENOTSUP) {
+		t.Skip("file system does not support POSIX ACLs")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,38 @@
+//go:build windows
+
+package policy
+
+import (
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// ruleDescriptor returns the security descriptor that applyRule writes, and the parts of it that are written, as
+// acl.ApplyCustom would write them with the Canonicalize option and the inheritance of the rule. The ACEs derived from
+// the mode and the explicit ACEs of the rule replace the explicit ACEs of the DACL, while a rule without a mode or
+// ACEs leaves them unchanged. With the "convert" inheritance, the ACEs inherited from the parent are kept as explicit
+// ACEs.
+func ruleDescriptor(rule Rule, current *descriptor.SecurityDescriptor, isDir bool) (*descriptor.SecurityDescriptor, descriptor.Information, error) {
+	sd, err := expectedDescriptor(rule, current, isDir)
+	if err != nil {
+		return nil, 0, err
+	}
+	info := rule.information()
+	if rule.Inheritance == "" && !rule.changesPermissions() {
+		return sd, info, nil
+	}
+	info |= descriptor.DACLInformation
+	sd.Control |= descriptor.DACLPresent
+	if !rule.changesPermissions() {
+		sd.DACL = current.DACL.Explicit()
+	}
+	if rule.Inheritance == "convert" {
+		for _, ace := range current.DACL {
+			if ace.Flags&descriptor.Inherited != 0 {
+				ace.Flags &^= descriptor.Inherited
+				sd.DACL = append(sd.DACL, ace)
+			}
+		}
+	}
+	sd.DACL = sd.DACL.Canonicalize()
+	return sd, info, nil
+}
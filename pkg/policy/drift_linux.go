@@ -0,0 +1,111 @@
+//go:build linux
+
+package policy
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/acl"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/filemode"
+	"github.com/rancher/permissions/pkg/posixacl"
+)
+
+// expectedDescriptor returns the owner, group and DACL that acl.Get returns once applyRule has been applied, given
+// the current security descriptor of the path.
+//
+// The ACEs derived from the mode replace the ACEs of the owner, the group and Everyone, which are kept if the rule has
+// no mode, and the ACEs of the rule replace those of named users and groups. If some ACEs of the rule are
+// inheritable, they replace the default ACL of a directory along with the ACEs of the owner, the group and Everyone,
+// as the ACEs of the mode are inherited on Windows. Otherwise, the default ACL is left unchanged, as chmod does.
+//
+// POSIX ACLs can only hold allow ACEs for Unix users and groups, Everyone, Creator Owner and Creator Group, so other
+// ACEs return an error that wraps errNotSupported.
+func expectedDescriptor(rule Rule, current *descriptor.SecurityDescriptor, isDir bool) (*descriptor.SecurityDescriptor, error) {
+	expected := *current
+	var err error
+	if rule.Owner != "" {
+		if expected.Owner, err = acl.LookupUser(rule.Owner); err != nil {
+			return nil, err
+		}
+	}
+	if rule.Group != "" {
+		if expected.Group, err = acl.LookupGroup(rule.Group); err != nil {
+			return nil, err
+		}
+	}
+	if !rule.changesPermissions() {
+		return &expected, nil
+	}
+
+	var base descriptor.ACL
+	if rule.Mode != nil {
+		base = filemode.ToDACL(fs.FileMode(*rule.Mode), expected.Owner, expected.Group)
+	} else {
+		for _, ace := range current.DACL {
+			if !ace.AppliesToObject() {
+				continue
+			}
+			switch ace.SID {
+			case current.Owner:
+				ace.SID = expected.Owner
+			case current.Group:
+				ace.SID = expected.Group
+			case descriptor.Everyone:
+			default:
+				continue
+			}
+			ace.Flags = 0
+			base = append(base, ace)
+		}
+	}
+
+	var aces descriptor.ACL
+	inheritable := false
+	for i, ace := range rule.ACEs {
+		if ace.Type != descriptor.AccessAllowed {
+			return nil, fmt.Errorf("ACE %d: deny ACEs are %w", i, errNotSupported)
+		}
+		trustee, err := acl.LookupAccount(ace.Trustee)
+		if err != nil {
+			return nil, err
+		}
+		flags := ace.inheritanceFlags()
+		inheritable = inheritable || flags&(descriptor.ObjectInherit|descriptor.ContainerInherit) != 0
+		aces = append(aces, descriptor.ACE{
+			Type:  ace.Type,
+			Flags: flags,
+			Mask:  access.FileGenericMapping.Normalize(uint32(ace.Rights)),
+			SID:   trustee,
+		})
+	}
+	if inheritable {
+		for i := range base {
+			base[i].Flags = descriptor.ObjectInherit | descriptor.ContainerInherit
+		}
+		aces = append(base, aces...)
+	} else {
+		aces = append(base, aces...)
+		for _, ace := range current.DACL {
+			if !ace.AppliesToObject() {
+				aces = append(aces, ace)
+			}
+		}
+	}
+
+	// the DACL is returned as acl.Get derives it from the POSIX ACLs that acl.Set writes
+	posixAccess, err := posixacl.FromDACL(aces, expected.Owner, expected.Group)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errNotSupported, err)
+	}
+	var defaults posixacl.ACL
+	if isDir {
+		if defaults, err = posixacl.DefaultFromDACL(aces, expected.Owner, expected.Group); err != nil {
+			return nil, fmt.Errorf("%w: %w", errNotSupported, err)
+		}
+	}
+	expected.DACL = append(posixAccess.DACL(expected.Owner, expected.Group), defaults.InheritableACEs()...)
+	return &expected, nil
+}
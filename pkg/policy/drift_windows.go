@@ -0,0 +1,74 @@
+//go:build windows
+
+package policy
+
+import (
+	"io/fs"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/acl"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/filemode"
+)
+
+// expectedDescriptor returns the owner, group and explicit DACL that applyRule writes, given the current security
+// descriptor of the path. The explicit ACEs are the same for files and directories, so isDir is not used.
+func expectedDescriptor(rule Rule, current *descriptor.SecurityDescriptor, isDir bool) (*descriptor.SecurityDescriptor, error) {
+	expected := &descriptor.SecurityDescriptor{
+		Owner:   current.Owner,
+		Group:   current.Group,
+		Control: current.Control & (descriptor.DACLPresent | descriptor.DACLProtected),
+	}
+	for _, s := range []struct {
+		name string
+		sid  *descriptor.SID
+	}{
+		{rule.Owner, &expected.Owner},
+		{rule.Group, &expected.Group},
+	} {
+		if s.name == "" {
+			continue
+		}
+		sid, err := acl.LookupAccount(s.name)
+		if err != nil {
+			return nil, err
+		}
+		*s.sid = sid
+	}
+
+	switch {
+	case rule.Inheritance == "protected" || rule.Inheritance == "convert":
+		expected.Control |= descriptor.DACLProtected
+	case rule.Inheritance == "unprotected":
+		expected.Control &^= descriptor.DACLProtected
+	case rule.changesPermissions():
+		// like acl.ApplyCustom, the DACL that is replaced is protected by default
+		expected.Control |= descriptor.DACLProtected
+	}
+
+	if !rule.changesPermissions() {
+		return expected, nil
+	}
+	inherit := descriptor.ObjectInherit | descriptor.ContainerInherit
+	expected.DACL = descriptor.ACL{}
+	if rule.Mode != nil {
+		for _, ace := range filemode.ToDACL(fs.FileMode(*rule.Mode), expected.Owner, expected.Group) {
+			// like the rules created by filemode.Convert, the ACEs are inherited by files and directories
+			ace.Flags = inherit
+			expected.DACL = append(expected.DACL, ace)
+		}
+	}
+	for _, ace := range rule.ACEs {
+		trustee, err := acl.LookupAccount(ace.Trustee)
+		if err != nil {
+			return nil, err
+		}
+		expected.DACL = append(expected.DACL, descriptor.ACE{
+			Type:  ace.Type,
+			Flags: ace.inheritanceFlags(),
+			Mask:  access.FileGenericMapping.Normalize(uint32(ace.Rights)),
+			SID:   trustee,
+		})
+	}
+	return expected, nil
+}
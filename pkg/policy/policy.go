@@ -0,0 +1,398 @@
+// Package policy describes the desired permissions of a file layout in a YAML or JSON document, and applies them with
+// pkg/acl, as Windows ACLs or as POSIX ACLs on Linux.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/icacls"
+	"gopkg.in/yaml.v3"
+)
+
+// Policy is an ordered list of rules. When several rules match the same path, they are merged in order: the owner,
+// group and inheritance of a rule override those of the previous rules, and so do its mode and ACEs, which are
+// replaced together.
+type Policy struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// Rule describes the desired permissions of the paths that match Path
+type Rule struct {
+	// Path is a path or a glob pattern, in the syntax of filepath.Match
+	Path string `json:"path" yaml:"path"`
+	// Recursive applies the rule to every file / directory below the matching paths as well
+	Recursive bool `json:"recursive,omitempty" yaml:"recursive,omitempty"`
+
+	// Owner is the owner of the paths. On Windows, it is a SID or an account name (e.g. BUILTIN\Administrators). On
+	// Linux, it is a user ID or a user name. If empty, the owner is left unchanged.
+	Owner string `json:"owner,omitempty" yaml:"owner,omitempty"`
+	// Group is the group of the paths. On Windows, it is a SID or an account name. On Linux, it is a group ID or a
+	// group name. If empty, the group is left unchanged.
+	Group string `json:"group,omitempty" yaml:"group,omitempty"`
+
+	// Mode holds Unix permission bits. On Windows, they are converted to ACEs for the owner, the group and Everyone
+	// as acl.Chmod does. On Linux, they replace the permission bits, and the default ACL of directories is left
+	// unchanged unless the rule has inheritable ACEs.
+	Mode *Mode `json:"mode,omitempty" yaml:"mode,omitempty"`
+	// ACEs are explicit access rules, which are added to the ACEs derived from Mode. On Linux, they are written as
+	// POSIX ACL entries, so they must be allow ACEs for Unix users and groups, Everyone, Creator Owner or Creator
+	// Group.
+	ACEs []ACE `json:"aces,omitempty" yaml:"aces,omitempty"`
+	// Inheritance controls whether the DACL inherits ACEs from the parent: "protected", "unprotected" or "convert",
+	// which have the same meaning as the values of acl.Inheritance. It has no effect on Linux.
+	Inheritance string `json:"inheritance,omitempty" yaml:"inheritance,omitempty"`
+}
+
+// changesPermissions returns true if the rule replaces the access rules of the paths
+func (r Rule) changesPermissions() bool {
+	return r.Mode != nil || len(r.ACEs) > 0
+}
+
+// information returns the parts of the security descriptor of the paths that the rule writes
+func (r Rule) information() descriptor.Information {
+	var info descriptor.Information
+	if r.Owner != "" {
+		info |= descriptor.OwnerInformation
+	}
+	if r.Group != "" {
+		info |= descriptor.GroupInformation
+	}
+	if r.changesPermissions() {
+		info |= descriptor.DACLInformation
+	}
+	return info
+}
+
+// ACE is an explicit access rule
+type ACE struct {
+	// Type is "allow" (the default) or "deny"
+	Type descriptor.ACEType `json:"type" yaml:"type"`
+	// Trustee is a SID or an account name. On Linux, it is an S-1-22-1-<uid> or S-1-22-2-<gid> SID, a user or group
+	// name, or a well-known name such as Everyone.
+	Trustee string `json:"trustee" yaml:"trustee"`
+	// Rights are the file rights granted or denied to the trustee
+	Rights Rights `json:"rights" yaml:"rights"`
+	// Flags holds the inheritance flags of the ACE, as SDDL abbreviations (e.g. "OICI"). If empty, the ACE is
+	// inherited by files and directories.
+	Flags *descriptor.ACEFlags `json:"flags,omitempty" yaml:"flags,omitempty"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler. Besides a mapping, an ACE can be written as an icacls entry (e.g.
+// `BUILTIN\Users:(OI)(CI)RX`), where the trustee is an account name or a SID prefixed with "*". As in icacls, an entry
+// without inheritance flags is not inherited.
+func (a *ACE) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.ScalarNode {
+		type ace ACE
+		return value.Decode((*ace)(a))
+	}
+	entry, err := icacls.ParseEntry(value.Value)
+	if err != nil {
+		return err
+	}
+	flags := entry.Flags
+	*a = ACE{Type: entry.Type, Trustee: entry.Trustee, Rights: Rights(entry.Rights), Flags: &flags}
+	if sid, ok := entry.SID(); ok {
+		a.Trustee = sid.String()
+	}
+	return nil
+}
+
+// inheritanceFlags returns the inheritance flags of the ACE, which default to OICI
+func (a ACE) inheritanceFlags() descriptor.ACEFlags {
+	if a.Flags == nil {
+		return descriptor.ObjectInherit | descriptor.ContainerInherit
+	}
+	return *a.Flags
+}
+
+// Mode holds Unix permission bits. It is written as an octal number (e.g. "0755").
+type Mode fs.FileMode
+
+// MarshalText implements encoding.TextMarshaler
+func (m Mode) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%04o", uint32(m))), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (m *Mode) UnmarshalText(text []byte) error {
+	v, err := strconv.ParseUint(strings.TrimPrefix(string(text), "0o"), 8, 32)
+	if err != nil || v&^uint64(fs.ModePerm) != 0 {
+		return fmt.Errorf("invalid mode %q: expected octal permission bits such as 0755", string(text))
+	}
+	*m = Mode(v)
+	return nil
+}
+
+// Rights holds file rights. It is written as a comma-separated list of names (e.g. "ReadAndExecute, Write"), as
+// formatted by access.FileRightsString, or as a number.
+type Rights uint32
+
+// MarshalText implements encoding.TextMarshaler
+func (r Rights) MarshalText() ([]byte, error) {
+	return []byte(access.FileRightsString(uint32(r))), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (r *Rights) UnmarshalText(text []byte) error {
+	rights, err := access.ParseFileRights(string(text))
+	if err != nil {
+		return err
+	}
+	*r = Rights(rights)
+	return nil
+}
+
+// Load reads and validates the policy stored in a YAML or JSON file
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Parse parses and validates a policy. Since JSON is a subset of YAML, both formats are accepted.
+func Parse(data []byte) (*Policy, error) {
+	p := &Policy{}
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Validate checks that every rule has a valid path pattern, something to apply and valid ACEs
+func (p *Policy) Validate() error {
+	var errs []error
+	for i, rule := range p.Rules {
+		if err := rule.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("rule %d (%s): %w", i, rule.Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r Rule) validate() error {
+	if r.Path == "" {
+		return fmt.Errorf("path cannot be empty")
+	}
+	if _, err := filepath.Match(r.Path, ""); err != nil {
+		return fmt.Errorf("invalid path pattern: %w", err)
+	}
+	switch r.Inheritance {
+	case "", "protected", "unprotected", "convert":
+	default:
+		return fmt.Errorf("invalid inheritance %q: expected protected, unprotected or convert", r.Inheritance)
+	}
+	if r.Owner == "" && r.Group == "" && !r.changesPermissions() && r.Inheritance == "" {
+		return fmt.Errorf("rule does not change anything")
+	}
+	for i, ace := range r.ACEs {
+		if ace.Type != descriptor.AccessAllowed && ace.Type != descriptor.AccessDenied {
+			return fmt.Errorf("ACE %d: type must be allow or deny", i)
+		}
+		if ace.Trustee == "" {
+			return fmt.Errorf("ACE %d: trustee cannot be empty", i)
+		}
+		if ace.Rights == 0 {
+			return fmt.Errorf("ACE %d: rights cannot be empty", i)
+		}
+		if ace.inheritanceFlags()&^descriptor.InheritanceFlags != 0 {
+			return fmt.Errorf("ACE %d: flags can only hold inheritance flags (OI, CI, NP, IO)", i)
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the rule applies to the path, either because it matches the pattern or because it is below
+// a matching path and the rule is recursive
+func (r Rule) Matches(path string) bool {
+	path = filepath.Clean(path)
+	for {
+		if ok, _ := filepath.Match(r.Path, path); ok {
+			return true
+		}
+		if !r.Recursive {
+			return false
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return false
+		}
+		path = parent
+	}
+}
+
+// Paths returns the existing paths that the rule applies to, in lexical order. When the rule is recursive, the
+// contents of matching directories are included, without following symbolic links.
+func (r Rule) Paths() ([]string, error) {
+	return r.paths(osFS{})
+}
+
+// fileSystem lists the paths that rules are matched against. acl.Backend implements it.
+type fileSystem interface {
+	Lstat(path string) (fs.FileInfo, error)
+	ReadDir(path string) ([]fs.DirEntry, error)
+}
+
+// osFS is the fileSystem of the host
+type osFS struct{}
+
+func (osFS) Lstat(path string) (fs.FileInfo, error)    { return os.Lstat(path) }
+func (osFS) ReadDir(path string) ([]fs.DirEntry, error) { return os.ReadDir(path) }
+
+// paths returns the paths of fsys that the rule applies to, as Paths does
+func (r Rule) paths(fsys fileSystem) ([]string, error) {
+	matches, err := glob(fsys, r.Path)
+	if err != nil {
+		return nil, err
+	}
+	if !r.Recursive {
+		return matches, nil
+	}
+	var paths []string
+	for _, match := range matches {
+		if paths, err = walk(fsys, match, paths); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// glob returns the paths of fsys that match the pattern, as filepath.Glob does on the file system
+func glob(fsys fileSystem, pattern string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	if !hasMeta(pattern) {
+		if _, err := fsys.Lstat(pattern); err != nil {
+			return nil, nil
+		}
+		return []string{pattern}, nil
+	}
+	dir, file := filepath.Split(pattern)
+	dir = cleanGlobPath(dir)
+	dirs := []string{dir}
+	if hasMeta(dir) {
+		var err error
+		if dirs, err = glob(fsys, dir); err != nil {
+			return nil, err
+		}
+	}
+	var matches []string
+	for _, dir := range dirs {
+		// as with filepath.Glob, directories that cannot be read have no matches
+		entries, err := fsys.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if ok, _ := filepath.Match(file, entry.Name()); ok {
+				matches = append(matches, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	return matches, nil
+}
+
+// hasMeta reports whether the path contains any of the special characters of filepath.Match
+func hasMeta(path string) bool {
+	magic := `*?[`
+	if filepath.Separator != '\\' {
+		magic += "\\"
+	}
+	return strings.ContainsAny(path, magic)
+}
+
+// cleanGlobPath prepares the directory of a pattern, as returned by filepath.Split, to be listed
+func cleanGlobPath(path string) string {
+	switch {
+	case path == "":
+		return "."
+	case path == filepath.VolumeName(path)+string(filepath.Separator):
+		return path
+	default:
+		return path[:len(path)-1]
+	}
+}
+
+// walk appends the path and, if it is a directory, the paths below it in lexical order, without following symbolic
+// links, as filepath.WalkDir does
+func walk(fsys fileSystem, path string, paths []string) ([]string, error) {
+	paths = append(paths, path)
+	info, err := fsys.Lstat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return paths, nil
+	}
+	entries, err := fsys.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.Type()&fs.ModeSymlink != 0 {
+			continue
+		}
+		if paths, err = walk(fsys, filepath.Join(path, entry.Name()), paths); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// resolve returns the paths of fsys that the rules apply to, in the order they are first matched, along with the
+// merged rule for each of them
+func (p *Policy) resolve(fsys fileSystem) ([]string, map[string]Rule, error) {
+	var paths []string
+	rules := make(map[string]Rule)
+	for i, rule := range p.Rules {
+		matches, err := rule.paths(fsys)
+		if err != nil {
+			return nil, nil, fmt.Errorf("rule %d (%s): %w", i, rule.Path, err)
+		}
+		for _, path := range matches {
+			merged, ok := rules[path]
+			if !ok {
+				paths = append(paths, path)
+			}
+			rules[path] = merged.merge(rule)
+		}
+	}
+	return paths, rules, nil
+}
+
+// merge returns the rule with the settings of other applied on top of it
+func (r Rule) merge(other Rule) Rule {
+	r.Path = other.Path
+	r.Recursive = other.Recursive
+	if other.Owner != "" {
+		r.Owner = other.Owner
+	}
+	if other.Group != "" {
+		r.Group = other.Group
+	}
+	if other.changesPermissions() {
+		r.Mode = other.Mode
+		r.ACEs = other.ACEs
+	}
+	if other.Inheritance != "" {
+		r.Inheritance = other.Inheritance
+	}
+	return r
+}
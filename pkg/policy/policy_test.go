@@ -0,0 +1,140 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	yamlPolicy := `
+rules:
+  - path: /etc/rancher/node
+    recursive: true
+    owner: S-1-5-32-544
+    mode: 0700
+    inheritance: protected
+    aces:
+      - type: deny
+        trustee: S-1-5-32-545
+        rights: Write, Delete
+        flags: CI
+      - trustee: S-1-5-18
+        rights: FullControl
+`
+	jsonPolicy := `{"rules": [{
+		"path": "/etc/rancher/node",
+		"recursive": true,
+		"owner": "S-1-5-32-544",
+		"mode": "0700",
+		"inheritance": "protected",
+		"aces": [
+			{"type": "Deny", "trustee": "S-1-5-32-545", "rights": "Write, Delete", "flags": "CI"},
+			{"trustee": "S-1-5-18", "rights": "2032127"}
+		]
+	}]}`
+
+	flags := descriptor.ContainerInherit
+	mode := Mode(0o700)
+	expected := &Policy{Rules: []Rule{{
+		Path:        "/etc/rancher/node",
+		Recursive:   true,
+		Owner:       "S-1-5-32-544",
+		Mode:        &mode,
+		Inheritance: "protected",
+		ACEs: []ACE{
+			{Type: descriptor.AccessDenied, Trustee: "S-1-5-32-545", Rights: Rights(access.Write | access.Delete), Flags: &flags},
+			{Type: descriptor.AccessAllowed, Trustee: "S-1-5-18", Rights: Rights(access.FullControl)},
+		},
+	}}}
+
+	for name, data := range map[string]string{"YAML": yamlPolicy, "JSON": jsonPolicy} {
+		t.Run(name, func(t *testing.T) {
+			p, err := Parse([]byte(data))
+			require.NoError(t, err)
+			assert.Equal(t, expected, p)
+		})
+	}
+}
+
+func TestParseICACLS(t *testing.T) {
+	p, err := Parse([]byte(`
+rules:
+  - path: /data
+    aces:
+      - BUILTIN\Users:(OI)(CI)RX
+      - "*S-1-1-0:(DENY)(WD,AD)"
+`))
+	require.NoError(t, err)
+	inherit := descriptor.ObjectInherit | descriptor.ContainerInherit
+	none := descriptor.ACEFlags(0)
+	assert.Equal(t, []ACE{
+		{Type: descriptor.AccessAllowed, Trustee: `BUILTIN\Users`, Rights: Rights(access.ReadAndExecute | access.Synchronize), Flags: &inherit},
+		{Type: descriptor.AccessDenied, Trustee: "S-1-1-0", Rights: Rights(access.WriteData | access.AppendData), Flags: &none},
+	}, p.Rules[0].ACEs)
+}
+
+func TestParseInvalid(t *testing.T) {
+	testCases := map[string]string{
+		"Empty path":        `rules: [{mode: "0755"}]`,
+		"Invalid pattern":   `rules: [{path: "/a/[", mode: "0755"}]`,
+		"Nothing to change": `rules: [{path: /a}]`,
+		"Invalid mode":      `rules: [{path: /a, mode: "0999"}]`,
+		"Mode with type":    `rules: [{path: /a, mode: "01755"}]`,
+		"Unknown right":     `rules: [{path: /a, aces: [{trustee: S-1-1-0, rights: Everything}]}]`,
+		"Audit ACE":         `rules: [{path: /a, aces: [{type: audit, trustee: S-1-1-0, rights: Read}]}]`,
+		"Missing trustee":   `rules: [{path: /a, aces: [{rights: Read}]}]`,
+		"Audit flags":       `rules: [{path: /a, aces: [{trustee: S-1-1-0, rights: Read, flags: OIFA}]}]`,
+		"Inheritance":       `rules: [{path: /a, inheritance: disabled}]`,
+		"Invalid icacls":    `rules: [{path: /a, aces: ["Users:(OI)RWX"]}]`,
+		"Inherited icacls":  `rules: [{path: /a, aces: ["Users:(I)F"]}]`,
+	}
+	for name, data := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	rule := Rule{Path: filepath.Join("opt", "*", "bin")}
+	assert.True(t, rule.Matches(filepath.Join("opt", "rke2", "bin")))
+	assert.False(t, rule.Matches(filepath.Join("opt", "rke2", "bin", "kubelet")))
+
+	rule.Recursive = true
+	assert.True(t, rule.Matches(filepath.Join("opt", "rke2", "bin", "kubelet")))
+	assert.False(t, rule.Matches(filepath.Join("opt", "rke2", "data")))
+}
+
+func TestPaths(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{"a/bin/tool", "b/bin/tool", "b/data"} {
+		p = filepath.Join(dir, filepath.FromSlash(p))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, nil, 0644))
+	}
+
+	rule := Rule{Path: filepath.Join(dir, "*", "bin")}
+	paths, err := rule.Paths()
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a", "bin"), filepath.Join(dir, "b", "bin")}, paths)
+
+	rule.Recursive = true
+	paths, err = rule.Paths()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "a", "bin"),
+		filepath.Join(dir, "a", "bin", "tool"),
+		filepath.Join(dir, "b", "bin"),
+		filepath.Join(dir, "b", "bin", "tool"),
+	}, paths)
+	for _, p := range paths {
+		assert.True(t, rule.Matches(p))
+	}
+}
@@ -0,0 +1,173 @@
+//go:build windows || linux
+
+package policy
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+
+	"github.com/rancher/permissions/pkg/acl"
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// Status is the outcome of reconciling a path
+type Status string
+
+const (
+	// Compliant means that the path already matches the policy
+	Compliant Status = "compliant"
+	// Drifted means that the path does not match the policy, and it was not fixed because fixing was not requested
+	Drifted Status = "drifted"
+	// Fixed means that the path did not match the policy, and that the policy was applied to it
+	Fixed Status = "fixed"
+	// Failed means that the path could not be checked or fixed
+	Failed Status = "failed"
+	// Skipped means that the path was not checked, because it is a symbolic link or because the rule cannot be
+	// applied on this platform
+	Skipped Status = "skipped"
+)
+
+// ReconcileOptions controls what Reconcile does with the paths that drifted
+type ReconcileOptions struct {
+	// Fix applies the policy to the paths that drifted. Otherwise drift is only reported.
+	Fix bool
+
+	// Backend lists the paths, and reads and writes their security descriptors. Defaults to acl.System.
+	Backend acl.Backend
+}
+
+// Result is the outcome of reconciling a path
+type Result struct {
+	Path   string `json:"path"`
+	Status Status `json:"status"`
+	// Drift holds the differences between the path and the policy, for paths that drifted or were fixed
+	Drift *acl.SecurityDescriptorDiff `json:"drift,omitempty"`
+	// Message explains why the path failed or was skipped
+	Message string `json:"message,omitempty"`
+}
+
+// Reconcile compares the paths matched by the policy with the rules that apply to them, and reports or fixes drift.
+//
+// Drift is computed with acl.Diff between the security descriptor returned by the backend and the one the merged rule
+// would produce. Only the parts that the rule sets are compared: the owner and group if they are set, the explicit
+// ACEs if the rule has a mode or ACEs, and the protection of the DACL if it has an inheritance. ACEs inherited from the
+// parent are never compared. Inheritance flags are ignored for files, which have no children.
+//
+// Reconcile returns the result of every path in the order they were matched. It stops and returns the results so far
+// with an error if the context is done, or if the paths of a rule cannot be listed.
+func Reconcile(ctx context.Context, p *Policy, opts ReconcileOptions) ([]Result, error) {
+	b := backend(opts.Backend)
+	paths, rules, err := p.resolve(b)
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		results = append(results, reconcilePath(b, path, rules[path], opts))
+	}
+	return results, nil
+}
+
+func reconcilePath(b acl.Backend, path string, rule Rule, opts ReconcileOptions) Result {
+	result := Result{Path: path}
+	info, err := b.Lstat(path)
+	if err != nil {
+		return result.fail(err)
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		result.Status = Skipped
+		result.Message = "symbolic links are not followed"
+		return result
+	}
+
+	drift, err := detectDrift(b, path, rule, info.IsDir())
+	if err != nil {
+		return result.fail(err)
+	}
+	if drift.IsEmpty() {
+		result.Status = Compliant
+		return result
+	}
+	result.Drift = drift
+	if !opts.Fix {
+		result.Status = Drifted
+		return result
+	}
+
+	if err := applyRule(b, path, rule, info.IsDir()); err != nil {
+		return result.fail(err)
+	}
+	remaining, err := detectDrift(b, path, rule, info.IsDir())
+	if err != nil {
+		return result.fail(err)
+	}
+	if !remaining.IsEmpty() {
+		result.Status = Failed
+		result.Message = "the policy was applied, but the path still differs from it:\n" + remaining.String()
+		return result
+	}
+	result.Status = Fixed
+	return result
+}
+
+func (r Result) fail(err error) Result {
+	r.Status = Failed
+	if errors.Is(err, errNotSupported) {
+		r.Status = Skipped
+	}
+	r.Message = err.Error()
+	return r
+}
+
+// detectDrift returns the differences between the current security descriptor of the path and the one expected by
+// the rule
+func detectDrift(b acl.Backend, path string, rule Rule, isDir bool) (*acl.SecurityDescriptorDiff, error) {
+	current, err := b.Get(path, descriptor.DefaultInformation)
+	if err != nil {
+		return nil, err
+	}
+	expected, err := expectedDescriptor(rule, current, isDir)
+	if err != nil {
+		return nil, err
+	}
+
+	actual := *current
+	actual.DACL = current.DACL.Explicit()
+	if !isDir {
+		actual.DACL = objectACEs(actual.DACL)
+		expected.DACL = objectACEs(expected.DACL)
+	}
+	drift := acl.Diff(&actual, expected)
+
+	if !rule.changesPermissions() {
+		drift.DACL = nil
+	} else if rule.Inheritance == "convert" {
+		// the ACEs that were inherited when the rule was applied have become explicit, so only missing rights are
+		// drift
+		var changes []acl.ACEChange
+		for _, change := range drift.DACL {
+			if change.Added != 0 {
+				change.Removed = 0
+				changes = append(changes, change)
+			}
+		}
+		drift.DACL = changes
+	}
+	return drift, nil
+}
+
+// objectACEs returns the ACEs that apply to the object itself, without their inheritance flags
+func objectACEs(dacl descriptor.ACL) descriptor.ACL {
+	var aces descriptor.ACL
+	for _, ace := range dacl {
+		if ace.AppliesToObject() {
+			ace.Flags &^= descriptor.InheritanceFlags
+			aces = append(aces, ace)
+		}
+	}
+	return aces
+}
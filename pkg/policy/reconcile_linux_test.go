@@ -0,0 +1,85 @@
+//go:build linux
+
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/filemode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcile(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "bin")
+	require.NoError(t, os.Mkdir(bin, 0750))
+	tool := filepath.Join(bin, "tool")
+	require.NoError(t, os.WriteFile(tool, nil, 0600))
+	require.NoError(t, os.Chmod(tool, 0666))
+	link := filepath.Join(bin, "link")
+	require.NoError(t, os.Symlink(tool, link))
+
+	mode := Mode(0o750)
+	// recursive rules do not follow symbolic links, but patterns can match them
+	p := &Policy{Rules: []Rule{
+		{Path: bin, Recursive: true, Mode: &mode},
+		{Path: filepath.Join(bin, "l*"), Mode: &mode},
+	}}
+
+	statuses := func(results []Result) map[string]Status {
+		m := make(map[string]Status)
+		for _, result := range results {
+			m[result.Path] = result.Status
+		}
+		return m
+	}
+
+	results, err := Reconcile(context.Background(), p, ReconcileOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Status{bin: Compliant, tool: Drifted, link: Skipped}, statuses(results))
+	for _, result := range results {
+		if result.Path == tool {
+			require.NotNil(t, result.Drift)
+			assert.NotEmpty(t, result.Drift.DACL)
+		}
+	}
+	info, err := os.Stat(tool)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o666), info.Mode().Perm(), "drift must not be fixed without Fix")
+
+	results, err = Reconcile(context.Background(), p, ReconcileOptions{Fix: true})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Status{bin: Compliant, tool: Fixed, link: Skipped}, statuses(results))
+	info, err = os.Stat(tool)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o750), info.Mode().Perm())
+
+	results, err = Reconcile(context.Background(), p, ReconcileOptions{Fix: true})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Status{bin: Compliant, tool: Compliant, link: Skipped}, statuses(results))
+
+	// ACEs for Unix users and groups are written as POSIX ACLs, but deny ACEs cannot be represented
+	p.Rules = append(p.Rules, Rule{Path: tool, ACEs: []ACE{{Trustee: "S-1-22-1-1001", Rights: Rights(filemode.Rights(0o4))}}})
+	skipWithoutACLSupport(t, dir)
+	results, err = Reconcile(context.Background(), p, ReconcileOptions{Fix: true})
+	require.NoError(t, err)
+	assert.Equal(t, Fixed, statuses(results)[tool])
+	results, err = Reconcile(context.Background(), p, ReconcileOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, Compliant, statuses(results)[tool])
+
+	p.Rules[len(p.Rules)-1].ACEs[0].Type = descriptor.AccessDenied
+	results, err = Reconcile(context.Background(), p, ReconcileOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, Skipped, statuses(results)[tool])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Reconcile(ctx, p, ReconcileOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,74 @@
+//go:build windows || linux
+
+package policy
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/acl/acltest"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/filemode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileBackend(t *testing.T) {
+	dir := filepath.FromSlash("/data")
+	f := filepath.Join(dir, "file")
+
+	owner, group := descriptor.UnixUserSID(1000), descriptor.UnixGroupSID(1000)
+	b := acltest.New()
+	b.Owner, b.Group = owner, group
+	b.Add(dir, true, &descriptor.SecurityDescriptor{
+		Owner:   owner,
+		Group:   group,
+		Control: descriptor.DACLPresent | descriptor.DACLProtected,
+		DACL:    filemode.ToDACL(0o755, owner, group),
+	})
+	require.NoError(t, b.Create(f))
+	require.NoError(t, b.Mkdir(filepath.Join(dir, "dir")))
+
+	paths, err := Rule{Path: dir, Recursive: true}.paths(b)
+	require.NoError(t, err)
+	assert.Equal(t, []string{dir, filepath.Join(dir, "dir"), f}, paths, "the paths should be listed by the backend")
+
+	mode := Mode(0o640)
+	user := descriptor.UnixUserSID(1001)
+	var noInheritance descriptor.ACEFlags
+	p := &Policy{Rules: []Rule{{Path: filepath.Join(dir, "f*"), Mode: &mode, ACEs: []ACE{
+		{Trustee: user.String(), Rights: Rights(filemode.Rights(0o4)), Flags: &noInheritance},
+	}}}}
+
+	results, err := Reconcile(context.Background(), p, ReconcileOptions{Backend: b})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, Drifted, results[0].Status, results[0].Message)
+
+	results, err = Reconcile(context.Background(), p, ReconcileOptions{Fix: true, Backend: b})
+	require.NoError(t, err)
+	assert.Equal(t, Fixed, results[0].Status, results[0].Message)
+	sd, err := b.Get(f, descriptor.DACLInformation)
+	require.NoError(t, err)
+	assert.Contains(t, sd.DACL, descriptor.ACE{Type: descriptor.AccessAllowed, Mask: filemode.Rights(0o4), SID: user})
+
+	results, err = Reconcile(context.Background(), p, ReconcileOptions{Backend: b})
+	require.NoError(t, err)
+	assert.Equal(t, Compliant, results[0].Status, results[0].Message)
+
+	// errors of the backend are reported per path
+	mode = Mode(0o600)
+	b.Fail(acltest.OpSet, f, acltest.ErrAccessDenied)
+	results, err = Reconcile(context.Background(), p, ReconcileOptions{Fix: true, Backend: b})
+	require.NoError(t, err)
+	assert.Equal(t, Failed, results[0].Status)
+	assert.ErrorIs(t, p.ApplyCustom(ApplyOptions{Backend: b}), fs.ErrPermission)
+
+	b.Fail(acltest.OpSet, f, nil)
+	require.NoError(t, p.ApplyCustom(ApplyOptions{Backend: b}))
+	results, err = Reconcile(context.Background(), p, ReconcileOptions{Backend: b})
+	require.NoError(t, err)
+	assert.Equal(t, Compliant, results[0].Status, results[0].Message)
+}
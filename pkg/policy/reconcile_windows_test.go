@@ -0,0 +1,61 @@
+//go:build windows
+
+package policy
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/acl"
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+func TestReconcile(t *testing.T) {
+	dir, err := os.MkdirTemp("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := &Policy{Rules: []Rule{{
+		Path:  dir,
+		Owner: string(descriptor.BuiltinAdministrators),
+		ACEs: []ACE{
+			{Trustee: string(descriptor.LocalSystem), Rights: Rights(0x001F01FF)},
+			{Trustee: string(descriptor.BuiltinAdministrators), Rights: Rights(0x001F01FF)},
+		},
+		Inheritance: "protected",
+	}}}
+
+	results, err := Reconcile(context.Background(), p, ReconcileOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Status != Drifted {
+		t.Fatalf("expected the directory to drift, found %+v", results)
+	}
+
+	results, err = Reconcile(context.Background(), p, ReconcileOptions{Fix: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Status != Fixed {
+		t.Fatalf("expected the directory to be fixed, found %+v", results[0])
+	}
+	sd, err := acl.Get(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sd.IsDACLProtected() {
+		t.Error("expected protected DACL")
+	}
+
+	results, err = Reconcile(context.Background(), p, ReconcileOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Status != Compliant {
+		t.Errorf("expected the directory to be compliant, found %+v", results[0])
+	}
+}
@@ -0,0 +1,129 @@
+// Package posixacl models POSIX.1e access control lists, as used by Linux file systems.
+package posixacl
+
+import (
+	"io/fs"
+)
+
+// Tag identifies the kind of an ACL entry. These have the same values as the Linux ACL_* tags.
+type Tag uint16
+
+// ACL entry tags
+const (
+	// UserObj holds the permissions of the owner of the file
+	UserObj Tag = 0x01
+	// User holds the permissions of the user identified by the entry ID
+	User Tag = 0x02
+	// GroupObj holds the permissions of the owning group of the file
+	GroupObj Tag = 0x04
+	// Group holds the permissions of the group identified by the entry ID
+	Group Tag = 0x08
+	// Mask limits the permissions granted by User, GroupObj and Group entries
+	Mask Tag = 0x10
+	// Other holds the permissions of everyone else
+	Other Tag = 0x20
+)
+
+func (t Tag) String() string {
+	switch t {
+	case UserObj, User:
+		return "user"
+	case GroupObj, Group:
+		return "group"
+	case Mask:
+		return "mask"
+	case Other:
+		return "other"
+	default:
+		return "unknown"
+	}
+}
+
+// Perm holds the permissions of an ACL entry
+type Perm uint16
+
+// Permissions
+const (
+	Execute Perm = 0x01
+	Write   Perm = 0x02
+	Read    Perm = 0x04
+
+	// All is the set of all permissions
+	All = Read | Write | Execute
+)
+
+// String returns the permissions in the format used by ls and getfacl (e.g. "r-x")
+func (p Perm) String() string {
+	b := []byte("---")
+	if p&Read != 0 {
+		b[0] = 'r'
+	}
+	if p&Write != 0 {
+		b[1] = 'w'
+	}
+	if p&Execute != 0 {
+		b[2] = 'x'
+	}
+	return string(b)
+}
+
+// Entry is an entry of an ACL. ID is the user ID of User entries and the group ID of Group entries, and is ignored
+// for all other tags.
+type Entry struct {
+	Tag  Tag
+	ID   uint32
+	Perm Perm
+}
+
+// ACL is a POSIX access control list. An ACL that only holds the UserObj, GroupObj and Other entries is equivalent to
+// the permission bits of the file mode.
+type ACL []Entry
+
+// FromMode returns the minimal ACL equivalent to the permission bits of the mode
+func FromMode(mode fs.FileMode) ACL {
+	return ACL{
+		{Tag: UserObj, Perm: Perm(mode>>6) & All},
+		{Tag: GroupObj, Perm: Perm(mode>>3) & All},
+		{Tag: Other, Perm: Perm(mode) & All},
+	}
+}
+
+// Mode returns the permission bits of the file mode that correspond to the ACL. When the ACL has a Mask entry, the
+// group bits hold the mask rather than the permissions of the owning group, as they do on Linux.
+func (a ACL) Mode() fs.FileMode {
+	var mode fs.FileMode
+	if entry, ok := a.Entry(UserObj); ok {
+		mode |= fs.FileMode(entry.Perm) << 6
+	}
+	group, ok := a.Entry(Mask)
+	if !ok {
+		group, _ = a.Entry(GroupObj)
+	}
+	mode |= fs.FileMode(group.Perm) << 3
+	if entry, ok := a.Entry(Other); ok {
+		mode |= fs.FileMode(entry.Perm)
+	}
+	return mode
+}
+
+// Entry returns the first entry with the tag, or false if there is none. It is meant to look up the UserObj,
+// GroupObj, Mask and Other entries, which appear at most once in a valid ACL.
+func (a ACL) Entry(tag Tag) (Entry, bool) {
+	for _, entry := range a {
+		if entry.Tag == tag {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// IsMinimal returns true if the ACL has no User, Group or Mask entries, so that it is fully described by the file mode
+func (a ACL) IsMinimal() bool {
+	for _, entry := range a {
+		switch entry.Tag {
+		case User, Group, Mask:
+			return false
+		}
+	}
+	return true
+}
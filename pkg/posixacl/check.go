@@ -0,0 +1,93 @@
+package posixacl
+
+// Credentials describes the process that requests access to a file
+type Credentials struct {
+	// UID is the effective user ID
+	UID uint32
+	// GIDs holds the effective group ID and the supplementary group IDs
+	GIDs []uint32
+	// DACOverride is true if the process holds the CAP_DAC_OVERRIDE capability, as root usually does. It grants read
+	// and write access to any file, and execute access to directories and to files where any execute bit is set in
+	// the file mode.
+	DACOverride bool
+}
+
+func (c Credentials) inGroup(gid uint32) bool {
+	for _, g := range c.GIDs {
+		if g == gid {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns true if the ACL of a file (or directory, if isDir is set) owned by the user owner and the group group
+// grants all the wanted permissions to the credentials, following the access check algorithm of acl(5):
+//
+//   - The owner gets the permissions of the UserObj entry.
+//   - A user with a User entry gets the permissions of the entry, limited by the Mask entry.
+//   - A user that belongs to the owning group or to groups with a Group entry gets access if one of these entries,
+//     limited by the Mask entry, grants all the wanted permissions. Permissions from several entries are not combined.
+//   - Everyone else gets the permissions of the Other entry.
+//
+// The first of these classes that matches the credentials is the only one that is considered.
+func (a ACL) Check(owner, group uint32, isDir bool, cred Credentials, want Perm) bool {
+	for _, perm := range a.candidates(owner, group, isDir, cred) {
+		if perm&want == want {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions returns every permission that the ACL of a file (or directory, if isDir is set) owned by the user owner
+// and the group group grants to the credentials, following the same rules as Check.
+//
+// When the credentials match several group entries, the result is the union of their permissions, even though
+// Check only grants a request for several permissions if they all come from the same entry.
+func (a ACL) Permissions(owner, group uint32, isDir bool, cred Credentials) Perm {
+	var perms Perm
+	for _, perm := range a.candidates(owner, group, isDir, cred) {
+		perms |= perm
+	}
+	return perms
+}
+
+// candidates returns the permissions of the entries of the ACL that apply to the credentials. Access is granted if
+// one of them includes all the wanted permissions.
+func (a ACL) candidates(owner, group uint32, isDir bool, cred Credentials) []Perm {
+	if cred.DACOverride {
+		if isDir || a.Mode()&0o111 != 0 {
+			return []Perm{All}
+		}
+		return []Perm{Read | Write}
+	}
+
+	mask := All
+	if entry, ok := a.Entry(Mask); ok {
+		mask = entry.Perm
+	}
+
+	if cred.UID == owner {
+		entry, _ := a.Entry(UserObj)
+		return []Perm{entry.Perm}
+	}
+	for _, entry := range a {
+		if entry.Tag == User && entry.ID == cred.UID {
+			return []Perm{entry.Perm & mask}
+		}
+	}
+
+	var groups []Perm
+	for _, entry := range a {
+		if (entry.Tag == GroupObj && cred.inGroup(group)) || (entry.Tag == Group && cred.inGroup(entry.ID)) {
+			groups = append(groups, entry.Perm&mask)
+		}
+	}
+	if len(groups) > 0 {
+		return groups
+	}
+
+	entry, _ := a.Entry(Other)
+	return []Perm{entry.Perm}
+}
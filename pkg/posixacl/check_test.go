@@ -0,0 +1,130 @@
+package posixacl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	const (
+		owner = 1000
+		group = 1000
+		alice = 1001
+		staff = 50
+		audit = 51
+	)
+	acl := ACL{
+		{Tag: UserObj, Perm: Read | Write},
+		{Tag: User, ID: alice, Perm: Read | Write},
+		{Tag: GroupObj, Perm: Read},
+		{Tag: Group, ID: staff, Perm: Read | Execute},
+		{Tag: Group, ID: audit, Perm: Write},
+		{Tag: Mask, Perm: Read | Execute},
+		{Tag: Other, Perm: 0},
+	}
+
+	testCases := []struct {
+		name        string
+		cred        Credentials
+		want        Perm
+		allowed     bool
+		permissions Perm
+	}{
+		{
+			name:        "Owner",
+			cred:        Credentials{UID: owner, GIDs: []uint32{staff}},
+			want:        Read | Write,
+			allowed:     true,
+			permissions: Read | Write,
+		},
+		{
+			name:        "Named user is limited by the mask",
+			cred:        Credentials{UID: alice, GIDs: []uint32{staff}},
+			want:        Write,
+			allowed:     false,
+			permissions: Read,
+		},
+		{
+			name:        "Named group",
+			cred:        Credentials{UID: 2000, GIDs: []uint32{100, staff}},
+			want:        Read | Execute,
+			allowed:     true,
+			permissions: Read | Execute,
+		},
+		{
+			name:        "Owning group and named group",
+			cred:        Credentials{UID: 2000, GIDs: []uint32{group, staff}},
+			want:        Read | Execute,
+			allowed:     true,
+			permissions: Read | Execute,
+		},
+		{
+			name:        "Masked group entry",
+			cred:        Credentials{UID: 2000, GIDs: []uint32{audit}},
+			want:        Write,
+			allowed:     false,
+			permissions: 0,
+		},
+		{
+			name:        "Matching group class excludes other",
+			cred:        Credentials{UID: 2000, GIDs: []uint32{group}},
+			want:        Read,
+			allowed:     true,
+			permissions: Read,
+		},
+		{
+			name:        "Other",
+			cred:        Credentials{UID: 2000, GIDs: []uint32{100}},
+			want:        Read,
+			allowed:     false,
+			permissions: 0,
+		},
+		{
+			name:        "DAC override",
+			cred:        Credentials{UID: 0, DACOverride: true},
+			want:        All,
+			allowed:     true,
+			permissions: All,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.allowed, acl.Check(owner, group, false, tc.cred, tc.want))
+			assert.Equal(t, tc.permissions.String(), acl.Permissions(owner, group, false, tc.cred).String())
+		})
+	}
+}
+
+func TestCheckGroupEntriesAreNotCombined(t *testing.T) {
+	acl := ACL{
+		{Tag: UserObj, Perm: All},
+		{Tag: GroupObj, Perm: Read},
+		{Tag: Group, ID: 50, Perm: Write},
+		{Tag: Mask, Perm: All},
+		{Tag: Other, Perm: 0},
+	}
+	cred := Credentials{UID: 2000, GIDs: []uint32{1000, 50}}
+	assert.True(t, acl.Check(0, 1000, false, cred, Read))
+	assert.True(t, acl.Check(0, 1000, false, cred, Write))
+	assert.False(t, acl.Check(0, 1000, false, cred, Read|Write))
+	assert.Equal(t, Read|Write, acl.Permissions(0, 1000, false, cred))
+}
+
+func TestCheckDACOverride(t *testing.T) {
+	acl := FromMode(0o600)
+	cred := Credentials{UID: 0, DACOverride: true}
+	assert.True(t, acl.Check(1000, 1000, false, cred, Read|Write))
+	assert.False(t, acl.Check(1000, 1000, false, cred, Execute), "files can only be executed if an execute bit is set")
+	assert.Equal(t, Read|Write, acl.Permissions(1000, 1000, false, cred))
+	assert.True(t, acl.Check(1000, 1000, true, cred, Execute), "directories can always be searched")
+	assert.Equal(t, All, acl.Permissions(1000, 1000, true, cred))
+}
+
+func TestMode(t *testing.T) {
+	assert.Equal(t, "-rwxr-x---", FromMode(0o750).Mode().String())
+	acl := append(FromMode(0o640), Entry{Tag: Group, ID: 50, Perm: All}, Entry{Tag: Mask, Perm: Read | Write})
+	assert.Equal(t, "-rw-rw----", acl.Mode().String())
+	assert.False(t, acl.IsMinimal())
+	assert.True(t, FromMode(0o640).IsMinimal())
+}
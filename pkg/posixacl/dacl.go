@@ -0,0 +1,179 @@
+package posixacl
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/filemode"
+)
+
+// inheritableFlags are the flags of the ACEs that ACL.InheritableACEs returns
+const inheritableFlags = descriptor.ObjectInherit | descriptor.ContainerInherit | descriptor.InheritOnly
+
+// DACL returns the Windows ACL that is the closest to the ACL of a file owned by the owner and group SIDs: an allow
+// ACE for the owner, each named user, the owning group, each named group and Everyone, in this order. Named users and
+// groups use the S-1-22-1-<uid> and S-1-22-2-<gid> SIDs, and their permissions are limited by the mask. Entries
+// without permissions are left out, so the DACL of a minimal ACL is the one filemode.ToDACL returns for its mode.
+//
+// Windows combines the rights of every matching ACE, whereas a POSIX ACL only considers the first class of entries
+// that matches the process, so the DACL grants at least the permissions of the ACL, and sometimes more.
+func (a ACL) DACL(owner, group descriptor.SID) descriptor.ACL {
+	return a.toACEs(owner, group, 0)
+}
+
+// InheritableACEs returns the inherit-only ACEs that are equivalent to a default ACL: the same ACEs as DACL, with the
+// ObjectInherit, ContainerInherit and InheritOnly flags, where the owner and group of the files that inherit them are
+// Creator Owner and Creator Group.
+func (a ACL) InheritableACEs() descriptor.ACL {
+	return a.toACEs(descriptor.CreatorOwner, descriptor.CreatorGroup, inheritableFlags)
+}
+
+func (a ACL) toACEs(owner, group descriptor.SID, flags descriptor.ACEFlags) descriptor.ACL {
+	mask := All
+	if entry, ok := a.Entry(Mask); ok {
+		mask = entry.Perm
+	}
+	dacl := descriptor.ACL{}
+	for _, entry := range a.Sorted() {
+		var sid descriptor.SID
+		perm := entry.Perm
+		switch entry.Tag {
+		case UserObj:
+			sid = owner
+		case User:
+			sid, perm = descriptor.UnixUserSID(entry.ID), perm&mask
+		case GroupObj:
+			sid, perm = group, perm&mask
+		case Group:
+			sid, perm = descriptor.UnixGroupSID(entry.ID), perm&mask
+		case Other:
+			sid = descriptor.Everyone
+		default:
+			continue
+		}
+		if rights := filemode.Rights(fs.FileMode(perm)); rights != 0 {
+			dacl = append(dacl, descriptor.ACE{Type: descriptor.AccessAllowed, Flags: flags, Mask: rights, SID: sid})
+		}
+	}
+	return dacl
+}
+
+// FromDACL returns the access ACL equivalent to the ACEs of a DACL that apply to a file owned by the owner and group
+// SIDs. These must be allow ACEs for the owner, the group, Everyone and Unix users and groups (S-1-22-1-<uid> and
+// S-1-22-2-<gid>), such as the ACEs returned by ACL.DACL. A permission is granted when the trustee is granted all the
+// rights that filemode.Rights maps it to. The mask grants the union of the permissions of the group class, and the ACL
+// is minimal if the DACL only has ACEs for the owner, the group and Everyone.
+//
+// Inherit-only ACEs are ignored, and the inheritance flags of the other ACEs as well. Deny ACEs and other trustees
+// cannot be represented by a POSIX ACL, and return an error.
+func FromDACL(dacl descriptor.ACL, owner, group descriptor.SID) (ACL, error) {
+	b := newBuilder()
+	for i, ace := range dacl {
+		if !ace.AppliesToObject() {
+			continue
+		}
+		if err := b.add(ace, owner, group); err != nil {
+			return nil, fmt.Errorf("ACE %d: %w", i, err)
+		}
+	}
+	return b.acl(), nil
+}
+
+// DefaultFromDACL returns the default ACL equivalent to the inheritable ACEs of the DACL of a directory owned by the
+// owner and group SIDs, or nil if it has no inheritable ACEs. The ACEs for the owner and Creator Owner grant the
+// permissions of the UserObj entry, which applies to the owner of each new file / directory, and the ACEs for the
+// group and Creator Group the permissions of the GroupObj entry.
+//
+// POSIX ACLs do not tell files from directories, so ACEs that are only inherited by files (ObjectInherit) or by
+// directories (ContainerInherit) are inherited by both. ACEs that are not propagated beyond the children
+// (NoPropagateInherit) cannot be represented, and return an error, as do deny ACEs and other trustees.
+func DefaultFromDACL(dacl descriptor.ACL, owner, group descriptor.SID) (ACL, error) {
+	b := newBuilder()
+	inheritable := false
+	for i, ace := range dacl {
+		if ace.Flags&(descriptor.ObjectInherit|descriptor.ContainerInherit) == 0 {
+			continue
+		}
+		if ace.Flags&descriptor.NoPropagateInherit != 0 {
+			return nil, fmt.Errorf("ACE %d: ACEs that are not propagated beyond the children cannot be represented by a POSIX default ACL", i)
+		}
+		inheritable = true
+		sid := ace.SID
+		switch sid {
+		case owner:
+			sid = descriptor.CreatorOwner
+		case group:
+			sid = descriptor.CreatorGroup
+		}
+		ace.SID = sid
+		if err := b.add(ace, descriptor.CreatorOwner, descriptor.CreatorGroup); err != nil {
+			return nil, fmt.Errorf("ACE %d: %w", i, err)
+		}
+	}
+	if !inheritable {
+		return nil, nil
+	}
+	return b.acl(), nil
+}
+
+// builder accumulates the rights of the ACEs of a DACL per POSIX ACL entry
+type builder struct {
+	rights      map[Tag]uint32
+	userRights  map[uint32]uint32
+	groupRights map[uint32]uint32
+	users       []uint32
+	groups      []uint32
+}
+
+func newBuilder() *builder {
+	return &builder{rights: map[Tag]uint32{}, userRights: map[uint32]uint32{}, groupRights: map[uint32]uint32{}}
+}
+
+func (b *builder) add(ace descriptor.ACE, owner, group descriptor.SID) error {
+	if ace.Type != descriptor.AccessAllowed {
+		return fmt.Errorf("%s ACEs cannot be represented by a POSIX ACL", ace.Type)
+	}
+	mask := access.FileGenericMapping.Normalize(ace.Mask)
+	switch {
+	case ace.SID == owner:
+		b.rights[UserObj] |= mask
+	case ace.SID == group:
+		b.rights[GroupObj] |= mask
+	case ace.SID == descriptor.Everyone:
+		b.rights[Other] |= mask
+	default:
+		if uid, ok := ace.SID.UnixUser(); ok {
+			if _, seen := b.userRights[uid]; !seen {
+				b.users = append(b.users, uid)
+			}
+			b.userRights[uid] |= mask
+		} else if gid, ok := ace.SID.UnixGroup(); ok {
+			if _, seen := b.groupRights[gid]; !seen {
+				b.groups = append(b.groups, gid)
+			}
+			b.groupRights[gid] |= mask
+		} else {
+			return fmt.Errorf("%s is neither the owner, the group, Everyone nor a Unix account", ace.SID)
+		}
+	}
+	return nil
+}
+
+func (b *builder) acl() ACL {
+	acl := ACL{{Tag: UserObj, Perm: perm(b.rights[UserObj])}}
+	for _, uid := range b.users {
+		acl = append(acl, Entry{Tag: User, ID: uid, Perm: perm(b.userRights[uid])})
+	}
+	acl = append(acl, Entry{Tag: GroupObj, Perm: perm(b.rights[GroupObj])})
+	for _, gid := range b.groups {
+		acl = append(acl, Entry{Tag: Group, ID: gid, Perm: perm(b.groupRights[gid])})
+	}
+	acl = append(acl, Entry{Tag: Other, Perm: perm(b.rights[Other])})
+	return acl.WithMask()
+}
+
+func perm(rights uint32) Perm {
+	return Perm(filemode.Perm(rights))
+}
@@ -0,0 +1,100 @@
+package posixacl
+
+import (
+	"testing"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/filemode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDACL(t *testing.T) {
+	owner := descriptor.UnixUserSID(1000)
+	group := descriptor.UnixGroupSID(100)
+	assert.Equal(t, filemode.ToDACL(0754, owner, group), FromMode(0754).DACL(owner, group))
+
+	acl := ACL{
+		{Tag: UserObj, Perm: All},
+		{Tag: User, ID: 1001, Perm: All},
+		{Tag: GroupObj, Perm: Read | Execute},
+		{Tag: Group, ID: 50, Perm: Write},
+		{Tag: Mask, Perm: Read | Execute},
+		{Tag: Other, Perm: 0},
+	}
+	dacl := acl.DACL(owner, group)
+	assert.Equal(t, descriptor.ACL{
+		{Type: descriptor.AccessAllowed, Mask: filemode.Rights(07), SID: owner},
+		{Type: descriptor.AccessAllowed, Mask: filemode.Rights(05), SID: descriptor.UnixUserSID(1001)},
+		{Type: descriptor.AccessAllowed, Mask: filemode.Rights(05), SID: group},
+	}, dacl)
+
+	parsed, err := FromDACL(dacl, owner, group)
+	require.NoError(t, err)
+	assert.Equal(t, ACL{
+		{Tag: UserObj, Perm: All},
+		{Tag: User, ID: 1001, Perm: Read | Execute},
+		{Tag: GroupObj, Perm: Read | Execute},
+		{Tag: Mask, Perm: Read | Execute},
+		{Tag: Other, Perm: 0},
+	}, parsed)
+
+	parsed, err = FromDACL(filemode.ToDACL(0640, owner, group), owner, group)
+	require.NoError(t, err)
+	assert.Equal(t, FromMode(0640), parsed)
+
+	for _, invalid := range []descriptor.ACL{
+		{{Type: descriptor.AccessDenied, Mask: access.Write, SID: group}},
+		{{Type: descriptor.AccessAllowed, Mask: access.Read, SID: descriptor.BuiltinUsers}},
+	} {
+		_, err := FromDACL(invalid, owner, group)
+		assert.Error(t, err)
+	}
+}
+
+func TestDefaultACL(t *testing.T) {
+	owner := descriptor.UnixUserSID(1000)
+	group := descriptor.UnixGroupSID(100)
+	inherit := descriptor.ObjectInherit | descriptor.ContainerInherit
+	dacl := descriptor.ACL{
+		{Type: descriptor.AccessAllowed, Flags: inherit, Mask: filemode.Rights(07), SID: owner},
+		{Type: descriptor.AccessAllowed, Flags: inherit | descriptor.InheritOnly, Mask: filemode.Rights(05), SID: descriptor.CreatorGroup},
+		{Type: descriptor.AccessAllowed, Flags: descriptor.ObjectInherit | descriptor.InheritOnly, Mask: filemode.Rights(04), SID: descriptor.UnixUserSID(1001)},
+		{Type: descriptor.AccessAllowed, Mask: filemode.Rights(05), SID: descriptor.Everyone},
+	}
+
+	access, err := FromDACL(dacl, owner, group)
+	require.NoError(t, err)
+	assert.Equal(t, FromMode(0705), access)
+
+	defaults, err := DefaultFromDACL(dacl, owner, group)
+	require.NoError(t, err)
+	expected := ACL{
+		{Tag: UserObj, Perm: All},
+		{Tag: User, ID: 1001, Perm: Read},
+		{Tag: GroupObj, Perm: Read | Execute},
+		{Tag: Mask, Perm: Read | Execute},
+		{Tag: Other, Perm: 0},
+	}
+	assert.Equal(t, expected, defaults)
+
+	inheritable := expected.InheritableACEs()
+	assert.Equal(t, descriptor.ACL{
+		{Type: descriptor.AccessAllowed, Flags: inheritableFlags, Mask: filemode.Rights(07), SID: descriptor.CreatorOwner},
+		{Type: descriptor.AccessAllowed, Flags: inheritableFlags, Mask: filemode.Rights(04), SID: descriptor.UnixUserSID(1001)},
+		{Type: descriptor.AccessAllowed, Flags: inheritableFlags, Mask: filemode.Rights(05), SID: descriptor.CreatorGroup},
+	}, inheritable)
+	roundTrip, err := DefaultFromDACL(inheritable, owner, group)
+	require.NoError(t, err)
+	assert.Equal(t, expected, roundTrip)
+
+	defaults, err = DefaultFromDACL(filemode.ToDACL(0755, owner, group), owner, group)
+	require.NoError(t, err)
+	assert.Nil(t, defaults)
+
+	_, err = DefaultFromDACL(descriptor.ACL{
+		{Type: descriptor.AccessAllowed, Flags: descriptor.ContainerInherit | descriptor.NoPropagateInherit, Mask: filemode.Rights(07), SID: owner},
+	}, owner, group)
+	assert.Error(t, err)
+}
@@ -0,0 +1,400 @@
+package posixacl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os/user"
+	"strconv"
+	"strings"
+)
+
+// Names converts user and group IDs to and from the names printed by getfacl. The zero value prints numeric IDs, like
+// getfacl --numeric, and only accepts numeric IDs.
+type Names struct {
+	// UserName returns the name of the user, or false to print the numeric ID
+	UserName func(uid uint32) (string, bool)
+	// GroupName returns the name of the group, or false to print the numeric ID
+	GroupName func(gid uint32) (string, bool)
+	// LookupUser returns the ID of the user with the name
+	LookupUser func(name string) (uint32, error)
+	// LookupGroup returns the ID of the group with the name
+	LookupGroup func(name string) (uint32, error)
+}
+
+// SystemNames resolves names with the user and group databases of the system, as getfacl and setfacl do
+var SystemNames = Names{
+	UserName: func(uid uint32) (string, bool) {
+		u, err := user.LookupId(strconv.FormatUint(uint64(uid), 10))
+		if err != nil {
+			return "", false
+		}
+		return u.Username, true
+	},
+	GroupName: func(gid uint32) (string, bool) {
+		g, err := user.LookupGroupId(strconv.FormatUint(uint64(gid), 10))
+		if err != nil {
+			return "", false
+		}
+		return g.Name, true
+	},
+	LookupUser: func(name string) (uint32, error) {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return 0, err
+		}
+		id, err := strconv.ParseUint(u.Uid, 10, 32)
+		return uint32(id), err
+	},
+	LookupGroup: func(name string) (uint32, error) {
+		g, err := user.LookupGroup(name)
+		if err != nil {
+			return 0, err
+		}
+		id, err := strconv.ParseUint(g.Gid, 10, 32)
+		return uint32(id), err
+	},
+}
+
+func (n Names) user(uid uint32) string {
+	if n.UserName != nil {
+		if name, ok := n.UserName(uid); ok {
+			return escape(name)
+		}
+	}
+	return strconv.FormatUint(uint64(uid), 10)
+}
+
+func (n Names) group(gid uint32) string {
+	if n.GroupName != nil {
+		if name, ok := n.GroupName(gid); ok {
+			return escape(name)
+		}
+	}
+	return strconv.FormatUint(uint64(gid), 10)
+}
+
+func (n Names) lookupUser(s string) (uint32, error) {
+	return lookupName(s, n.LookupUser, "user")
+}
+
+func (n Names) lookupGroup(s string) (uint32, error) {
+	return lookupName(s, n.LookupGroup, "group")
+}
+
+// lookupName converts a numeric ID or an escaped name to an ID
+func lookupName(s string, lookup func(string) (uint32, error), kind string) (uint32, error) {
+	if id, err := strconv.ParseUint(s, 10, 32); err == nil {
+		return uint32(id), nil
+	}
+	name, err := unescape(s)
+	if err != nil {
+		return 0, err
+	}
+	if lookup == nil {
+		return 0, fmt.Errorf("unknown %s %q: names cannot be resolved", kind, name)
+	}
+	id, err := lookup(name)
+	if err != nil {
+		return 0, fmt.Errorf("unknown %s %q: %w", kind, name, err)
+	}
+	return id, nil
+}
+
+// FileACL holds the ACLs of a file / directory, as listed by getfacl
+type FileACL struct {
+	Path string
+	UID  uint32
+	GID  uint32
+	// Flags holds the fs.ModeSetuid, fs.ModeSetgid and fs.ModeSticky bits of the file mode
+	Flags fs.FileMode
+	// Access is the ACL used in access checks against the file / directory
+	Access ACL
+	// Default is the ACL inherited by the files / directories created in a directory, or nil if there is none
+	Default ACL
+}
+
+// FormatEntry returns the entry in the getfacl text format (e.g. "user:alice:r-x")
+func FormatEntry(entry Entry, names Names) string {
+	qualifier := ""
+	switch entry.Tag {
+	case User:
+		qualifier = names.user(entry.ID)
+	case Group:
+		qualifier = names.group(entry.ID)
+	}
+	return entry.Tag.String() + ":" + qualifier + ":" + entry.Perm.String()
+}
+
+// WriteText writes the ACLs of the files in the format of getfacl --absolute-names: a header with the path, owner,
+// group and special mode flags of each file, its access entries, its default entries prefixed with "default:", and an
+// empty line. Like getfacl, the entries whose permissions are restricted by the mask are followed by an "#effective:"
+// comment.
+func WriteText(w io.Writer, files []FileACL, names Names) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range files {
+		fmt.Fprintf(bw, "# file: %s\n", escape(f.Path))
+		fmt.Fprintf(bw, "# owner: %s\n", names.user(f.UID))
+		fmt.Fprintf(bw, "# group: %s\n", names.group(f.GID))
+		if f.Flags&(fs.ModeSetuid|fs.ModeSetgid|fs.ModeSticky) != 0 {
+			fmt.Fprintf(bw, "# flags: %s\n", formatFlags(f.Flags))
+		}
+		writeEntries(bw, "", f.Access, names)
+		writeEntries(bw, "default:", f.Default, names)
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+func writeEntries(w *bufio.Writer, prefix string, acl ACL, names Names) {
+	mask, hasMask := acl.Entry(Mask)
+	for _, entry := range acl {
+		line := prefix + FormatEntry(entry, names)
+		w.WriteString(line)
+		if hasMask && (entry.Tag == User || entry.Tag == GroupObj || entry.Tag == Group) && entry.Perm&^mask.Perm != 0 {
+			// getfacl aligns the comments on the fifth tab stop
+			for n := len(line); n < 32; n = (n + 8) &^ 7 {
+				w.WriteString("\t")
+			}
+			w.WriteString("#effective:" + (entry.Perm & mask.Perm).String())
+		}
+		w.WriteString("\n")
+	}
+}
+
+func formatFlags(mode fs.FileMode) string {
+	b := []byte("---")
+	if mode&fs.ModeSetuid != 0 {
+		b[0] = 's'
+	}
+	if mode&fs.ModeSetgid != 0 {
+		b[1] = 's'
+	}
+	if mode&fs.ModeSticky != 0 {
+		b[2] = 't'
+	}
+	return string(b)
+}
+
+// ReadText parses the output of getfacl, such as a backup made with getfacl --absolute-names -R. Comments other than
+// the file headers, such as "#effective:" comments, are ignored.
+func ReadText(r io.Reader, names Names) ([]FileACL, error) {
+	var files []FileACL
+	var current *FileACL
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			current = nil
+			continue
+		}
+		if err := parseLine(line, &files, &current, names); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func parseLine(line string, files *[]FileACL, current **FileACL, names Names) error {
+	if strings.HasPrefix(line, "#") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line[1:]), ":")
+		if !ok {
+			return nil
+		}
+		value = strings.TrimSpace(value)
+		if key == "file" {
+			path, err := unescape(value)
+			if err != nil {
+				return err
+			}
+			*files = append(*files, FileACL{Path: path})
+			*current = &(*files)[len(*files)-1]
+			return nil
+		}
+		if *current == nil {
+			return nil
+		}
+		var err error
+		switch key {
+		case "owner":
+			(*current).UID, err = names.lookupUser(value)
+		case "group":
+			(*current).GID, err = names.lookupGroup(value)
+		case "flags":
+			(*current).Flags, err = parseFlags(value)
+		}
+		return err
+	}
+	if *current == nil {
+		return fmt.Errorf("entry %q does not follow a # file: header", line)
+	}
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		line = strings.TrimSpace(line[:i])
+	}
+	entry, isDefault, err := parseEntry(line, names)
+	if err != nil {
+		return err
+	}
+	if isDefault {
+		(*current).Default = append((*current).Default, entry)
+	} else {
+		(*current).Access = append((*current).Access, entry)
+	}
+	return nil
+}
+
+func parseFlags(s string) (fs.FileMode, error) {
+	if len(s) != 3 {
+		return 0, fmt.Errorf("invalid flags %q", s)
+	}
+	var mode fs.FileMode
+	for i, bit := range []fs.FileMode{fs.ModeSetuid, fs.ModeSetgid, fs.ModeSticky} {
+		switch s[i] {
+		case '-':
+		case "sst"[i]:
+			mode |= bit
+		default:
+			return 0, fmt.Errorf("invalid flags %q", s)
+		}
+	}
+	return mode, nil
+}
+
+// ParseEntries parses ACL entries separated by commas or new lines, in the syntax accepted by setfacl --set and -m
+// (e.g. "u::rwx,u:alice:r-x,g::r-x,m::r-x,o::---,d:u::rwx"). Tags can be abbreviated to their first letter and the
+// "default:" prefix to "d:". It returns the access entries and the default entries separately.
+func ParseEntries(s string, names Names) (access, defaults ACL, err error) {
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' }) {
+		field = strings.TrimSpace(field)
+		if field == "" || strings.HasPrefix(field, "#") {
+			continue
+		}
+		entry, isDefault, err := parseEntry(field, names)
+		if err != nil {
+			return nil, nil, err
+		}
+		if isDefault {
+			defaults = append(defaults, entry)
+		} else {
+			access = append(access, entry)
+		}
+	}
+	return access, defaults, nil
+}
+
+// parseEntry parses an entry such as "user:alice:r-x" or "default:group::r-x"
+func parseEntry(s string, names Names) (Entry, bool, error) {
+	fields := strings.Split(s, ":")
+	isDefault := false
+	if len(fields) > 1 && (fields[0] == "default" || fields[0] == "d") {
+		isDefault, fields = true, fields[1:]
+	}
+	if len(fields) == 2 {
+		// mask and other entries have no qualifier, so setfacl accepts them without the empty field
+		fields = []string{fields[0], "", fields[1]}
+	}
+	if len(fields) != 3 {
+		return Entry{}, false, fmt.Errorf("invalid entry %q: expected TAG:QUALIFIER:PERMS", s)
+	}
+	perm, err := parsePerm(fields[2])
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("invalid entry %q: %w", s, err)
+	}
+	entry := Entry{Perm: perm}
+	qualifier := fields[1]
+	switch fields[0] {
+	case "user", "u":
+		entry.Tag = UserObj
+		if qualifier != "" {
+			entry.Tag = User
+			entry.ID, err = names.lookupUser(qualifier)
+		}
+	case "group", "g":
+		entry.Tag = GroupObj
+		if qualifier != "" {
+			entry.Tag = Group
+			entry.ID, err = names.lookupGroup(qualifier)
+		}
+	case "mask", "m":
+		entry.Tag = Mask
+	case "other", "o":
+		entry.Tag = Other
+	default:
+		return Entry{}, false, fmt.Errorf("invalid entry %q: unknown tag %q", s, fields[0])
+	}
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("invalid entry %q: %w", s, err)
+	}
+	if (entry.Tag == Mask || entry.Tag == Other) && qualifier != "" {
+		return Entry{}, false, fmt.Errorf("invalid entry %q: %s entries have no qualifier", s, entry.Tag)
+	}
+	return entry, isDefault, nil
+}
+
+// parsePerm parses permissions such as "r-x", "rw" or an octal digit
+func parsePerm(s string) (Perm, error) {
+	if len(s) == 1 && s[0] >= '0' && s[0] <= '7' {
+		return Perm(s[0] - '0'), nil
+	}
+	var perm Perm
+	for _, c := range s {
+		switch c {
+		case 'r':
+			perm |= Read
+		case 'w':
+			perm |= Write
+		case 'x':
+			perm |= Execute
+		case '-':
+		default:
+			return 0, fmt.Errorf("invalid permissions %q", s)
+		}
+	}
+	if s == "" {
+		return 0, fmt.Errorf("missing permissions")
+	}
+	return perm, nil
+}
+
+// escape replaces the characters that would break the text format with octal escapes (e.g. "\040" for a space), like
+// getfacl does
+func escape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' || c <= ' ' || c == 0x7f {
+			fmt.Fprintf(&sb, "\\%03o", c)
+		} else {
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// unescape decodes the octal escapes added by escape
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		if i+4 > len(s) {
+			return "", fmt.Errorf("invalid escape sequence in %q", s)
+		}
+		v, err := strconv.ParseUint(s[i+1:i+4], 8, 8)
+		if err != nil {
+			return "", fmt.Errorf("invalid escape sequence in %q", s)
+		}
+		sb.WriteByte(byte(v))
+		i += 3
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,133 @@
+package posixacl
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testNames resolves a fixed set of users and groups
+var testNames = Names{
+	UserName: func(uid uint32) (string, bool) {
+		name, ok := map[uint32]string{0: "root", 1001: "alice", 1002: "john doe"}[uid]
+		return name, ok
+	},
+	GroupName: func(gid uint32) (string, bool) {
+		name, ok := map[uint32]string{0: "root", 4: "adm"}[gid]
+		return name, ok
+	},
+	LookupUser: func(name string) (uint32, error) {
+		if uid, ok := map[string]uint32{"root": 0, "alice": 1001, "john doe": 1002}[name]; ok {
+			return uid, nil
+		}
+		return 0, fmt.Errorf("no such user")
+	},
+	LookupGroup: func(name string) (uint32, error) {
+		if gid, ok := map[string]uint32{"root": 0, "adm": 4}[name]; ok {
+			return gid, nil
+		}
+		return 0, fmt.Errorf("no such group")
+	},
+}
+
+const getfaclDump = `# file: /srv/data
+# owner: root
+# group: adm
+# flags: -s-
+user::rwx
+user:alice:rwx			#effective:r-x
+user:john\040doe:r--
+group::r-x
+group:adm:rwx			#effective:r-x
+mask::r-x
+other::---
+default:user::rwx
+default:group::r-x
+default:other::---
+
+# file: /srv/data/file\040name
+# owner: 1003
+# group: root
+user::rw-
+group::r--
+other::r--
+
+`
+
+func TestText(t *testing.T) {
+	files, err := ReadText(strings.NewReader(getfaclDump), testNames)
+	require.NoError(t, err)
+	assert.Equal(t, []FileACL{
+		{
+			Path:  "/srv/data",
+			UID:   0,
+			GID:   4,
+			Flags: fs.ModeSetgid,
+			Access: ACL{
+				{Tag: UserObj, Perm: All},
+				{Tag: User, ID: 1001, Perm: All},
+				{Tag: User, ID: 1002, Perm: Read},
+				{Tag: GroupObj, Perm: Read | Execute},
+				{Tag: Group, ID: 4, Perm: All},
+				{Tag: Mask, Perm: Read | Execute},
+				{Tag: Other, Perm: 0},
+			},
+			Default: ACL{
+				{Tag: UserObj, Perm: All},
+				{Tag: GroupObj, Perm: Read | Execute},
+				{Tag: Other, Perm: 0},
+			},
+		},
+		{
+			Path:   "/srv/data/file name",
+			UID:    1003,
+			Access: FromMode(0644),
+		},
+	}, files)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteText(&buf, files, testNames))
+	assert.Equal(t, getfaclDump, buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteText(&buf, files[1:], Names{}))
+	assert.Equal(t, "# file: /srv/data/file\\040name\n# owner: 1003\n# group: 0\nuser::rw-\ngroup::r--\nother::r--\n\n", buf.String())
+}
+
+func TestReadTextInvalid(t *testing.T) {
+	for _, text := range []string{
+		"user::rwx\n",
+		"# file: /a\nuser:bob:rwx\n",
+		"# file: /a\nuser::rwz\n",
+		"# file: /a\nowner::rwx\n",
+		"# file: /a\nmask:alice:rwx\n",
+		"# file: /a\n# flags: x--\n",
+		"# file: /a\\04\n",
+	} {
+		_, err := ReadText(strings.NewReader(text), testNames)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestParseEntries(t *testing.T) {
+	access, defaults, err := ParseEntries("u::rwx,u:alice:r-x,g::5,m::rx,o::-,d:u::rwx,default:o:r", testNames)
+	require.NoError(t, err)
+	assert.Equal(t, ACL{
+		{Tag: UserObj, Perm: All},
+		{Tag: User, ID: 1001, Perm: Read | Execute},
+		{Tag: GroupObj, Perm: Read | Execute},
+		{Tag: Mask, Perm: Read | Execute},
+		{Tag: Other, Perm: 0},
+	}, access)
+	assert.Equal(t, ACL{{Tag: UserObj, Perm: All}, {Tag: Other, Perm: Read}}, defaults)
+
+	_, _, err = ParseEntries("u:1001:rwx", Names{})
+	assert.NoError(t, err)
+	_, _, err = ParseEntries("u:alice:rwx", Names{})
+	assert.Error(t, err)
+}
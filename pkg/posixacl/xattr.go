@@ -0,0 +1,162 @@
+package posixacl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// Extended attributes that hold POSIX ACLs on Linux
+const (
+	// AccessXattr holds the ACL used in access checks against the file / directory
+	AccessXattr = "system.posix_acl_access"
+	// DefaultXattr holds the ACL inherited by files / directories created in a directory
+	DefaultXattr = "system.posix_acl_default"
+)
+
+const (
+	// xattrVersion is the version of the extended attribute format (POSIX_ACL_XATTR_VERSION)
+	xattrVersion = 0x0002
+	// undefinedID is the ID stored in the entries that have no qualifier (ACL_UNDEFINED_ID)
+	undefinedID  = 0xFFFFFFFF
+	headerLength = 4
+	entryLength  = 8
+)
+
+// ParseXattr decodes an ACL stored in the system.posix_acl_access or system.posix_acl_default extended attribute: a
+// little-endian version header followed by (tag, perm, id) entries. The ACL is validated with Validate.
+func ParseXattr(b []byte) (ACL, error) {
+	if len(b) < headerLength {
+		return nil, fmt.Errorf("POSIX ACL is too short: %d bytes", len(b))
+	}
+	if version := binary.LittleEndian.Uint32(b); version != xattrVersion {
+		return nil, fmt.Errorf("unsupported POSIX ACL version %d", version)
+	}
+	if (len(b)-headerLength)%entryLength != 0 {
+		return nil, fmt.Errorf("invalid POSIX ACL size %d", len(b))
+	}
+	acl := make(ACL, 0, (len(b)-headerLength)/entryLength)
+	for i := headerLength; i < len(b); i += entryLength {
+		entry := Entry{
+			Tag:  Tag(binary.LittleEndian.Uint16(b[i:])),
+			Perm: Perm(binary.LittleEndian.Uint16(b[i+2:])),
+		}
+		if entry.Tag == User || entry.Tag == Group {
+			entry.ID = binary.LittleEndian.Uint32(b[i+4:])
+		}
+		acl = append(acl, entry)
+	}
+	if err := acl.Validate(); err != nil {
+		return nil, err
+	}
+	return acl, nil
+}
+
+// MarshalXattr encodes the ACL in the format of the system.posix_acl_access and system.posix_acl_default extended
+// attributes. The ACL is validated with Validate, and its entries are sorted in the order the kernel requires.
+func (a ACL) MarshalXattr() ([]byte, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	b := make([]byte, headerLength, headerLength+len(a)*entryLength)
+	binary.LittleEndian.PutUint32(b, xattrVersion)
+	for _, entry := range a.Sorted() {
+		id := uint32(undefinedID)
+		if entry.Tag == User || entry.Tag == Group {
+			id = entry.ID
+		}
+		b = binary.LittleEndian.AppendUint16(b, uint16(entry.Tag))
+		b = binary.LittleEndian.AppendUint16(b, uint16(entry.Perm))
+		b = binary.LittleEndian.AppendUint32(b, id)
+	}
+	return b, nil
+}
+
+// Validate checks that the ACL is valid as defined by acl_valid(3): it must have exactly one UserObj, GroupObj and
+// Other entry, at most one User entry per user and one Group entry per group, and a single Mask entry, which is
+// required as soon as there are User or Group entries. An empty ACL is not valid.
+func (a ACL) Validate() error {
+	counts := make(map[Tag]int)
+	users := make(map[uint32]bool)
+	groups := make(map[uint32]bool)
+	for i, entry := range a {
+		if entry.Perm&^All != 0 {
+			return fmt.Errorf("entry %d: invalid permissions %#o", i, entry.Perm)
+		}
+		switch entry.Tag {
+		case User:
+			if users[entry.ID] {
+				return fmt.Errorf("entry %d: duplicate entry for user %d", i, entry.ID)
+			}
+			users[entry.ID] = true
+		case Group:
+			if groups[entry.ID] {
+				return fmt.Errorf("entry %d: duplicate entry for group %d", i, entry.ID)
+			}
+			groups[entry.ID] = true
+		case UserObj, GroupObj, Mask, Other:
+			if counts[entry.Tag] > 0 {
+				return fmt.Errorf("entry %d: duplicate %s entry", i, entryName(entry.Tag))
+			}
+		default:
+			return fmt.Errorf("entry %d: unknown tag %#x", i, uint16(entry.Tag))
+		}
+		counts[entry.Tag]++
+	}
+	for _, tag := range []Tag{UserObj, GroupObj, Other} {
+		if counts[tag] == 0 {
+			return fmt.Errorf("missing %s entry", entryName(tag))
+		}
+	}
+	if (len(users) > 0 || len(groups) > 0) && counts[Mask] == 0 {
+		return fmt.Errorf("missing mask entry: required with named user or group entries")
+	}
+	return nil
+}
+
+// entryName returns the name of the entries with the tag as getfacl prints them (e.g. "user::")
+func entryName(tag Tag) string {
+	if tag == User || tag == Group {
+		return tag.String()
+	}
+	return tag.String() + "::"
+}
+
+// Sorted returns a copy of the ACL with its entries in canonical order: by tag (UserObj, User, GroupObj, Group, Mask,
+// Other), then by ID. This is the order getfacl prints them in and the kernel stores them in.
+func (a ACL) Sorted() ACL {
+	sorted := make(ACL, len(a))
+	copy(sorted, a)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Tag != sorted[j].Tag {
+			return sorted[i].Tag < sorted[j].Tag
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
+}
+
+// WithMask returns a copy of the ACL with a Mask entry that grants the union of the permissions of the group class
+// (User, GroupObj and Group entries), as setfacl computes it, if the ACL has User or Group entries. Otherwise, the
+// Mask entry is removed, since it is not needed.
+func (a ACL) WithMask() ACL {
+	var mask Perm
+	extended := false
+	result := make(ACL, 0, len(a)+1)
+	for _, entry := range a {
+		switch entry.Tag {
+		case Mask:
+			continue
+		case User, Group:
+			extended = true
+			mask |= entry.Perm
+		case GroupObj:
+			mask |= entry.Perm
+		}
+		result = append(result, entry)
+	}
+	if extended {
+		result = append(result, Entry{Tag: Mask, Perm: mask})
+	}
+	return result.Sorted()
+}
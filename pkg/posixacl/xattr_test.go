@@ -0,0 +1,96 @@
+package posixacl
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// xattr builds a system.posix_acl_access value from (tag, perm, id) entries
+func xattr(version uint32, entries ...[3]uint32) []byte {
+	b := binary.LittleEndian.AppendUint32(nil, version)
+	for _, e := range entries {
+		b = binary.LittleEndian.AppendUint16(b, uint16(e[0]))
+		b = binary.LittleEndian.AppendUint16(b, uint16(e[1]))
+		b = binary.LittleEndian.AppendUint32(b, e[2])
+	}
+	return b
+}
+
+func TestXattr(t *testing.T) {
+	acl := ACL{
+		{Tag: Other, Perm: Read},
+		{Tag: Group, ID: 50, Perm: Read | Execute},
+		{Tag: User, ID: 1002, Perm: Read},
+		{Tag: UserObj, Perm: All},
+		{Tag: User, ID: 1001, Perm: Read | Write},
+		{Tag: Mask, Perm: All},
+		{Tag: GroupObj, Perm: Read},
+	}
+	expected := xattr(2,
+		[3]uint32{0x01, 7, undefinedID},
+		[3]uint32{0x02, 6, 1001},
+		[3]uint32{0x02, 4, 1002},
+		[3]uint32{0x04, 4, undefinedID},
+		[3]uint32{0x08, 5, 50},
+		[3]uint32{0x10, 7, undefinedID},
+		[3]uint32{0x20, 4, undefinedID},
+	)
+	b, err := acl.MarshalXattr()
+	require.NoError(t, err)
+	assert.Equal(t, expected, b)
+
+	parsed, err := ParseXattr(b)
+	require.NoError(t, err)
+	assert.Equal(t, acl.Sorted(), parsed)
+
+	minimal, err := FromMode(0640).MarshalXattr()
+	require.NoError(t, err)
+	parsed, err = ParseXattr(minimal)
+	require.NoError(t, err)
+	assert.Equal(t, FromMode(0640), parsed)
+}
+
+func TestParseXattrInvalid(t *testing.T) {
+	userObj := [3]uint32{0x01, 7, undefinedID}
+	groupObj := [3]uint32{0x04, 5, undefinedID}
+	other := [3]uint32{0x20, 0, undefinedID}
+	testCases := map[string][]byte{
+		"Too short":       {2, 0},
+		"Version":         xattr(1, userObj, groupObj, other),
+		"Truncated entry": xattr(2, userObj, groupObj, other)[:27],
+		"Empty":           xattr(2),
+		"Missing other":   xattr(2, userObj, groupObj),
+		"Duplicate owner": xattr(2, userObj, userObj, groupObj, other),
+		"Missing mask":    xattr(2, userObj, [3]uint32{0x02, 7, 1000}, groupObj, other),
+		"Duplicate user":  xattr(2, userObj, [3]uint32{0x02, 7, 1000}, [3]uint32{0x02, 5, 1000}, groupObj, [3]uint32{0x10, 7, undefinedID}, other),
+		"Unknown tag":     xattr(2, userObj, groupObj, [3]uint32{0x40, 7, undefinedID}, other),
+		"Invalid perm":    xattr(2, userObj, groupObj, [3]uint32{0x20, 8, undefinedID}),
+	}
+	for name, b := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseXattr(b)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestWithMask(t *testing.T) {
+	acl := ACL{
+		{Tag: UserObj, Perm: All},
+		{Tag: GroupObj, Perm: Read},
+		{Tag: Group, ID: 50, Perm: Write},
+		{Tag: Mask, Perm: 0},
+		{Tag: Other, Perm: 0},
+	}
+	assert.Equal(t, ACL{
+		{Tag: UserObj, Perm: All},
+		{Tag: GroupObj, Perm: Read},
+		{Tag: Group, ID: 50, Perm: Write},
+		{Tag: Mask, Perm: Read | Write},
+		{Tag: Other, Perm: 0},
+	}, acl.WithMask())
+	assert.Equal(t, FromMode(0750), append(FromMode(0750), Entry{Tag: Mask, Perm: All}).WithMask())
+}
@@ -0,0 +1,143 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/icacls"
+	"gopkg.in/yaml.v3"
+)
+
+// Trustee identifies the account of an ACE, owner or group: a SID (e.g. "S-1-5-32-544") or an account name (e.g.
+// `BUILTIN\Administrators`)
+type Trustee string
+
+// LookupFunc returns the SID of an account name
+type LookupFunc func(name string) (descriptor.SID, error)
+
+// TrusteeFromSID returns the trustee of a SID, which is its name as returned by names, or the SID itself if names is
+// nil or cannot resolve it
+func TrusteeFromSID(sid descriptor.SID, names icacls.NameFunc) Trustee {
+	if sid == "" {
+		return ""
+	}
+	if names != nil {
+		if name := names(sid); name != "" {
+			return Trustee(name)
+		}
+	}
+	return Trustee(sid)
+}
+
+// SID returns the SID of the trustee. Account names are resolved with lookup, or with icacls.WellKnownSID if lookup
+// is nil, in which case only well-known accounts such as Everyone or BUILTIN\Administrators can be resolved.
+func (t Trustee) SID(lookup LookupFunc) (descriptor.SID, error) {
+	if t == "" {
+		return "", fmt.Errorf("trustee cannot be empty")
+	}
+	if strings.HasPrefix(strings.ToUpper(string(t)), "S-1-") {
+		return descriptor.ParseSID(string(t))
+	}
+	if lookup != nil {
+		sid, err := lookup(string(t))
+		if err != nil {
+			return "", fmt.Errorf("unknown account %q: %w", string(t), err)
+		}
+		return sid, nil
+	}
+	if sid, ok := icacls.WellKnownSID(string(t)); ok {
+		return sid, nil
+	}
+	return "", fmt.Errorf("unknown account %q: only SIDs and well-known accounts can be resolved", string(t))
+}
+
+// ACE is the document form of a descriptor.ACE. It is written as an object with a type ("Allow", "Deny", "Audit" or
+// "Label"), a trustee, rights and flags, which are the SDDL abbreviations of the ACE flags (e.g. "OICI"). The rights
+// of label ACEs are the SDDL abbreviations of the label policy (e.g. "NWNR"), and those of other ACEs are the names of
+// file rights (e.g. "ReadAndExecute, Synchronize").
+type ACE struct {
+	Type    descriptor.ACEType
+	Trustee Trustee
+	Flags   descriptor.ACEFlags
+	Mask    uint32
+}
+
+// aceDocument is the serialized form of an ACE
+type aceDocument struct {
+	Type    descriptor.ACEType  `json:"type" yaml:"type"`
+	Trustee Trustee             `json:"trustee" yaml:"trustee"`
+	Rights  string              `json:"rights" yaml:"rights"`
+	Flags   descriptor.ACEFlags `json:"flags,omitempty" yaml:"flags,omitempty"`
+}
+
+// FromACE converts an ACE to its document form. The trustee is named as TrusteeFromSID does.
+func FromACE(ace descriptor.ACE, names icacls.NameFunc) ACE {
+	return ACE{Type: ace.Type, Trustee: TrusteeFromSID(ace.SID, names), Flags: ace.Flags, Mask: ace.Mask}
+}
+
+// ACE converts the document form to an ACE. The trustee is resolved with lookup; see Trustee.SID.
+func (a ACE) ACE(lookup LookupFunc) (descriptor.ACE, error) {
+	sid, err := a.Trustee.SID(lookup)
+	if err != nil {
+		return descriptor.ACE{}, err
+	}
+	return descriptor.ACE{Type: a.Type, Flags: a.Flags, Mask: a.Mask, SID: sid}, nil
+}
+
+// Rights returns the mask of the ACE in symbolic form
+func (a ACE) Rights() string {
+	if a.Type == descriptor.SystemMandatoryLabel {
+		return descriptor.LabelPolicy(a.Mask).String()
+	}
+	return access.FileRightsString(a.Mask)
+}
+
+func (a ACE) document() aceDocument {
+	return aceDocument{Type: a.Type, Trustee: a.Trustee, Rights: a.Rights(), Flags: a.Flags}
+}
+
+func (a *ACE) fromDocument(d aceDocument) error {
+	if d.Trustee == "" {
+		return fmt.Errorf("trustee cannot be empty")
+	}
+	*a = ACE{Type: d.Type, Trustee: d.Trustee, Flags: d.Flags}
+	if d.Type == descriptor.SystemMandatoryLabel {
+		policy, err := descriptor.ParseLabelPolicy(d.Rights)
+		a.Mask = uint32(policy)
+		return err
+	}
+	var err error
+	a.Mask, err = access.ParseFileRights(d.Rights)
+	return err
+}
+
+// MarshalJSON implements json.Marshaler
+func (a ACE) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.document())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *ACE) UnmarshalJSON(data []byte) error {
+	var d aceDocument
+	if err := json.Unmarshal(data, &d); err != nil {
+		return err
+	}
+	return a.fromDocument(d)
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (a ACE) MarshalYAML() (interface{}, error) {
+	return a.document(), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (a *ACE) UnmarshalYAML(value *yaml.Node) error {
+	var d aceDocument
+	if err := value.Decode(&d); err != nil {
+		return err
+	}
+	return a.fromDocument(d)
+}
@@ -0,0 +1,209 @@
+// Package schema defines a stable, versioned JSON and YAML representation of security descriptors, which can be
+// stored in configuration (e.g. Kubernetes config maps) and exchanged through APIs. For example:
+//
+//	version: 1
+//	owner: BUILTIN\Administrators
+//	group: S-1-5-18
+//	dacl:
+//	  protected: true
+//	  entries:
+//	    - type: Allow
+//	      trustee: NT AUTHORITY\SYSTEM
+//	      rights: FullControl
+//	      flags: OICI
+//	    - type: Allow
+//	      trustee: S-1-5-32-545
+//	      rights: ReadAndExecute, Synchronize
+//	      flags: OICI
+//
+// Trustees are SIDs or account names, and rights are the names of file rights as formatted by
+// access.FileRightsString. Documents are converted to and from pkg/descriptor security descriptors with
+// FromDescriptor and SecurityDescriptor.Descriptor.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/icacls"
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the version of the documents written by this package. Documents with another version are rejected.
+const Version = 1
+
+// SecurityDescriptor is the document form of a descriptor.SecurityDescriptor. The owner, group, DACL and SACL are
+// optional, and those that are left out are not part of the security descriptor.
+type SecurityDescriptor struct {
+	// Version is the version of the document. It is set to Version when the document is marshaled.
+	Version int     `json:"version" yaml:"version"`
+	Owner   Trustee `json:"owner,omitempty" yaml:"owner,omitempty"`
+	Group   Trustee `json:"group,omitempty" yaml:"group,omitempty"`
+	DACL    *ACL    `json:"dacl,omitempty" yaml:"dacl,omitempty"`
+	SACL    *ACL    `json:"sacl,omitempty" yaml:"sacl,omitempty"`
+}
+
+// ACL is the document form of a DACL or SACL, with the control flags of the security descriptor that apply to it
+type ACL struct {
+	// Protected prevents the ACL from inheriting ACEs from the parent object
+	Protected bool `json:"protected,omitempty" yaml:"protected,omitempty"`
+	// AutoInherited records that the ACL was set up to propagate inheritable ACEs to children
+	AutoInherited bool `json:"autoInherited,omitempty" yaml:"autoInherited,omitempty"`
+	// AutoInheritRequired requests the propagation of inheritable ACEs to children
+	AutoInheritRequired bool `json:"autoInheritRequired,omitempty" yaml:"autoInheritRequired,omitempty"`
+	// Null marks a NULL ACL, which has no entries. A NULL DACL grants full access to everyone, unlike an empty DACL.
+	Null    bool  `json:"null,omitempty" yaml:"null,omitempty"`
+	Entries []ACE `json:"entries" yaml:"entries"`
+}
+
+// MarshalJSON implements json.Marshaler. The version of the document is always Version.
+func (sd SecurityDescriptor) MarshalJSON() ([]byte, error) {
+	type document SecurityDescriptor
+	sd.Version = Version
+	return json.Marshal(document(sd))
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Documents whose version is not Version are rejected.
+func (sd *SecurityDescriptor) UnmarshalJSON(data []byte) error {
+	type document SecurityDescriptor
+	var d document
+	if err := json.Unmarshal(data, &d); err != nil {
+		return err
+	}
+	if err := checkVersion(d.Version); err != nil {
+		return err
+	}
+	*sd = SecurityDescriptor(d)
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler. The version of the document is always Version.
+func (sd SecurityDescriptor) MarshalYAML() (interface{}, error) {
+	type document SecurityDescriptor
+	sd.Version = Version
+	return document(sd), nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler. Documents whose version is not Version are rejected.
+func (sd *SecurityDescriptor) UnmarshalYAML(value *yaml.Node) error {
+	type document SecurityDescriptor
+	var d document
+	if err := value.Decode(&d); err != nil {
+		return err
+	}
+	if err := checkVersion(d.Version); err != nil {
+		return err
+	}
+	*sd = SecurityDescriptor(d)
+	return nil
+}
+
+func checkVersion(version int) error {
+	if version != Version {
+		return fmt.Errorf("unsupported security descriptor version %d: expected %d", version, Version)
+	}
+	return nil
+}
+
+// FromDescriptor converts a security descriptor to its document form. The trustees are named with names, or written
+// as SIDs if names is nil or cannot resolve them.
+func FromDescriptor(sd *descriptor.SecurityDescriptor, names icacls.NameFunc) *SecurityDescriptor {
+	d := &SecurityDescriptor{
+		Version: Version,
+		Owner:   TrusteeFromSID(sd.Owner, names),
+		Group:   TrusteeFromSID(sd.Group, names),
+	}
+	if sd.Control&descriptor.DACLPresent != 0 {
+		d.DACL = fromACL(sd.DACL, names,
+			sd.Control&descriptor.DACLProtected != 0,
+			sd.Control&descriptor.DACLAutoInherited != 0,
+			sd.Control&descriptor.DACLAutoInheritReq != 0)
+	}
+	if sd.Control&descriptor.SACLPresent != 0 {
+		d.SACL = fromACL(sd.SACL, names,
+			sd.Control&descriptor.SACLProtected != 0,
+			sd.Control&descriptor.SACLAutoInherited != 0,
+			sd.Control&descriptor.SACLAutoInheritReq != 0)
+	}
+	return d
+}
+
+func fromACL(acl descriptor.ACL, names icacls.NameFunc, protected, autoInherited, autoInheritReq bool) *ACL {
+	a := &ACL{Protected: protected, AutoInherited: autoInherited, AutoInheritRequired: autoInheritReq, Null: acl == nil}
+	if acl != nil {
+		a.Entries = make([]ACE, len(acl))
+	}
+	for i, ace := range acl {
+		a.Entries[i] = FromACE(ace, names)
+	}
+	return a
+}
+
+// Descriptor converts the document to a security descriptor. Account names are resolved with lookup; see
+// Trustee.SID.
+func (sd *SecurityDescriptor) Descriptor(lookup LookupFunc) (*descriptor.SecurityDescriptor, error) {
+	if err := checkVersion(sd.Version); err != nil {
+		return nil, err
+	}
+	result := &descriptor.SecurityDescriptor{}
+	var err error
+	if sd.Owner != "" {
+		if result.Owner, err = sd.Owner.SID(lookup); err != nil {
+			return nil, fmt.Errorf("owner: %w", err)
+		}
+	}
+	if sd.Group != "" {
+		if result.Group, err = sd.Group.SID(lookup); err != nil {
+			return nil, fmt.Errorf("group: %w", err)
+		}
+	}
+	if sd.DACL != nil {
+		if result.DACL, err = sd.DACL.acl(lookup); err != nil {
+			return nil, fmt.Errorf("DACL: %w", err)
+		}
+		result.Control |= descriptor.DACLPresent | sd.DACL.control(descriptor.DACLProtected, descriptor.DACLAutoInherited,
+			descriptor.DACLAutoInheritReq)
+	}
+	if sd.SACL != nil {
+		if result.SACL, err = sd.SACL.acl(lookup); err != nil {
+			return nil, fmt.Errorf("SACL: %w", err)
+		}
+		result.Control |= descriptor.SACLPresent | sd.SACL.control(descriptor.SACLProtected, descriptor.SACLAutoInherited,
+			descriptor.SACLAutoInheritReq)
+	}
+	return result, nil
+}
+
+// acl converts the entries of the document to an ACL
+func (a *ACL) acl(lookup LookupFunc) (descriptor.ACL, error) {
+	if a.Null {
+		if len(a.Entries) > 0 {
+			return nil, fmt.Errorf("a NULL ACL cannot have entries")
+		}
+		return nil, nil
+	}
+	acl := make(descriptor.ACL, len(a.Entries))
+	for i, entry := range a.Entries {
+		var err error
+		if acl[i], err = entry.ACE(lookup); err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+	}
+	return acl, nil
+}
+
+// control returns the control flags that the ACL sets, given the flags of the DACL or SACL
+func (a *ACL) control(protected, autoInherited, autoInheritReq descriptor.Control) descriptor.Control {
+	var control descriptor.Control
+	if a.Protected {
+		control |= protected
+	}
+	if a.AutoInherited {
+		control |= autoInherited
+	}
+	if a.AutoInheritRequired {
+		control |= autoInheritReq
+	}
+	return control
+}
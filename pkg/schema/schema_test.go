@@ -0,0 +1,144 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/access"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/icacls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestJSON(t *testing.T) {
+	sd, err := descriptor.ParseSDDL("O:BAG:S-1-5-21-1-2-3-513D:PAI(A;OICI;FA;;;SY)(D;;0x110000;;;S-1-5-21-1-2-3-1001)" +
+		"S:(AU;FA;FA;;;WD)(ML;;NWNR;;;LW)")
+	require.NoError(t, err)
+
+	doc := FromDescriptor(sd, icacls.WellKnownName)
+	out, err := json.Marshal(doc)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"version": 1,
+		"owner": "BUILTIN\\Administrators",
+		"group": "S-1-5-21-1-2-3-513",
+		"dacl": {
+			"protected": true,
+			"autoInherited": true,
+			"entries": [
+				{"type": "Allow", "trustee": "NT AUTHORITY\\SYSTEM", "rights": "FullControl", "flags": "OICI"},
+				{"type": "Deny", "trustee": "S-1-5-21-1-2-3-1001", "rights": "Delete, Synchronize"}
+			]
+		},
+		"sacl": {
+			"entries": [
+				{"type": "Audit", "trustee": "Everyone", "rights": "FullControl", "flags": "FA"},
+				{"type": "Label", "trustee": "S-1-16-4096", "rights": "NWNR"}
+			]
+		}
+	}`, string(out))
+
+	var decoded SecurityDescriptor
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	assert.Equal(t, doc, &decoded)
+	actual, err := decoded.Descriptor(nil)
+	require.NoError(t, err)
+	assert.Equal(t, sd, actual)
+
+	// NULL and empty DACLs are told apart
+	for _, sd := range []*descriptor.SecurityDescriptor{
+		{Control: descriptor.DACLPresent},
+		{Control: descriptor.DACLPresent, DACL: descriptor.ACL{}},
+	} {
+		out, err := json.Marshal(FromDescriptor(sd, nil))
+		require.NoError(t, err)
+		var decoded SecurityDescriptor
+		require.NoError(t, json.Unmarshal(out, &decoded))
+		actual, err := decoded.Descriptor(nil)
+		require.NoError(t, err)
+		assert.Equal(t, sd, actual, string(out))
+	}
+
+	// flags without an SDDL abbreviation are kept
+	unknown := &descriptor.SecurityDescriptor{Control: descriptor.DACLPresent, DACL: descriptor.ACL{
+		{Type: descriptor.AccessAllowed, Flags: descriptor.ObjectInherit | 0x20, Mask: access.Read, SID: descriptor.Everyone},
+	}}
+	out, err = json.Marshal(FromDescriptor(unknown, nil))
+	require.NoError(t, err)
+	decoded = SecurityDescriptor{}
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	actual, err = decoded.Descriptor(nil)
+	require.NoError(t, err)
+	assert.Equal(t, unknown, actual, string(out))
+
+	for name, invalid := range map[string]string{
+		"Version":      `{"version": 2}`,
+		"No version":   `{"owner": "S-1-1-0"}`,
+		"Rights":       `{"version": 1, "dacl": {"entries": [{"trustee": "Everyone", "rights": "Everything"}]}}`,
+		"Flags":        `{"version": 1, "dacl": {"entries": [{"trustee": "Everyone", "rights": "Read", "flags": "XX"}]}}`,
+		"Type":         `{"version": 1, "dacl": {"entries": [{"type": "Maybe", "trustee": "Everyone", "rights": "Read"}]}}`,
+		"Trustee":      `{"version": 1, "dacl": {"entries": [{"rights": "Read"}]}}`,
+		"Label policy": `{"version": 1, "sacl": {"entries": [{"type": "Label", "trustee": "S-1-16-4096", "rights": "Read"}]}}`,
+	} {
+		var sd SecurityDescriptor
+		assert.Error(t, json.Unmarshal([]byte(invalid), &sd), name)
+	}
+}
+
+func TestYAML(t *testing.T) {
+	var doc SecurityDescriptor
+	require.NoError(t, yaml.Unmarshal([]byte(`
+version: 1
+owner: alice
+dacl:
+  protected: true
+  entries:
+    - type: Allow
+      trustee: alice
+      rights: Modify, Synchronize
+      flags: OICI
+    - trustee: builtin\users
+      rights: ReadAndExecute
+`), &doc))
+
+	lookup := func(name string) (descriptor.SID, error) {
+		if name == "alice" {
+			return "S-1-5-21-1-2-3-1001", nil
+		}
+		return "", fmt.Errorf("not found")
+	}
+	_, err := doc.Descriptor(lookup)
+	assert.Error(t, err, "the lookup function replaces the well-known names")
+	doc.DACL.Entries[1].Trustee = "S-1-5-32-545"
+	sd, err := doc.Descriptor(lookup)
+	require.NoError(t, err)
+	assert.Equal(t, &descriptor.SecurityDescriptor{
+		Owner:   "S-1-5-21-1-2-3-1001",
+		Control: descriptor.DACLPresent | descriptor.DACLProtected,
+		DACL: descriptor.ACL{
+			{Type: descriptor.AccessAllowed, Flags: descriptor.ObjectInherit | descriptor.ContainerInherit, Mask: access.Modify | access.Synchronize, SID: "S-1-5-21-1-2-3-1001"},
+			{Type: descriptor.AccessAllowed, Mask: access.ReadAndExecute, SID: descriptor.BuiltinUsers},
+		},
+	}, sd)
+
+	_, err = (&SecurityDescriptor{Version: Version, Owner: "bob"}).Descriptor(nil)
+	assert.Error(t, err)
+	owner, err := Trustee(`builtin\users`).SID(nil)
+	require.NoError(t, err)
+	assert.Equal(t, descriptor.BuiltinUsers, owner)
+
+	doc.Version = 0
+	out, err := yaml.Marshal(doc)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "version: 1\n")
+	assert.Contains(t, string(out), "rights: Modify, Synchronize\n")
+	var decoded SecurityDescriptor
+	require.NoError(t, yaml.Unmarshal(out, &decoded))
+	doc.Version = Version
+	assert.Equal(t, doc, decoded)
+
+	assert.Error(t, yaml.Unmarshal([]byte("version: 3\n"), &decoded))
+}
@@ -0,0 +1,34 @@
+//go:build windows
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/icacls"
+	"golang.org/x/sys/windows"
+)
+
+// LookupSID is a LookupFunc that resolves account names with LookupAccountName
+func LookupSID(name string) (descriptor.SID, error) {
+	sid, _, _, err := windows.LookupSID("", name)
+	if err != nil {
+		return "", err
+	}
+	return descriptor.SID(sid.String()), nil
+}
+
+// ExplicitAccess converts an allow or deny ACE to an EXPLICIT_ACCESS that can be passed to acl.Apply, as
+// icacls.Entry.ExplicitAccess does. Account names are resolved by Windows when the EXPLICIT_ACCESS is applied.
+func (a ACE) ExplicitAccess() (windows.EXPLICIT_ACCESS, error) {
+	if a.Type != descriptor.AccessAllowed && a.Type != descriptor.AccessDenied {
+		return windows.EXPLICIT_ACCESS{}, fmt.Errorf("unsupported ACE type %s: expected Allow or Deny", a.Type)
+	}
+	entry := icacls.Entry{Trustee: string(a.Trustee), Type: a.Type, Flags: a.Flags, Rights: a.Mask}
+	if strings.HasPrefix(strings.ToUpper(entry.Trustee), "S-1-") {
+		entry.Trustee = "*" + entry.Trustee
+	}
+	return entry.ExplicitAccess()
+}
@@ -0,0 +1,31 @@
+//go:build windows
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"golang.org/x/sys/windows"
+)
+
+func TestExplicitAccess(t *testing.T) {
+	entry, err := ACE{Type: descriptor.AccessDenied, Trustee: "S-1-1-0", Mask: 0x2}.ExplicitAccess()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.AccessMode != windows.DENY_ACCESS || entry.Trustee.TrusteeForm != windows.TRUSTEE_IS_SID {
+		t.Errorf("unexpected EXPLICIT_ACCESS: %+v", entry)
+	}
+	if _, err := (ACE{Type: descriptor.SystemAudit, Trustee: "S-1-1-0", Mask: 0x2}).ExplicitAccess(); err == nil {
+		t.Error("expected an error for an audit ACE")
+	}
+
+	sid, err := Trustee(`BUILTIN\Administrators`).SID(LookupSID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sid != descriptor.BuiltinAdministrators {
+		t.Errorf("expected %s, found %s", descriptor.BuiltinAdministrators, sid)
+	}
+}
@@ -0,0 +1,152 @@
+// Package taracl preserves access control in tar archives, using the PAX extended header records written and
+// restored by libarchive (bsdtar) and star: SCHILY.acl.access and SCHILY.acl.default for POSIX ACLs, and
+// MSWINDOWS.rawsd for Windows security descriptors.
+//
+// AddRecords adds the records of a file to its archive/tar header before it is written, and Restore applies the
+// records of a header to the extracted file.
+package taracl
+
+import (
+	"archive/tar"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/posixacl"
+)
+
+// PAX record keys
+const (
+	// AccessRecord holds the access ACL of a file, as formatted by FormatPOSIX
+	AccessRecord = "SCHILY.acl.access"
+	// DefaultRecord holds the default ACL of a directory, as formatted by FormatPOSIX
+	DefaultRecord = "SCHILY.acl.default"
+	// RawSDRecord holds the security descriptor of a file in the self-relative binary format, encoded in base64
+	RawSDRecord = "MSWINDOWS.rawsd"
+)
+
+// FormatPOSIX returns the ACL in the format of the SCHILY.acl.access and SCHILY.acl.default records: getfacl entries
+// separated by commas, where the entries of named users and groups end with their numeric ID, so that they can be
+// restored on systems where the name does not exist (e.g. "user::rwx,user:alice:rw-:1001,group::r-x,mask::rwx,
+// other::r--").
+func FormatPOSIX(acl posixacl.ACL, names posixacl.Names) string {
+	entries := make([]string, len(acl))
+	for i, entry := range acl.Sorted() {
+		entries[i] = posixacl.FormatEntry(entry, names)
+		if entry.Tag == posixacl.User || entry.Tag == posixacl.Group {
+			entries[i] += ":" + strconv.FormatUint(uint64(entry.ID), 10)
+		}
+	}
+	return strings.Join(entries, ",")
+}
+
+// ParsePOSIX parses an ACL in the format of the SCHILY.acl.access and SCHILY.acl.default records. The numeric ID that
+// ends the entries of named users and groups takes precedence over their name, which is only resolved with names
+// when there is no ID.
+func ParsePOSIX(s string, names posixacl.Names) (posixacl.ACL, error) {
+	entries := strings.Split(s, ",")
+	for i, entry := range entries {
+		fields := strings.Split(entry, ":")
+		if len(fields) == 4 {
+			if _, err := strconv.ParseUint(fields[3], 10, 32); err != nil {
+				return nil, fmt.Errorf("invalid entry %q: invalid ID %q", entry, fields[3])
+			}
+			entries[i] = fields[0] + ":" + fields[3] + ":" + fields[2]
+		}
+	}
+	acl, defaults, err := posixacl.ParseEntries(strings.Join(entries, ","), names)
+	if err != nil {
+		return nil, err
+	}
+	if len(defaults) > 0 {
+		return nil, fmt.Errorf("invalid ACL %q: unexpected default entries", s)
+	}
+	return acl.Sorted(), nil
+}
+
+// SetPOSIX stores the ACLs in the SCHILY.acl.access and SCHILY.acl.default records of the header. A nil ACL removes
+// its record.
+func SetPOSIX(hdr *tar.Header, access, defaults posixacl.ACL, names posixacl.Names) {
+	setRecord(hdr, AccessRecord, "")
+	setRecord(hdr, DefaultRecord, "")
+	if access != nil {
+		setRecord(hdr, AccessRecord, FormatPOSIX(access, names))
+	}
+	if defaults != nil {
+		setRecord(hdr, DefaultRecord, FormatPOSIX(defaults, names))
+	}
+}
+
+// POSIX returns the ACLs stored in the SCHILY.acl.access and SCHILY.acl.default records of the header, or nil for the
+// records that are not set
+func POSIX(hdr *tar.Header, names posixacl.Names) (access, defaults posixacl.ACL, err error) {
+	if s, ok := hdr.PAXRecords[AccessRecord]; ok {
+		if access, err = ParsePOSIX(s, names); err != nil {
+			return nil, nil, fmt.Errorf("%s: %s: %w", hdr.Name, AccessRecord, err)
+		}
+	}
+	if s, ok := hdr.PAXRecords[DefaultRecord]; ok {
+		if defaults, err = ParsePOSIX(s, names); err != nil {
+			return nil, nil, fmt.Errorf("%s: %s: %w", hdr.Name, DefaultRecord, err)
+		}
+	}
+	return access, defaults, nil
+}
+
+// SetDescriptor stores the security descriptor in the MSWINDOWS.rawsd record of the header. A nil security
+// descriptor removes the record.
+func SetDescriptor(hdr *tar.Header, sd *descriptor.SecurityDescriptor) error {
+	if sd == nil {
+		setRecord(hdr, RawSDRecord, "")
+		return nil
+	}
+	b, err := sd.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("%s: %w", hdr.Name, err)
+	}
+	setRecord(hdr, RawSDRecord, base64.StdEncoding.EncodeToString(b))
+	return nil
+}
+
+// Descriptor returns the security descriptor stored in the MSWINDOWS.rawsd record of the header, or nil if the record
+// is not set
+func Descriptor(hdr *tar.Header) (*descriptor.SecurityDescriptor, error) {
+	s, ok := hdr.PAXRecords[RawSDRecord]
+	if !ok {
+		return nil, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", hdr.Name, RawSDRecord, err)
+	}
+	sd, err := descriptor.ParseSecurityDescriptor(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", hdr.Name, RawSDRecord, err)
+	}
+	return sd, nil
+}
+
+// setRecord sets a PAX record of the header, or removes it if the value is empty
+func setRecord(hdr *tar.Header, key, value string) {
+	if value == "" {
+		delete(hdr.PAXRecords, key)
+		return
+	}
+	if hdr.PAXRecords == nil {
+		hdr.PAXRecords = make(map[string]string)
+	}
+	hdr.PAXRecords[key] = value
+}
+
+// skipped returns true for the entries whose access control is not preserved: links, whose target is archived
+// separately, and special files
+func skipped(hdr *tar.Header) bool {
+	switch hdr.Typeflag {
+	case tar.TypeReg, tar.TypeDir:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,82 @@
+package taracl
+
+import (
+	"archive/tar"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/posixacl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPOSIXRecords(t *testing.T) {
+	names := posixacl.Names{
+		UserName: func(uid uint32) (string, bool) { return "alice", uid == 1001 },
+	}
+	acl := posixacl.ACL{
+		{Tag: posixacl.Other, Perm: posixacl.Read},
+		{Tag: posixacl.UserObj, Perm: posixacl.All},
+		{Tag: posixacl.User, ID: 1001, Perm: posixacl.Read | posixacl.Write},
+		{Tag: posixacl.Group, ID: 2000, Perm: posixacl.Read},
+		{Tag: posixacl.GroupObj, Perm: posixacl.Read | posixacl.Execute},
+		{Tag: posixacl.Mask, Perm: posixacl.All},
+	}
+	s := FormatPOSIX(acl, names)
+	assert.Equal(t, "user::rwx,user:alice:rw-:1001,group::r-x,group:2000:r--:2000,mask::rwx,other::r--", s)
+
+	// the ID takes precedence over the name, which cannot be resolved here
+	parsed, err := ParsePOSIX(s, posixacl.Names{})
+	require.NoError(t, err)
+	assert.Equal(t, acl.Sorted(), parsed)
+
+	parsed, err = ParsePOSIX("user::rw-,group::r--,other::---", posixacl.Names{})
+	require.NoError(t, err)
+	assert.Equal(t, posixacl.FromMode(0640), parsed)
+
+	for _, invalid := range []string{"user:alice:rw-:bob", "default:user::rwx", "user:alice:rw-", "other::rwz"} {
+		_, err := ParsePOSIX(invalid, posixacl.Names{})
+		assert.Error(t, err, invalid)
+	}
+
+	hdr := &tar.Header{Name: "dir/"}
+	SetPOSIX(hdr, acl, posixacl.FromMode(0750), names)
+	assert.Equal(t, "user::rwx,group::r-x,other::---", hdr.PAXRecords[DefaultRecord])
+	access, defaults, err := POSIX(hdr, posixacl.Names{})
+	require.NoError(t, err)
+	assert.Equal(t, acl.Sorted(), access)
+	assert.Equal(t, posixacl.FromMode(0750), defaults)
+
+	SetPOSIX(hdr, nil, nil, names)
+	assert.Empty(t, hdr.PAXRecords)
+	access, defaults, err = POSIX(hdr, posixacl.Names{})
+	require.NoError(t, err)
+	assert.Nil(t, access)
+	assert.Nil(t, defaults)
+}
+
+func TestDescriptorRecord(t *testing.T) {
+	sd, err := descriptor.ParseSDDL("O:BAG:SYD:PAI(A;OICI;FA;;;SY)(A;OICI;0x1200a9;;;BU)")
+	require.NoError(t, err)
+
+	hdr := &tar.Header{Name: "file"}
+	actual, err := Descriptor(hdr)
+	require.NoError(t, err)
+	assert.Nil(t, actual)
+
+	require.NoError(t, SetDescriptor(hdr, sd))
+	assert.Regexp(t, `^AQAElB`, hdr.PAXRecords[RawSDRecord])
+	actual, err = Descriptor(hdr)
+	require.NoError(t, err)
+	assert.Equal(t, sd, actual)
+
+	require.NoError(t, SetDescriptor(hdr, nil))
+	assert.NotContains(t, hdr.PAXRecords, RawSDRecord)
+
+	hdr.PAXRecords[RawSDRecord] = "not base64"
+	_, err = Descriptor(hdr)
+	assert.Error(t, err)
+	hdr.PAXRecords[RawSDRecord] = "AQAA"
+	_, err = Descriptor(hdr)
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+//go:build linux
+
+package taracl
+
+import (
+	"archive/tar"
+
+	"github.com/rancher/permissions/pkg/acl"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/posixacl"
+)
+
+// AddRecords adds the SCHILY.acl.access and SCHILY.acl.default records of the file / directory at path to its
+// header, as bsdtar --acls does. The access ACL is only recorded if it is extended, since the mode of the header
+// already holds minimal ACLs. Names are resolved with posixacl.SystemNames. Only regular files and directories have
+// records.
+func AddRecords(hdr *tar.Header, path string) error {
+	if skipped(hdr) {
+		return nil
+	}
+	access, defaults, err := acl.GetPOSIX(path)
+	if err != nil {
+		return err
+	}
+	if access.IsMinimal() {
+		access = nil
+	}
+	SetPOSIX(hdr, access, defaults, posixacl.SystemNames)
+	return nil
+}
+
+// Restore applies the access control recorded in the header to the extracted file / directory at path. The POSIX
+// ACLs of the SCHILY.acl.access and SCHILY.acl.default records are written with acl.SetPOSIX. Without them, the DACL
+// of the MSWINDOWS.rawsd record is written with acl.Set, which fails if it refers to SIDs that do not map to Unix
+// users and groups. The owner and group are not changed, since they are held by the Uid and Gid fields of the header.
+func Restore(path string, hdr *tar.Header) error {
+	if skipped(hdr) {
+		return nil
+	}
+	access, defaults, err := POSIX(hdr, posixacl.SystemNames)
+	if err != nil {
+		return err
+	}
+	if access != nil || defaults != nil {
+		if access == nil {
+			// only the default ACL was recorded, so the access ACL is the one made by the mode of the file
+			if access, _, err = acl.GetPOSIX(path); err != nil {
+				return err
+			}
+		}
+		return acl.SetPOSIX(path, access, defaults)
+	}
+	sd, err := Descriptor(hdr)
+	if err != nil || sd == nil || sd.Control&descriptor.DACLPresent == 0 {
+		return err
+	}
+	return acl.Set(path, sd, descriptor.DACLInformation)
+}
@@ -0,0 +1,122 @@
+//go:build linux
+
+package taracl
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher/permissions/pkg/acl"
+	"github.com/rancher/permissions/pkg/descriptor"
+	"github.com/rancher/permissions/pkg/posixacl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	dir := filepath.Join(src, "dir")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	f := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(f, []byte("data"), 0644))
+	plain := filepath.Join(src, "plain")
+	require.NoError(t, os.WriteFile(plain, nil, 0600))
+
+	dirDefaults := posixacl.ACL{
+		{Tag: posixacl.UserObj, Perm: posixacl.All},
+		{Tag: posixacl.Group, ID: 1002, Perm: posixacl.Read | posixacl.Execute},
+		{Tag: posixacl.GroupObj, Perm: posixacl.Read | posixacl.Execute},
+		{Tag: posixacl.Mask, Perm: posixacl.Read | posixacl.Execute},
+		{Tag: posixacl.Other, Perm: 0},
+	}
+	require.NoError(t, acl.SetPOSIX(dir, posixacl.FromMode(0750), dirDefaults.Sorted()))
+	fileAccess := posixacl.ACL{
+		{Tag: posixacl.UserObj, Perm: posixacl.Read | posixacl.Write},
+		{Tag: posixacl.User, ID: 1001, Perm: posixacl.Read | posixacl.Write},
+		{Tag: posixacl.GroupObj, Perm: posixacl.Read},
+		{Tag: posixacl.Mask, Perm: posixacl.Read | posixacl.Write},
+		{Tag: posixacl.Other, Perm: 0},
+	}
+	require.NoError(t, acl.SetPOSIX(f, fileAccess, nil))
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, path := range []string{dir, f, plain} {
+		info, err := os.Lstat(path)
+		require.NoError(t, err)
+		hdr, err := tar.FileInfoHeader(info, "")
+		require.NoError(t, err)
+		hdr.Name, _ = filepath.Rel(src, path)
+		require.NoError(t, AddRecords(hdr, path))
+		require.NoError(t, tw.WriteHeader(hdr))
+		if info.Mode().IsRegular() {
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			_, err = tw.Write(data)
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+
+	dst := t.TempDir()
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		path := filepath.Join(dst, hdr.Name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			require.NoError(t, os.Mkdir(path, hdr.FileInfo().Mode().Perm()))
+		case tar.TypeReg:
+			require.NoError(t, os.WriteFile(path, nil, hdr.FileInfo().Mode().Perm()))
+			if hdr.Name == "plain" {
+				assert.Empty(t, hdr.PAXRecords, "minimal ACLs should not be recorded")
+			}
+		}
+		require.NoError(t, Restore(path, hdr))
+	}
+
+	access, defaults, err := acl.GetPOSIX(filepath.Join(dst, "dir"))
+	require.NoError(t, err)
+	assert.Equal(t, posixacl.FromMode(0750), access)
+	assert.Equal(t, dirDefaults.Sorted(), defaults)
+	access, _, err = acl.GetPOSIX(filepath.Join(dst, "dir", "file"))
+	require.NoError(t, err)
+	assert.Equal(t, fileAccess.Sorted(), access)
+	info, err := os.Stat(filepath.Join(dst, "plain"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestRestoreDescriptor(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(f, nil, 0600))
+	sd, err := acl.Get(f)
+	require.NoError(t, err)
+
+	hdr := &tar.Header{Name: "file", Typeflag: tar.TypeReg}
+	require.NoError(t, SetDescriptor(hdr, &descriptor.SecurityDescriptor{
+		Control: descriptor.DACLPresent,
+		DACL:    posixacl.FromMode(0640).DACL(sd.Owner, sd.Group),
+	}))
+	require.NoError(t, Restore(f, hdr))
+	info, err := os.Stat(f)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+
+	require.NoError(t, SetDescriptor(hdr, &descriptor.SecurityDescriptor{
+		Control: descriptor.DACLPresent,
+		DACL:    descriptor.ACL{{Type: descriptor.AccessAllowed, Mask: 0x1F01FF, SID: descriptor.BuiltinAdministrators}},
+	}))
+	assert.Error(t, Restore(f, hdr), "Windows SIDs cannot be written as POSIX ACLs")
+
+	// links have no records
+	assert.NoError(t, Restore(f, &tar.Header{Name: "link", Typeflag: tar.TypeSymlink, PAXRecords: hdr.PAXRecords}))
+}
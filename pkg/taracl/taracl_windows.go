@@ -0,0 +1,39 @@
+//go:build windows
+
+package taracl
+
+import (
+	"archive/tar"
+
+	"github.com/rancher/permissions/pkg/acl"
+	"github.com/rancher/permissions/pkg/descriptor"
+)
+
+// AddRecords adds the MSWINDOWS.rawsd record of the file / directory at path to its header, as bsdtar does on
+// Windows. The record holds the owner, group and DACL returned by acl.Get. Only regular files and directories have
+// records.
+func AddRecords(hdr *tar.Header, path string) error {
+	if skipped(hdr) {
+		return nil
+	}
+	sd, err := acl.Get(path)
+	if err != nil {
+		return err
+	}
+	return SetDescriptor(hdr, sd)
+}
+
+// Restore applies the DACL of the MSWINDOWS.rawsd record of the header to the extracted file / directory at path with
+// acl.Set. The owner and group are not restored, since setting them to other accounts requires the
+// SeRestorePrivilege; use Descriptor and acl.Set to restore them. The SCHILY.acl.access and SCHILY.acl.default
+// records refer to Unix users and groups, so they are ignored.
+func Restore(path string, hdr *tar.Header) error {
+	if skipped(hdr) {
+		return nil
+	}
+	sd, err := Descriptor(hdr)
+	if err != nil || sd == nil || sd.Control&descriptor.DACLPresent == 0 {
+		return err
+	}
+	return acl.Set(path, sd, descriptor.DACLInformation)
+}